@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/output"
+)
+
+// constantsCmd represents the constants command
+var constantsCmd = &cobra.Command{
+	Use:   "constants",
+	Short: "List the built-in constants",
+	Long:  `List the constants available in expressions, with their values and units. Constants cannot be assigned. Example: gomathpro constants`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		doc := constantsDoc{}
+		for _, c := range evaluator.Constants() {
+			doc.Constants = append(doc.Constants, constantDoc{Name: c.Name, Value: output.Float(c.Value), Unit: c.Unit, Description: c.Doc})
+		}
+
+		emit(doc, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tVALUE\tUNIT\tDESCRIPTION")
+			for _, c := range doc.Constants {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Name, format.Float(float64(c.Value), displayFormat), c.Unit, c.Description)
+			}
+			w.Flush()
+		})
+	},
+}
+
+func init() {
+	// Add the constants command to the root command
+	RootCmd.AddCommand(constantsCmd)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/output"
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// convertList lists the registered units instead of converting
+var convertList bool
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert [quantity] [unit] | [value] [from] [to]",
+	Short: "Convert a quantity to another unit",
+	Long: `Convert a quantity to another unit of the same dimension. The quantity may be any expression with a unit, or a value followed by its unit as separate arguments. Units accept SI prefixes where marked in --list. Example: gomathpro convert 72 degF degC
+Example: gomathpro convert "5 km + 300 m" mi`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if convertList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.RangeArgs(2, 3)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if convertList {
+			listUnits()
+			return
+		}
+
+		input := strings.Join(args[:len(args)-1], " ")
+		to, err := units.Parse(args[len(args)-1])
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("Failed to parse unit")
+			reportError(err)
+			return
+		}
+
+		value, err := evaluator.New(evaluator.Options{}).Evaluate(input)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"error": err,
+				"input": input,
+			}).Error("Failed to evaluate quantity")
+			reportError(errors.New(evaluator.RenderError(input, err)))
+			return
+		}
+		from, ok := value.(units.Quantity)
+		if !ok {
+			reportError(fmt.Errorf("%s has no unit", input))
+			return
+		}
+		result, err := units.Convert(from, to)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("Failed to convert quantity")
+			reportError(err)
+			return
+		}
+
+		doc := conversionDoc{
+			Input:  input,
+			From:   format.Quantity(from, displayFormat),
+			Value:  output.Float(result.Value),
+			Unit:   result.Unit.String(),
+			Result: format.Quantity(result, displayFormat),
+		}
+		emit(doc, func() {
+			fmt.Printf("%s = %s\n", doc.From, doc.Result)
+		})
+	},
+}
+
+// listUnits prints the registered units
+func listUnits() {
+	doc := unitsDoc{}
+	for _, def := range units.Definitions() {
+		doc.Units = append(doc.Units, unitDoc{
+			Name:        def.Name,
+			Dimension:   def.Dim.String(),
+			Prefixes:    def.Prefixes,
+			Description: def.Doc,
+		})
+	}
+
+	emit(doc, func() {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tDIMENSION\tPREFIXES\tDESCRIPTION")
+		for _, u := range doc.Units {
+			prefixes := ""
+			if u.Prefixes {
+				prefixes = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.Name, u.Dimension, prefixes, u.Description)
+		}
+		w.Flush()
+	})
+}
+
+func init() {
+	// Add the convert command to the root command
+	RootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().BoolVar(&convertList, "list", false, "list the available units")
+}
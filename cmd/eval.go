@@ -1,59 +1,258 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"os"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/script"
 )
 
+// log reports diagnostics on stderr, leaving stdout to command output
 var log = logrus.New()
 
+// evalExact selects exact rational arithmetic for the eval and repl commands
+var evalExact bool
+
+// evalPrecision is the number of significant digits for arbitrary-precision
+// evaluation, or 0 for float64
+var evalPrecision int
+
+// evalComplex makes functions such as sqrt return complex results for
+// negative arguments instead of an error
+var evalComplex bool
+
+// evalAngle is the angle unit of the trigonometric functions: rad, deg or
+// grad
+var evalAngle string
+
+// evalGamma makes fact of a non-integer return gamma(x + 1)
+var evalGamma bool
+
+// evalStrict makes NaN and infinite results errors instead of warnings
+var evalStrict bool
+
+// evalFile is the script to evaluate instead of an expression, or "-" for
+// standard input
+var evalFile string
+
+// evalKeepGoing continues a script after a failing statement
+var evalKeepGoing bool
+
 // evalCmd represents the eval command
 var evalCmd = &cobra.Command{
-	Use:   "eval [expression]",
+	Use:   "eval [expression | -]",
 	Short: "Evaluate a mathematical expression",
-	Long:  `Evaluate a mathematical expression with support for variables, exponents, factorials, and functions. Example: A = 5; B = 7; A + B`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Evaluate a mathematical expression with support for variables, exponents, factorials, and functions. Example: A = 5; B = 7; A + B
+With -f, evaluate a script file instead, one statement per line or several separated by ";", with comments starting with #. Use - as the expression or file name to read standard input. Example: gomathpro eval -f calc.gmp`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if evalFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		expression := args[0]
-
-		// Split the input into individual statements (e.g., "A = 5; B = 7; A + B")
-		statements := strings.Split(expression, ";")
+		opts, err := sessionOptions()
+		if err != nil {
+			reportError(err)
+			return
+		}
+		session := evaluator.New(opts)
 
-		// Evaluate each statement
-		for _, stmt := range statements {
-			stmt = strings.TrimSpace(stmt)
-			if stmt == "" {
-				continue
+		var ok bool
+		if evalFile == "" && args[0] != "-" {
+			ok = evalExpression(session, args[0])
+		} else {
+			name := evalFile
+			if name == "" {
+				name = "-"
 			}
+			ok = evalScript(session, name)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// evalExpression evaluates the statements of a single expression argument
+// and reports whether they ran without errors
+func evalExpression(session *evaluator.Evaluator, expression string) bool {
+	// Evaluate each statement (e.g., "A = 5; B = 7; A + B")
+	results, err := session.Exec(expression)
 
-			result, err := evaluator.Evaluate(stmt)
-			if err != nil {
-				log.WithFields(logrus.Fields{
-					"error":      err,
-					"expression": stmt,
-				}).Error("Failed to evaluate expression")
-				fmt.Printf("Error: %v\n", err)
-				return
+	// Report the results of the statements that ran, skipping assignments
+	doc := evalDoc{Input: expression, Results: []resultDoc{}}
+	for _, result := range results {
+		for _, w := range result.Warnings {
+			line, column := evaluator.Position(expression, w)
+			doc.Warnings = append(doc.Warnings, errorDoc{Line: line, Column: column, Message: evaluator.Message(w)})
+		}
+		if result.Value != nil {
+			log.WithFields(logrus.Fields{
+				"expression": result.Source,
+				"result":     result.Value,
+			}).Info("Expression evaluated successfully")
+			doc.Results = append(doc.Results, newResultDoc(result.Line, result))
+		}
+	}
+
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"error":      err,
+			"expression": expression,
+		}).Error("Failed to evaluate expression")
+		line, column := evaluator.Position(expression, err)
+		doc.Errors = append(doc.Errors, errorDoc{Line: line, Column: column, Message: evaluator.Message(err)})
+	}
+
+	emit(doc, func() {
+		for _, result := range doc.Results {
+			fmt.Printf("Result: %s\n", result.Value)
+		}
+		for _, result := range results {
+			for _, w := range result.Warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", evaluator.RenderError(expression, w))
 			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", evaluator.RenderError(expression, err))
+		}
+	})
+	return err == nil
+}
 
-			// If the result is not nil, print it (e.g., for expressions like "A + B")
-			if result != nil {
-				log.WithFields(logrus.Fields{
-					"expression": stmt,
-					"result":     result,
-				}).Info("Expression evaluated successfully")
-				fmt.Printf("Result: %v\n", result)
+// evalScript evaluates a script file, or standard input for "-", and
+// reports whether it ran without errors
+func evalScript(session *evaluator.Evaluator, name string) bool {
+	in := os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			reportError(err)
+			return false
+		}
+		defer f.Close()
+		in = f
+	} else {
+		name = "<stdin>"
+	}
+
+	doc := evalDoc{File: name, Results: []resultDoc{}}
+	err := script.Run(session, name, in, script.Options{KeepGoing: evalKeepGoing}, func(result script.Result) {
+		for _, w := range result.Warnings {
+			doc.Warnings = append(doc.Warnings, errorDoc{File: name, Line: result.Line, Message: evaluator.Message(w)})
+		}
+		if result.Value != nil {
+			log.WithFields(logrus.Fields{
+				"file":       name,
+				"line":       result.Line,
+				"expression": result.Source,
+				"result":     result.Value,
+			}).Info("Expression evaluated successfully")
+			doc.Results = append(doc.Results, newResultDoc(result.Line, result.Result))
+		}
+	})
+	if err == nil {
+		emit(doc, func() {
+			for _, result := range doc.Results {
+				fmt.Printf("Result: %s\n", result.Value)
 			}
+			printWarnings(doc.Warnings)
+		})
+		return true
+	}
+
+	log.WithFields(logrus.Fields{
+		"error": err,
+		"file":  name,
+	}).Error("Failed to evaluate script")
+	var failure *script.Error
+	var failures script.Errors
+	switch {
+	case errors.As(err, &failures):
+	case errors.As(err, &failure):
+		failures = script.Errors{failure}
+	default:
+		reportError(err)
+		return false
+	}
+	for _, failure := range failures {
+		doc.Errors = append(doc.Errors, errorDoc{
+			File:    failure.File,
+			Line:    failure.Line,
+			Column:  failure.Column,
+			Message: evaluator.Message(failure.Err),
+		})
+	}
+
+	emit(doc, func() {
+		for _, result := range doc.Results {
+			fmt.Printf("Result: %s\n", result.Value)
 		}
-	},
+		printWarnings(doc.Warnings)
+		if !evalKeepGoing {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", failures[0].Render())
+			return
+		}
+		fmt.Fprintf(os.Stderr, "%d %s:\n", len(failures), plural(len(failures), "error"))
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "%s\n", failure.Render())
+		}
+	})
+	return false
+}
+
+// printWarnings writes the warnings of a script to stderr
+func printWarnings(warnings []errorDoc) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s:%d: %s\n", w.File, w.Line, w.Message)
+	}
 }
 
 func init() {
 	// Add the eval command to the root command
 	RootCmd.AddCommand(evalCmd)
-}
\ No newline at end of file
+
+	addSessionFlags(evalCmd)
+	evalCmd.Flags().StringVarP(&evalFile, "file", "f", "", "evaluate a script file, or - for standard input")
+	evalCmd.Flags().BoolVar(&evalKeepGoing, "keep-going", false, "continue a script after an error and report all errors at the end")
+}
+
+// addSessionFlags adds the flags configuring an evaluation session to a
+// command.
+func addSessionFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&evalExact, "exact", false, "keep integers and fractions exact (e.g. 1/3 + 1/6 = 1/2)")
+	cmd.Flags().IntVar(&evalPrecision, "precision", 0, "evaluate with N significant digits instead of float64")
+	cmd.Flags().BoolVar(&evalComplex, "complex", false, "return complex results such as sqrt(-4) = 2i instead of an error")
+	cmd.Flags().StringVar(&evalAngle, "angle", "rad", "angle unit of the trigonometric functions: rad, deg or grad")
+	cmd.Flags().BoolVar(&evalGamma, "gamma-factorial", false, "extend fact to non-integers as fact(x) = gamma(x + 1)")
+	cmd.Flags().BoolVar(&evalStrict, "strict", false, "fail on NaN and infinite results, such as log(-1) or exp(1000), instead of warning")
+}
+
+// sessionOptions returns the evaluator options selected by the session
+// flags.
+func sessionOptions() (evaluator.Options, error) {
+	if evalExact && evalPrecision > 0 {
+		return evaluator.Options{}, errors.New("--exact and --precision cannot be used together")
+	}
+	if evalPrecision < 0 {
+		return evaluator.Options{}, errors.New("--precision must be a positive number of digits")
+	}
+	angle, err := evaluator.ParseAngleUnit(evalAngle)
+	if err != nil {
+		return evaluator.Options{}, err
+	}
+	return evaluator.Options{Exact: evalExact, Precision: evalPrecision, Complex: evalComplex, Angle: angle, FactorialGamma: evalGamma, Strict: evalStrict}, nil
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
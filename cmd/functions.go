@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+)
+
+// functionsCmd represents the functions command
+var functionsCmd = &cobra.Command{
+	Use:   "functions",
+	Short: "List the built-in functions",
+	Long:  `List the functions available in expressions, with the number of arguments each takes. Example: gomathpro functions`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		doc := functionsDoc{}
+		for _, fn := range evaluator.Builtins().Entries() {
+			doc.Functions = append(doc.Functions, functionDoc{Name: fn.Name, Args: fn.Arity(), Description: fn.Doc})
+		}
+
+		emit(doc, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tARGS\tDESCRIPTION")
+			for _, fn := range doc.Functions {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", fn.Name, fn.Args, fn.Description)
+			}
+			w.Flush()
+		})
+	},
+}
+
+func init() {
+	// Add the functions command to the root command
+	RootCmd.AddCommand(functionsCmd)
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/output"
+	"github.com/trenchesdeveloper/gomathpro/internal/polynomial"
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// outputFormat is the format selected with the global --output flag
+var outputFormat = output.Text
+
+// displayFormat is the number format selected with the global --digits,
+// --notation, --thousands and --trim-zeros flags
+var displayFormat format.Options
+
+// emit writes doc in the selected output format, or calls text to print it
+// for the text format
+func emit(doc interface{}, text func()) {
+	if outputFormat == output.Text {
+		text()
+		return
+	}
+	if err := output.Write(os.Stdout, outputFormat, doc); err != nil {
+		log.WithFields(logrus.Fields{
+			"error":  err,
+			"format": outputFormat,
+		}).Error("Failed to write output")
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+}
+
+// reportError prints a command's failure in the selected output format
+func reportError(err error) {
+	emit(failureDoc{Error: err.Error()}, func() {
+		fmt.Printf("Error: %v\n", err)
+	})
+}
+
+// failureDoc is the document of a command that failed before producing
+// results
+type failureDoc struct {
+	Error string `json:"error" yaml:"error"`
+}
+
+func (d failureDoc) Table() ([]string, [][]string) {
+	return []string{"error"}, [][]string{{d.Error}}
+}
+
+// evalDoc is the document of the eval command
+type evalDoc struct {
+	Input    string      `json:"input,omitempty" yaml:"input,omitempty"`
+	File     string      `json:"file,omitempty" yaml:"file,omitempty"`
+	Results  []resultDoc `json:"results" yaml:"results"`
+	Errors   []errorDoc  `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Warnings []errorDoc  `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// resultDoc is the value of one expression. Value is formatted as in the
+// text output, so exact and arbitrary-precision results keep their digits.
+type resultDoc struct {
+	Line   int    `json:"line" yaml:"line"`
+	Source string `json:"source" yaml:"source"`
+	Value  string `json:"value" yaml:"value"`
+	Type   string `json:"type" yaml:"type"`
+}
+
+// errorDoc is an evaluation error or warning with its position, when known
+type errorDoc struct {
+	File    string `json:"file,omitempty" yaml:"file,omitempty"`
+	Line    int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column  int    `json:"column,omitempty" yaml:"column,omitempty"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (d evalDoc) Table() ([]string, [][]string) {
+	var rows [][]string
+	for _, r := range d.Results {
+		rows = append(rows, []string{strconv.Itoa(r.Line), r.Source, r.Value, r.Type, "", ""})
+	}
+	for _, e := range d.Errors {
+		rows = append(rows, []string{strconv.Itoa(e.Line), "", "", "", e.Message, ""})
+	}
+	for _, w := range d.Warnings {
+		rows = append(rows, []string{strconv.Itoa(w.Line), "", "", "", "", w.Message})
+	}
+	return []string{"line", "source", "value", "type", "error", "warning"}, rows
+}
+
+// newResultDoc describes the result of a statement on the given line
+func newResultDoc(line int, result evaluator.Result) resultDoc {
+	return resultDoc{
+		Line:   line,
+		Source: result.Source,
+		Value:  format.Value(result.Value, displayFormat),
+		Type:   valueType(result.Value),
+	}
+}
+
+// valueType names the kind of an evaluated value
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case float64, *big.Float:
+		return "real"
+	case *big.Rat:
+		return "rational"
+	case complex128:
+		return "complex"
+	case bool:
+		return "boolean"
+	case units.Quantity:
+		return "quantity"
+	case []interface{}:
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// complexDoc is a complex number as a real/imaginary pair
+type complexDoc struct {
+	Real output.Float `json:"real" yaml:"real"`
+	Imag output.Float `json:"imag" yaml:"imag"`
+}
+
+// newComplexDoc splits z into its parts, without negative zeros
+func newComplexDoc(z complex128) complexDoc {
+	return complexDoc{Real: output.Float(real(z) + 0), Imag: output.Float(imag(z) + 0)}
+}
+
+// rootsDoc is the document of the polynomial roots command. Coefficients
+// are in increasing order of degree.
+type rootsDoc struct {
+	Polynomial   string         `json:"polynomial" yaml:"polynomial"`
+	Coefficients []output.Float `json:"coefficients" yaml:"coefficients"`
+	Method       string         `json:"method" yaml:"method"`
+	Converged    bool           `json:"converged" yaml:"converged"`
+	Roots        []rootDoc      `json:"roots" yaml:"roots"`
+}
+
+// rootDoc is a root with the radius of a disk around it that contains a
+// true root, and the iterations that refined it
+type rootDoc struct {
+	Real       output.Float `json:"real" yaml:"real"`
+	Imag       output.Float `json:"imag" yaml:"imag"`
+	ErrorBound output.Float `json:"error_bound" yaml:"error_bound"`
+	Iterations int          `json:"iterations" yaml:"iterations"`
+	Converged  bool         `json:"converged" yaml:"converged"`
+}
+
+// newRootDoc describes a root found numerically
+func newRootDoc(r polynomial.Root) rootDoc {
+	z := newComplexDoc(r.Value)
+	return rootDoc{Real: z.Real, Imag: z.Imag, ErrorBound: output.Float(r.ErrorBound), Iterations: r.Iterations, Converged: r.Converged}
+}
+
+func (d rootsDoc) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Roots))
+	for i, r := range d.Roots {
+		rows[i] = []string{r.Real.String(), r.Imag.String(), r.ErrorBound.String(), strconv.Itoa(r.Iterations), strconv.FormatBool(r.Converged)}
+	}
+	return []string{"real", "imag", "error_bound", "iterations", "converged"}, rows
+}
+
+// factorsDoc is the document of the polynomial factorize command. The
+// polynomial is Constant, its content or with --real or --complex its
+// leading coefficient, times the product of Factors.
+type factorsDoc struct {
+	Polynomial    string         `json:"polynomial" yaml:"polynomial"`
+	Coefficients  []output.Float `json:"coefficients" yaml:"coefficients"`
+	Factorization string         `json:"factorization" yaml:"factorization"`
+	Constant      string         `json:"constant" yaml:"constant"`
+	Factors       []string       `json:"factors" yaml:"factors"`
+}
+
+func (d factorsDoc) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Factors))
+	for i, f := range d.Factors {
+		rows[i] = []string{f}
+	}
+	return []string{"factor"}, rows
+}
+
+// interpolationDoc is the document of the polynomial interpolate command.
+// Coefficients are in increasing order of degree.
+type interpolationDoc struct {
+	Points       [][2]float64   `json:"points" yaml:"points,flow"`
+	Polynomial   string         `json:"polynomial" yaml:"polynomial"`
+	Coefficients []output.Float `json:"coefficients" yaml:"coefficients"`
+}
+
+func (d interpolationDoc) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Coefficients))
+	for i, c := range d.Coefficients {
+		rows[i] = []string{strconv.Itoa(i), c.String()}
+	}
+	return []string{"degree", "coefficient"}, rows
+}
+
+// polynomialDoc is a polynomial written out and as its coefficients in
+// increasing order of degree
+type polynomialDoc struct {
+	Polynomial   string         `json:"polynomial" yaml:"polynomial"`
+	Coefficients []output.Float `json:"coefficients" yaml:"coefficients,flow"`
+}
+
+// newPolynomialDoc writes p with the display format
+func newPolynomialDoc(p polynomial.Polynomial) polynomialDoc {
+	return polynomialDoc{Polynomial: p.Format(displayFormat), Coefficients: output.Floats(p)}
+}
+
+// divisionDoc is the document of the polynomial divide command
+type divisionDoc struct {
+	Dividend  string        `json:"dividend" yaml:"dividend"`
+	Divisor   string        `json:"divisor" yaml:"divisor"`
+	Quotient  polynomialDoc `json:"quotient" yaml:"quotient"`
+	Remainder polynomialDoc `json:"remainder" yaml:"remainder"`
+}
+
+func (d divisionDoc) Table() ([]string, [][]string) {
+	return []string{"part", "polynomial"}, [][]string{
+		{"quotient", d.Quotient.Polynomial},
+		{"remainder", d.Remainder.Polynomial},
+	}
+}
+
+// gcdDoc is the document of the polynomial gcd command
+type gcdDoc struct {
+	Polynomials []string      `json:"polynomials" yaml:"polynomials"`
+	GCD         polynomialDoc `json:"gcd" yaml:"gcd"`
+}
+
+func (d gcdDoc) Table() ([]string, [][]string) {
+	return []string{"gcd"}, [][]string{{d.GCD.Polynomial}}
+}
+
+// functionsDoc is the document of the functions command
+type functionsDoc struct {
+	Functions []functionDoc `json:"functions" yaml:"functions"`
+}
+
+// functionDoc describes a built-in function
+type functionDoc struct {
+	Name        string `json:"name" yaml:"name"`
+	Args        string `json:"args" yaml:"args"`
+	Description string `json:"description" yaml:"description"`
+}
+
+func (d functionsDoc) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Functions))
+	for i, f := range d.Functions {
+		rows[i] = []string{f.Name, f.Args, f.Description}
+	}
+	return []string{"name", "args", "description"}, rows
+}
+
+// conversionDoc is the document of the convert command
+type conversionDoc struct {
+	Input  string       `json:"input" yaml:"input"`
+	From   string       `json:"from" yaml:"from"`
+	Value  output.Float `json:"value" yaml:"value"`
+	Unit   string       `json:"unit" yaml:"unit"`
+	Result string       `json:"result" yaml:"result"`
+}
+
+func (d conversionDoc) Table() ([]string, [][]string) {
+	return []string{"from", "value", "unit"}, [][]string{{d.From, d.Value.String(), d.Unit}}
+}
+
+// unitsDoc is the document of convert --list
+type unitsDoc struct {
+	Units []unitDoc `json:"units" yaml:"units"`
+}
+
+// unitDoc describes a registered unit
+type unitDoc struct {
+	Name        string `json:"name" yaml:"name"`
+	Dimension   string `json:"dimension" yaml:"dimension"`
+	Prefixes    bool   `json:"prefixes" yaml:"prefixes"`
+	Description string `json:"description" yaml:"description"`
+}
+
+func (d unitsDoc) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Units))
+	for i, u := range d.Units {
+		rows[i] = []string{u.Name, u.Dimension, strconv.FormatBool(u.Prefixes), u.Description}
+	}
+	return []string{"name", "dimension", "prefixes", "description"}, rows
+}
+
+// constantsDoc is the document of the constants command
+type constantsDoc struct {
+	Constants []constantDoc `json:"constants" yaml:"constants"`
+}
+
+// constantDoc describes a built-in constant
+type constantDoc struct {
+	Name        string       `json:"name" yaml:"name"`
+	Value       output.Float `json:"value" yaml:"value"`
+	Unit        string       `json:"unit,omitempty" yaml:"unit,omitempty"`
+	Description string       `json:"description" yaml:"description"`
+}
+
+func (d constantsDoc) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Constants))
+	for i, c := range d.Constants {
+		rows[i] = []string{c.Name, c.Value.String(), c.Unit, c.Description}
+	}
+	return []string{"name", "value", "unit", "description"}, rows
+}
+
+// statsDoc is the document of the stats command
+type statsDoc struct {
+	File       string         `json:"file" yaml:"file"`
+	Statistics []statisticDoc `json:"statistics" yaml:"statistics"`
+}
+
+// statisticDoc is one named statistic of a column
+type statisticDoc struct {
+	Name  string       `json:"name" yaml:"name"`
+	Value output.Float `json:"value" yaml:"value"`
+}
+
+func (d statsDoc) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Statistics))
+	for i, s := range d.Statistics {
+		rows[i] = []string{s.Name, s.Value.String()}
+	}
+	return []string{"statistic", "value"}, rows
+}
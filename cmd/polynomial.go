@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/output"
 	"github.com/trenchesdeveloper/gomathpro/internal/polynomial"
 )
 
@@ -14,75 +18,174 @@ import (
 var polynomialCmd = &cobra.Command{
 	Use:   "polynomial",
 	Short: "Perform polynomial operations",
-	Long:  `Perform polynomial operations like finding roots, factorization, and interpolation.`,
+	Long:  `Perform polynomial operations like finding roots, factorization, interpolation, division and GCDs.`,
 }
 
 // rootsCmd represents the roots command
 var rootsCmd = &cobra.Command{
 	Use:   "roots [coefficients]",
 	Short: "Find the roots of a polynomial",
-	Long:  `Find the roots of a polynomial given its coefficients. Example: gomathpro polynomial roots 1 -3 2`,
+	Long:  `Find the roots of a polynomial, with the radius of a disk around each that contains a true root and the number of iterations that refined it. Polynomials of degree 3 and above are solved with the Aberth-Ehrlich iteration, or with --method companion as the eigenvalues of the companion matrix. Example: gomathpro polynomial roots "x^3 - 6x^2 + 11x - 6"`,
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		method, err := polynomial.ParseRootMethod(rootsMethod)
+		if err != nil {
+			reportError(err)
+			return
+		}
+
 		polyStr := strings.Join(args, "")
 		coefficients, err := polynomial.ParsePolynomial(polyStr)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"error": err,
 			}).Error("Failed to parse polynomial")
-			fmt.Printf("Error: %v\n", err)
+			reportError(err)
 			return
 		}
 
-		roots, err := polynomial.FindRoots(coefficients)
+		opts := polynomial.RootOptions{Method: method, Tolerance: rootsTolerance, MaxIter: rootsMaxIter}
+		roots, err := polynomial.FindRootsWith(coefficients, opts)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"error": err,
 			}).Error("Failed to find roots")
-			fmt.Printf("Error: %v\n", err)
+			reportError(err)
 			return
 		}
 
-		fmt.Println("Roots:")
+		doc := rootsDoc{Polynomial: polyStr, Coefficients: output.Floats(coefficients), Method: opts.MethodFor(coefficients).String(), Converged: true, Roots: []rootDoc{}}
+		unconverged := 0
 		for _, root := range roots {
-			fmt.Printf("- %v\n", root)
+			doc.Roots = append(doc.Roots, newRootDoc(root))
+			if !root.Converged {
+				doc.Converged = false
+				unconverged++
+			}
 		}
+		emit(doc, func() {
+			fmt.Println("Roots:")
+			for _, root := range roots {
+				fmt.Printf("- %s (%s)\n", format.Complex(root.Value, displayFormat), rootDetails(root))
+			}
+			if unconverged > 0 {
+				fmt.Fprintf(os.Stderr, "Warning: %d of %d roots did not converge within %d iterations\n", unconverged, len(roots), rootsMaxIter)
+			}
+		})
 	},
 }
 
+// rootDetails describes the error bound and convergence of a root, as
+// "error < 3.2e-13, 6 iterations"
+func rootDetails(r polynomial.Root) string {
+	details := "error < " + format.Float(r.ErrorBound, format.Options{Digits: 2})
+	switch r.Iterations {
+	case 0:
+	case 1:
+		details += ", 1 iteration"
+	default:
+		details += fmt.Sprintf(", %d iterations", r.Iterations)
+	}
+	if !r.Converged {
+		details += ", not converged"
+	}
+	return details
+}
+
+// rootsTolerance, rootsMaxIter and rootsMethod control the root finder
+var (
+	rootsTolerance float64
+	rootsMaxIter   int
+	rootsMethod    string
+)
+
 // factorizeCmd represents the factorize command
 var factorizeCmd = &cobra.Command{
 	Use:   "factorize [coefficients]",
 	Short: "Factorize a polynomial",
-	Long:  `Factorize a polynomial given its coefficients. Example: gomathpro polynomial factorize 1 -3 2`,
+	Long:  `Factorize a polynomial exactly over the rationals, into its content and primitive factors with integer coefficients, using the rational root theorem and Kronecker's method for quadratic factors. With --real, it is factorized over the reals into its leading coefficient and powers of linear factors and irreducible quadratic factors, or with --complex into linear factors with complex roots. Example: gomathpro polynomial factorize "6x^3 - 11x^2 + 6x - 1"`,
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if factorizeReal && factorizeComplex {
+			reportError(errors.New("--real and --complex cannot be used together"))
+			return
+		}
+
 		polyStr := strings.Join(args, "")
 		coefficients, err := polynomial.ParsePolynomial(polyStr)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"error": err,
 			}).Error("Failed to parse polynomial")
-			fmt.Printf("Error: %v\n", err)
+			reportError(err)
 			return
 		}
 
-		factors, err := polynomial.Factorize(coefficients)
+		doc := factorsDoc{Polynomial: polyStr, Coefficients: output.Floats(coefficients)}
+		if factorizeReal || factorizeComplex {
+			err = factorizeNumeric(&doc, coefficients)
+		} else {
+			err = factorizeRational(&doc, coefficients)
+		}
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"error": err,
 			}).Error("Failed to factorize polynomial")
-			fmt.Printf("Error: %v\n", err)
+			reportError(err)
 			return
 		}
 
-		fmt.Println("Factors:")
-		for _, factor := range factors {
-			fmt.Printf("- %s\n", factor)
-		}
+		emit(doc, func() {
+			fmt.Printf("Factorization: %s\n", doc.Factorization)
+			fmt.Println("Factors:")
+			for _, factor := range doc.Factors {
+				fmt.Printf("- %s\n", factor)
+			}
+		})
 	},
 }
 
+// factorizeNumeric fills in doc with the factorization of p over the
+// reals, or with --complex over the complex numbers
+func factorizeNumeric(doc *factorsDoc, p polynomial.Polynomial) error {
+	factorize := polynomial.Factorize
+	if factorizeComplex {
+		factorize = polynomial.FactorizeComplex
+	}
+	f, err := factorize(p)
+	if err != nil {
+		return err
+	}
+	doc.Factorization = f.Format(displayFormat)
+	doc.Constant = format.Float(f.Leading, displayFormat)
+	doc.Factors = make([]string, len(f.Factors))
+	for i, factor := range f.Factors {
+		doc.Factors[i] = factor.Format(displayFormat)
+	}
+	return nil
+}
+
+// factorizeRational fills in doc with the exact factorization of p over
+// the rationals, or over the reals if p has coefficients that are not
+// rational
+func factorizeRational(doc *factorsDoc, p polynomial.Polynomial) error {
+	r, err := p.Rat()
+	if err != nil {
+		return factorizeNumeric(doc, p)
+	}
+	f, err := polynomial.FactorizeRational(r)
+	if err != nil {
+		return err
+	}
+	doc.Factorization = f.Format(displayFormat)
+	doc.Constant = format.Rat(f.Content, displayFormat)
+	doc.Factors = make([]string, len(f.Factors))
+	for i, factor := range f.Factors {
+		doc.Factors[i] = factor.Format(displayFormat)
+	}
+	return nil
+}
+
 // interpolateCmd represents the interpolate command
 var interpolateCmd = &cobra.Command{
 	Use:   "interpolate [x1 y1 x2 y2 ...]",
@@ -92,7 +195,7 @@ var interpolateCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args)%2 != 0 {
 			log.Error("Invalid number of arguments. Expected pairs of x and y values.")
-			fmt.Println("Error: Expected pairs of x and y values.")
+			reportError(errors.New("Expected pairs of x and y values."))
 			return
 		}
 
@@ -104,7 +207,7 @@ var interpolateCmd = &cobra.Command{
 				log.WithFields(logrus.Fields{
 					"error": fmt.Errorf("invalid point: %s, %s", args[i], args[i+1]),
 				}).Error("Invalid point")
-				fmt.Printf("Error: Invalid point: %s, %s\n", args[i], args[i+1])
+				reportError(fmt.Errorf("Invalid point: %s, %s", args[i], args[i+1]))
 				return
 			}
 			points[i/2] = [2]float64{x, y}
@@ -115,17 +218,104 @@ var interpolateCmd = &cobra.Command{
 			log.WithFields(logrus.Fields{
 				"error": err,
 			}).Error("Failed to interpolate polynomial")
-			fmt.Printf("Error: %v\n", err)
+			reportError(err)
+			return
+		}
+
+		doc := interpolationDoc{Points: points, Polynomial: coefficients.Format(displayFormat), Coefficients: output.Floats(coefficients)}
+		emit(doc, func() {
+			fmt.Printf("Interpolated Polynomial: %s\n", doc.Polynomial)
+			fmt.Println("Coefficients:")
+			for i, coeff := range coefficients {
+				fmt.Printf("x^%d: %s\n", i, format.Float(coeff, displayFormat))
+			}
+		})
+	},
+}
+
+// factorizeComplex factorizes into complex linear factors
+var factorizeComplex bool
+
+// factorizeReal factorizes over the reals instead of exactly over the
+// rationals
+var factorizeReal bool
+
+// gcdTolerance is the relative size below which remainders are treated as
+// zero when computing GCDs
+var gcdTolerance float64
+
+// divideCmd represents the divide command
+var divideCmd = &cobra.Command{
+	Use:   "divide [dividend] [divisor]",
+	Short: "Divide two polynomials",
+	Long:  `Divide one polynomial by another with long division, giving the quotient and remainder. Example: gomathpro polynomial divide "x^3 - 1" "x - 1"`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		polys, err := parsePolynomials(args)
+		if err != nil {
+			reportError(err)
+			return
+		}
+
+		quo, rem, err := polys[0].DivMod(polys[1])
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("Failed to divide polynomials")
+			reportError(err)
+			return
+		}
+
+		doc := divisionDoc{Dividend: args[0], Divisor: args[1], Quotient: newPolynomialDoc(quo), Remainder: newPolynomialDoc(rem)}
+		emit(doc, func() {
+			fmt.Printf("Quotient: %s\n", doc.Quotient.Polynomial)
+			fmt.Printf("Remainder: %s\n", doc.Remainder.Polynomial)
+		})
+	},
+}
+
+// gcdCmd represents the gcd command
+var gcdCmd = &cobra.Command{
+	Use:   "gcd [polynomial] [polynomial]...",
+	Short: "Find the greatest common divisor of polynomials",
+	Long:  `Find the monic greatest common divisor of two or more polynomials. Coefficients with rounding errors are handled by treating remainders smaller than --tolerance, relative to the polynomials divided, as zero. Example: gomathpro polynomial gcd "x^3 - 1" "x^2 - 1"`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		polys, err := parsePolynomials(args)
+		if err != nil {
+			reportError(err)
 			return
 		}
 
-		fmt.Println("Interpolated Polynomial Coefficients:")
-		for i, coeff := range coefficients {
-			fmt.Printf("x^%d: %.2f\n", i, coeff)
+		gcd := polys[0]
+		for _, p := range polys[1:] {
+			gcd = polynomial.GCD(gcd, p, gcdTolerance)
 		}
+
+		doc := gcdDoc{Polynomials: args, GCD: newPolynomialDoc(gcd)}
+		emit(doc, func() {
+			fmt.Printf("GCD: %s\n", doc.GCD.Polynomial)
+		})
 	},
 }
 
+// parsePolynomials parses each argument as a polynomial
+func parsePolynomials(args []string) ([]polynomial.Polynomial, error) {
+	polys := make([]polynomial.Polynomial, len(args))
+	for i, arg := range args {
+		p, err := polynomial.ParsePolynomial(arg)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"error":      err,
+				"polynomial": arg,
+			}).Error("Failed to parse polynomial")
+			return nil, err
+		}
+		polys[i] = p
+	}
+	return polys, nil
+}
+
 func init() {
 	// Add the polynomial command to the root command
 	RootCmd.AddCommand(polynomialCmd)
@@ -134,4 +324,13 @@ func init() {
 	polynomialCmd.AddCommand(rootsCmd)
 	polynomialCmd.AddCommand(factorizeCmd)
 	polynomialCmd.AddCommand(interpolateCmd)
+	polynomialCmd.AddCommand(divideCmd)
+	polynomialCmd.AddCommand(gcdCmd)
+
+	rootsCmd.Flags().Float64Var(&rootsTolerance, "tolerance", polynomial.DefaultRootTolerance, "relative change in a root below which the iteration has converged")
+	rootsCmd.Flags().IntVar(&rootsMaxIter, "max-iter", polynomial.DefaultMaxIter, "maximum number of iterations")
+	rootsCmd.Flags().StringVar(&rootsMethod, "method", "aberth", "root-finding method: aberth or companion")
+	factorizeCmd.Flags().BoolVar(&factorizeComplex, "complex", false, "factorize into linear factors with complex roots instead of real quadratics")
+	factorizeCmd.Flags().BoolVar(&factorizeReal, "real", false, "factorize over the reals into linear and quadratic factors instead of exactly over the rationals")
+	gcdCmd.Flags().Float64Var(&gcdTolerance, "tolerance", 1e-9, "relative size below which remainders are treated as zero, or 0 for exact division")
 }
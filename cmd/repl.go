@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peterh/liner"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/repl"
+)
+
+// historyFile is the name of the REPL history file in the home directory
+const historyFile = ".gomathpro_history"
+
+// replCmd represents the repl command
+var replCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Start an interactive session",
+	Long: `Start an interactive session in which variables and functions persist between lines.
+The value of the last expression is available as ans and _. Type :help for the meta-commands. Example: gomathpro repl`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := sessionOptions()
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return
+		}
+		r := repl.New(evaluator.New(opts), os.Stdout)
+		r.Format = displayFormat
+
+		line := liner.NewLiner()
+		defer line.Close()
+		line.SetCtrlCAborts(true)
+		line.SetMultiLineMode(true)
+		line.SetCompleter(r.Complete)
+
+		history := ""
+		if home, err := os.UserHomeDir(); err == nil {
+			history = filepath.Join(home, historyFile)
+			if f, err := os.Open(history); err == nil {
+				line.ReadHistory(f)
+				f.Close()
+			}
+		}
+
+		fmt.Println("gomathpro REPL. Type :help for commands, :quit to exit.")
+		for {
+			input, err := line.Prompt(r.Prompt())
+			if errors.Is(err, liner.ErrPromptAborted) {
+				// Ctrl-C abandons the statement being entered
+				r.Cancel()
+				continue
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					log.WithFields(logrus.Fields{"error": err}).Error("Failed to read input")
+				}
+				fmt.Println()
+				break
+			}
+			if strings.TrimSpace(input) != "" {
+				line.AppendHistory(input)
+			}
+			if errors.Is(r.Line(input), repl.ErrQuit) {
+				break
+			}
+		}
+
+		if history != "" {
+			if f, err := os.Create(history); err == nil {
+				line.WriteHistory(f)
+				f.Close()
+			}
+		}
+	},
+}
+
+func init() {
+	// Add the repl command to the root command
+	RootCmd.AddCommand(replCmd)
+
+	addSessionFlags(replCmd)
+}
@@ -5,7 +5,12 @@ Copyright © 2025 Opeyemi Samuel <opeyemisamuel222@gmail.com>
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/output"
 )
 
 // RootCmd represents the base command
@@ -13,9 +18,55 @@ var RootCmd = &cobra.Command{
 	Use:   "gomathpro",
 	Short: "A CLI tool for mathematical computations",
 	Long:  `A CLI tool for performing mathematical computations like arithmetic, algebra, calculus, and more.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		format, err := output.ParseFormat(outputName)
+		if err != nil {
+			return err
+		}
+		outputFormat = format
+		return parseDisplayFormat()
+	},
+}
+
+// outputName is the value of the --output flag
+var outputName string
+
+// Values of the number formatting flags
+var (
+	digits    int
+	notation  string
+	thousands bool
+	trimZeros bool
+)
+
+// parseDisplayFormat sets displayFormat from the number formatting flags
+func parseDisplayFormat() error {
+	if digits < 0 {
+		return fmt.Errorf("--digits must not be negative")
+	}
+	n, err := format.ParseNotation(notation)
+	if err != nil {
+		return err
+	}
+	displayFormat = format.Options{
+		Notation:  n,
+		Digits:    digits,
+		Grouping:  thousands,
+		TrimZeros: trimZeros,
+		Precision: evalPrecision,
+	}
+	return nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
 	return RootCmd.Execute()
-}
\ No newline at end of file
+}
+
+func init() {
+	RootCmd.PersistentFlags().StringVarP(&outputName, "output", "o", string(output.Text), "output format: text, json, yaml or csv")
+	RootCmd.PersistentFlags().IntVar(&digits, "digits", 0, "significant digits to display, or digits after the point with --notation fixed (0 for all)")
+	RootCmd.PersistentFlags().StringVar(&notation, "notation", "auto", "number notation: auto, fixed, sci or eng")
+	RootCmd.PersistentFlags().BoolVar(&thousands, "thousands", false, "separate thousands with commas")
+	RootCmd.PersistentFlags().BoolVar(&trimZeros, "trim-zeros", false, "drop trailing zeros after the decimal point")
+}
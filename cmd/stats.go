@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/dataset"
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/output"
+)
+
+// statsColumn selects the column to summarise, by number or header name
+var statsColumn string
+
+// statsDelimiter separates the fields of a row, detected when empty
+var statsDelimiter string
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats [file | -]",
+	Short: "Summarise a column of numbers",
+	Long: `Summarise a numeric column of a file, or of standard input when no file or - is given: count, sum, mean, median, standard deviation, variance, minimum, maximum and quartiles. Rows are split on commas, tabs, semicolons or spaces, a header row names the columns, and lines starting with # are skipped. Example: gomathpro stats --column price sales.csv
+Example: seq 1 10 | gomathpro stats`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var in io.Reader = os.Stdin
+		name := "<stdin>"
+		if len(args) == 1 && args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				reportError(err)
+				return
+			}
+			defer f.Close()
+			in, name = f, args[0]
+		}
+
+		values, err := dataset.ReadColumn(in, dataset.Options{Column: statsColumn, Delimiter: statsDelimiter})
+		if err == nil && len(values) == 0 {
+			err = fmt.Errorf("no values in %s", name)
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"error": err,
+				"file":  name,
+			}).Error("Failed to read data")
+			reportError(err)
+			return
+		}
+
+		doc, err := summarise(name, values)
+		if err != nil {
+			reportError(err)
+			return
+		}
+		emit(doc, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, s := range doc.Statistics {
+				fmt.Fprintf(w, "%s\t%s\n", s.Name, format.Float(float64(s.Value), displayFormat))
+			}
+			w.Flush()
+		})
+	},
+}
+
+// summarise computes the statistics of values with the evaluator's
+// statistical functions
+func summarise(name string, values []float64) (statsDoc, error) {
+	data := make([]interface{}, len(values))
+	for i, x := range values {
+		data[i] = x
+	}
+	functions := evaluator.Builtins()
+
+	doc := statsDoc{File: name, Statistics: []statisticDoc{{Name: "count", Value: output.Float(len(values))}}}
+	for _, s := range []struct {
+		name, function string
+		args           []interface{}
+	}{
+		{"sum", "sum", nil},
+		{"mean", "mean", nil},
+		{"median", "median", nil},
+		{"stdev", "stdev", nil},
+		{"var", "var", nil},
+		{"min", "min", nil},
+		{"q1", "percentile", []interface{}{25.0}},
+		{"q3", "percentile", []interface{}{75.0}},
+		{"max", "max", nil},
+	} {
+		if len(values) < 2 && (s.name == "stdev" || s.name == "var") {
+			continue
+		}
+		fn, _ := functions.Lookup(s.function)
+		result, err := fn.Call(append([]interface{}{data}, s.args...)...)
+		if err != nil {
+			return statsDoc{}, err
+		}
+		doc.Statistics = append(doc.Statistics, statisticDoc{Name: s.name, Value: output.Float(result.(float64))})
+	}
+	return doc, nil
+}
+
+func init() {
+	// Add the stats command to the root command
+	RootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVarP(&statsColumn, "column", "c", "1", "column to summarise, by number from 1 or by header name")
+	statsCmd.Flags().StringVarP(&statsDelimiter, "delimiter", "d", "", "field delimiter (default: detect a comma, tab, semicolon or spaces)")
+}
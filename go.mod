@@ -3,14 +3,16 @@ module github.com/trenchesdeveloper/gomathpro
 go 1.23.4
 
 require (
-	github.com/Knetic/govaluate v3.0.0+incompatible
+	github.com/peterh/liner v1.2.2
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.8.1
+	gonum.org/v1/gonum v0.15.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/mattn/go-runewidth v0.0.3 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/sys v0.29.0 // indirect
-	gonum.org/v1/gonum v0.15.1 // indirect
 )
@@ -0,0 +1,137 @@
+// Package dataset reads numeric data from delimited text, such as a column
+// of a CSV file, for statistical summaries.
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Options configures ReadColumn.
+type Options struct {
+	// Column selects the column by its 1-based index or by the name in the
+	// header row. Empty means the first column.
+	Column string
+	// Delimiter separates the fields of a row. Empty means the first of a
+	// comma, a tab or a semicolon that the first row has, and runs of
+	// spaces otherwise.
+	Delimiter string
+}
+
+// ParseError reports a field that is not a number.
+type ParseError struct {
+	Line  int
+	Field string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %q is not a number", e.Line, e.Field)
+}
+
+// ReadColumn reads the numbers in one column of r. Blank lines and lines
+// starting with "#" are skipped. A first row whose field in the column is
+// not a number is taken as a header, which names the columns.
+func ReadColumn(r io.Reader, opts Options) ([]float64, error) {
+	index := 0
+	if opts.Column != "" {
+		n, err := strconv.Atoi(opts.Column)
+		switch {
+		case err != nil:
+			index = -1
+		case n < 1:
+			return nil, fmt.Errorf("invalid column %d: columns start at 1", n)
+		default:
+			index = n - 1
+		}
+	}
+
+	var values []float64
+	scanner := bufio.NewScanner(r)
+	first := true
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if opts.Delimiter == "" {
+			opts.Delimiter = detectDelimiter(text)
+		}
+		fields := split(text, opts.Delimiter)
+
+		if first {
+			first = false
+			if index < 0 {
+				if index = find(fields, opts.Column); index < 0 {
+					return nil, fmt.Errorf("no column named %q in the header", opts.Column)
+				}
+				continue
+			}
+			if index < len(fields) {
+				if _, err := parse(fields[index]); err != nil {
+					continue
+				}
+			}
+		}
+
+		if index >= len(fields) {
+			return nil, fmt.Errorf("line %d: no column %d", line, index+1)
+		}
+		x, err := parse(fields[index])
+		if err != nil {
+			return nil, &ParseError{Line: line, Field: fields[index]}
+		}
+		values = append(values, x)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// detectDelimiter picks the delimiter of the rows from the first one.
+func detectDelimiter(row string) string {
+	for _, d := range []string{",", "\t", ";"} {
+		if strings.Contains(row, d) {
+			return d
+		}
+	}
+	return " "
+}
+
+// split splits a row into its trimmed fields. A space delimiter matches
+// any run of white space.
+func split(row, delimiter string) []string {
+	if delimiter == " " {
+		return strings.Fields(row)
+	}
+	fields := strings.Split(row, delimiter)
+	for i, f := range fields {
+		fields[i] = strings.Trim(strings.TrimSpace(f), `"`)
+	}
+	return fields
+}
+
+// find returns the index of the named field, or -1.
+func find(fields []string, name string) int {
+	for i, f := range fields {
+		if f == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// parse parses a field as a finite number. NaN and the infinities, which
+// strconv.ParseFloat accepts, are rejected so that a stray cell cannot
+// turn every statistic into NaN.
+func parse(field string) (float64, error) {
+	x, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+	if err == nil && (math.IsNaN(x) || math.IsInf(x, 0)) {
+		return 0, fmt.Errorf("%q is not a finite number", field)
+	}
+	return x, err
+}
@@ -0,0 +1,67 @@
+package dataset
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestReadColumn tests selecting columns in the supported layouts
+func TestReadColumn(t *testing.T) {
+	const csv = `# sensor log
+time,temp,"humidity"
+0,20.5,40
+1,21,42
+
+2,22.5,41
+`
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected []float64
+	}{
+		{"Single column", "3\n1\n2\n", Options{}, []float64{3, 1, 2}},
+		{"Header is skipped", csv, Options{Column: "2"}, []float64{20.5, 21, 22.5}},
+		{"Column by name", csv, Options{Column: "humidity"}, []float64{40, 42, 41}},
+		{"Whitespace", "a  b\n1  2\n3\t4\n", Options{Column: "b"}, []float64{2, 4}},
+		{"Tabs", "1\t2\n3\t4\n", Options{Column: "2"}, []float64{2, 4}},
+		{"Explicit delimiter", "1|2\n3|4\n", Options{Column: "1", Delimiter: "|"}, []float64{1, 3}},
+		{"Empty input", "", Options{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadColumn(strings.NewReader(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestReadColumnErrors tests malformed data and bad column selections
+func TestReadColumnErrors(t *testing.T) {
+	var parseErr *ParseError
+	_, err := ReadColumn(strings.NewReader("x\n1\ntwo\n"), Options{})
+	if !errors.As(err, &parseErr) || parseErr.Line != 3 || parseErr.Field != "two" {
+		t.Errorf("Expected a parse error for two on line 3, got %v", err)
+	}
+
+	for _, field := range []string{"NaN", "inf", "-Infinity", "1e400"} {
+		_, err := ReadColumn(strings.NewReader("x\n1\n"+field+"\n2\n"), Options{})
+		if !errors.As(err, &parseErr) || parseErr.Line != 3 || parseErr.Field != field {
+			t.Errorf("Expected a parse error for %s on line 3, got %v", field, err)
+		}
+	}
+
+	for _, opts := range []Options{{Column: "0"}, {Column: "3"}, {Column: "missing"}} {
+		if _, err := ReadColumn(strings.NewReader("a,b\n1,2\n"), opts); err == nil {
+			t.Errorf("Column %q: expected an error", opts.Column)
+		}
+	}
+}
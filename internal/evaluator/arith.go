@@ -0,0 +1,313 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// Numbers are float64, *big.Rat in exact mode or *big.Float in
+// arbitrary-precision mode. Exact operands stay exact under the arithmetic
+// operators; an operation with no exact result such as 2^0.5 falls back to
+// float64. Mixing a *big.Float with any other number gives a *big.Float,
+// and mixing a *big.Rat with a float64 gives a float64. Complex numbers,
+// which take precedence over all of these, are handled in complex.go, and
+// quantities with units, which take precedence over complex numbers, in
+// quantity.go. Lists are described in list.go.
+
+// unaryOp applies a prefix operator to an evaluated operand.
+func unaryOp(op string, x interface{}) (interface{}, error) {
+	if op == "!" {
+		b, ok := truth(x)
+		if !ok {
+			return nil, &TypeError{Msg: "operator ! expects a boolean or real operand"}
+		}
+		return !b, nil
+	}
+	switch v := x.(type) {
+	case float64:
+		if op == "-" {
+			return -v, nil
+		}
+		return v, nil
+	case *big.Rat:
+		if op == "-" {
+			return new(big.Rat).Neg(v), nil
+		}
+		return v, nil
+	case *big.Float:
+		if op == "-" {
+			return newFloat(v.Prec()).Neg(v), nil
+		}
+		return v, nil
+	case complex128:
+		if op == "-" {
+			return -v, nil
+		}
+		return v, nil
+	case units.Quantity:
+		if op == "-" {
+			return units.New(-v.Value, v.Unit), nil
+		}
+		return v, nil
+	}
+	return nil, &TypeError{Msg: fmt.Sprintf("operator %s expects a numeric operand", op)}
+}
+
+// binaryOp applies an infix operator to two evaluated operands.
+func binaryOp(op string, x, y interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return equal(x, y), nil
+	case "!=":
+		return !equal(x, y), nil
+	case "..":
+		return rangeOf(x, y)
+	}
+
+	if isList(x, y) {
+		return nil, &TypeError{Msg: fmt.Sprintf("operator %s is not defined for lists", op)}
+	}
+	if isQuantity(x, y) {
+		a, ok1 := toQuantity(x)
+		b, ok2 := toQuantity(y)
+		if !ok1 || !ok2 {
+			return nil, &TypeError{Msg: fmt.Sprintf("operator %s expects real numbers or quantities", op)}
+		}
+		return quantityOp(op, a, b)
+	}
+	if isComplex(x, y) {
+		a, ok1 := toComplex(x)
+		b, ok2 := toComplex(y)
+		if !ok1 || !ok2 {
+			return nil, &TypeError{Msg: fmt.Sprintf("operator %s expects numeric operands", op)}
+		}
+		return complexOp(op, a, b)
+	}
+	if a, ok := x.(*big.Rat); ok {
+		if b, ok := y.(*big.Rat); ok {
+			return ratOp(op, a, b)
+		}
+	}
+	if prec := bigPrec(x, y); prec > 0 {
+		a, ok1 := toBigFloat(x, prec)
+		b, ok2 := toBigFloat(y, prec)
+		if !ok1 || !ok2 {
+			return nil, &TypeError{Msg: fmt.Sprintf("operator %s expects numeric operands", op)}
+		}
+		return bigOp(op, a, b)
+	}
+
+	a, ok1 := toFloat(x)
+	b, ok2 := toFloat(y)
+	if !ok1 || !ok2 {
+		return nil, &TypeError{Msg: fmt.Sprintf("operator %s expects numeric operands", op)}
+	}
+	return floatOp(op, a, b)
+}
+
+// floatOp applies an arithmetic or ordering operator to two floats.
+func floatOp(op string, a, b float64) (interface{}, error) {
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		return math.Mod(a, b), nil
+	case "^":
+		return math.Pow(a, b), nil
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	case ">":
+		return a > b, nil
+	case ">=":
+		return a >= b, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// ratOp applies an arithmetic or ordering operator to two rationals.
+func ratOp(op string, a, b *big.Rat) (interface{}, error) {
+	switch op {
+	case "+":
+		return new(big.Rat).Add(a, b), nil
+	case "-":
+		return new(big.Rat).Sub(a, b), nil
+	case "*":
+		return new(big.Rat).Mul(a, b), nil
+	case "/":
+		if b.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		return new(big.Rat).Quo(a, b), nil
+	case "%":
+		if b.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		// Truncated remainder, matching math.Mod: a - b*trunc(a/b)
+		q := new(big.Rat).Quo(a, b)
+		t := new(big.Rat).SetInt(new(big.Int).Quo(q.Num(), q.Denom()))
+		return new(big.Rat).Sub(a, t.Mul(t, b)), nil
+	case "^":
+		if !b.IsInt() || !b.Num().IsInt64() {
+			af, _ := a.Float64()
+			bf, _ := b.Float64()
+			return math.Pow(af, bf), nil
+		}
+		return ratPow(a, b.Num().Int64())
+	case "<":
+		return a.Cmp(b) < 0, nil
+	case "<=":
+		return a.Cmp(b) <= 0, nil
+	case ">":
+		return a.Cmp(b) > 0, nil
+	case ">=":
+		return a.Cmp(b) >= 0, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// maxPowBits is the largest number of bits in the numerator or denominator
+// of an exact power. Larger powers are refused rather than computed.
+const maxPowBits = 1 << 22
+
+// ratPow raises a rational to an integer power.
+func ratPow(a *big.Rat, n int64) (*big.Rat, error) {
+	if n < 0 {
+		if a.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		a = new(big.Rat).Inv(a)
+		n = -n
+	}
+	switch {
+	case n == 0:
+		return big.NewRat(1, 1), nil
+	case a.IsInt() && a.Num().CmpAbs(big.NewInt(1)) <= 0:
+		// Powers of 0, 1 and -1 stay small however large n is
+		if a.Sign() < 0 && n%2 == 0 {
+			return big.NewRat(1, 1), nil
+		}
+		return new(big.Rat).Set(a), nil
+	}
+	// The result has at least n times as many bits, less one, as the larger
+	// of the numerator and denominator
+	if bits := int64(max(a.Num().BitLen(), a.Denom().BitLen()) - 1); n > maxPowBits/bits {
+		return nil, &DomainError{Func: "pow", Msg: fmt.Sprintf("exponent %d is too large, the exact result would exceed %d bits", n, maxPowBits)}
+	}
+	e := big.NewInt(n)
+	num := new(big.Int).Exp(a.Num(), e, nil)
+	den := new(big.Int).Exp(a.Denom(), e, nil)
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// bigPrec returns the largest precision of the *big.Float operands among
+// values, or 0 if there are none.
+func bigPrec(values ...interface{}) uint {
+	var prec uint
+	for _, v := range values {
+		if f, ok := v.(*big.Float); ok && f.Prec() > prec {
+			prec = f.Prec()
+		}
+	}
+	return prec
+}
+
+// equal reports whether two values are equal. Numbers compare by value
+// regardless of representation, and lists element by element.
+func equal(x, y interface{}) bool {
+	if isList(x, y) {
+		a, ok1 := x.([]interface{})
+		b, ok2 := y.([]interface{})
+		return ok1 && ok2 && equalLists(a, b)
+	}
+	if isQuantity(x, y) {
+		a, ok1 := toQuantity(x)
+		b, ok2 := toQuantity(y)
+		if !ok1 || !ok2 {
+			return false
+		}
+		c, err := units.Compare(a, b)
+		return err == nil && c == 0
+	}
+	if isComplex(x, y) {
+		a, ok1 := toComplex(x)
+		b, ok2 := toComplex(y)
+		return ok1 && ok2 && a == b
+	}
+	if a, ok := x.(*big.Rat); ok {
+		if b, ok := y.(*big.Rat); ok {
+			return a.Cmp(b) == 0
+		}
+	}
+	if prec := bigPrec(x, y); prec > 0 {
+		a, ok1 := toBigFloat(x, prec)
+		b, ok2 := toBigFloat(y, prec)
+		if ok1 && ok2 {
+			return a.Cmp(b) == 0
+		}
+		return false
+	}
+	a, ok1 := toFloat(x)
+	b, ok2 := toFloat(y)
+	if ok1 && ok2 {
+		return a == b
+	}
+	return x == y
+}
+
+// truth returns the truth value of a condition: a boolean, or a real number,
+// which is true when it is not zero.
+func truth(x interface{}) (bool, bool) {
+	switch v := x.(type) {
+	case bool:
+		return v, true
+	case float64:
+		return v != 0, true
+	case *big.Rat:
+		return v.Sign() != 0, true
+	case *big.Float:
+		return v.Sign() != 0, true
+	}
+	return false, false
+}
+
+// numericArgs checks that all arguments to the named function are numbers.
+func numericArgs(name string, args []interface{}) error {
+	for _, arg := range args {
+		if _, ok := toComplex(arg); !ok {
+			return &TypeError{Msg: fmt.Sprintf("%s expects numeric arguments", name)}
+		}
+	}
+	return nil
+}
+
+// toFloat converts a real number to float64.
+func toFloat(x interface{}) (float64, bool) {
+	switch v := x.(type) {
+	case float64:
+		return v, true
+	case *big.Rat:
+		f, _ := v.Float64()
+		return f, true
+	case *big.Float:
+		f, _ := v.Float64()
+		return f, true
+	}
+	return 0, false
+}
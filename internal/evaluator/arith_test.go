@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestExactMode tests rational arithmetic in exact sessions
+func TestExactMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"Fraction sum", "1/3 + 1/6", "1/2", false},
+		{"Decimal literals", "0.1 + 0.2", "3/10", false},
+		{"Scientific literal", "1.5e2 / 4", "75/2", false},
+		{"Integer result", "6 / 3", "2", false},
+		{"Large factorial", "fact(25)", "15511210043330985984000000", false},
+		{"Negative integer exponent", "2 ^ -3", "1/8", false},
+		{"Fraction power", "(2/3) ^ 3", "8/27", false},
+		{"Perfect square root", "sqrt(9/4)", "3/2", false},
+		{"Irrational root", "sqrt(2)", "1.4142135623730951", false},
+		{"Irrational function", "sin(0) + 1/2", "0.5", false},
+		{"Fractional exponent", "4 ^ 0.5", "2", false},
+		{"Modulo", "-7 % 3", "-1", false},
+		{"Absolute value", "abs(-5/2)", "5/2", false},
+		{"Floor", "floor(-5/2)", "-3", false},
+		{"Ceiling", "ceil(5/2)", "3", false},
+		{"Round half away from zero", "round(-5/2)", "-3", false},
+		{"Minimum", "min(1/3, 1/4)", "1/4", false},
+		{"Power function", "pow(1/2, 2)", "1/4", false},
+		{"Exact comparison", "1/3 + 1/3 + 1/3 == 1", "true", false},
+		{"Variables stay exact", "A = 1/3; B = A * 3; B", "1", false},
+		{"Division by zero", "1 / (1/2 - 1/2)", "", true},
+		{"Zero to a negative power", "0 ^ -1", "", true},
+		{"Large power of one", "(-1) ^ (10^10)", "1", false},
+		{"Large power of zero", "0 ^ (10^10)", "0", false},
+		{"Power at the limit", "2 ^ 4194304 > 0", "true", false},
+		{"Power too large", "2 ^ 100000000", "", true},
+		{"Tower too large", "10 ^ (10^10)", "", true},
+		{"Fraction power too large", "(1/3) ^ -10000000", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{Exact: true}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestExactResultType tests that exact sessions return *big.Rat and other
+// sessions float64
+func TestExactResultType(t *testing.T) {
+	result, err := New(Options{Exact: true}).Evaluate("1/2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r, ok := result.(*big.Rat); !ok || r.Cmp(big.NewRat(1, 2)) != 0 {
+		t.Errorf("Expected *big.Rat 1/2, got %T %v", result, result)
+	}
+
+	result, err = New(Options{}).Evaluate("1/2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != 0.5 {
+		t.Errorf("Expected float64 0.5, got %T %v", result, result)
+	}
+}
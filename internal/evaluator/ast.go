@@ -0,0 +1,152 @@
+package evaluator
+
+import "github.com/trenchesdeveloper/gomathpro/internal/units"
+
+// Node is an element of the expression syntax tree. Positions are byte
+// offsets into the evaluated input.
+type Node interface {
+	// Pos returns the offset of the first byte of the node.
+	Pos() int
+	// End returns the offset just past the last byte of the node.
+	End() int
+}
+
+// NumberLit is a numeric literal such as 42, 1.5e3 or 2i.
+type NumberLit struct {
+	// Text is the literal without any imaginary suffix.
+	Text  string
+	Value float64
+	// Imaginary is set for literals with an i or j suffix, whose value is
+	// Value times the imaginary unit.
+	Imaginary bool
+	From, To  int
+}
+
+// BoolLit is the literal true or false.
+type BoolLit struct {
+	Value    bool
+	From, To int
+}
+
+// Ident is a reference to a variable.
+type Ident struct {
+	Name     string
+	From, To int
+}
+
+// UnaryExpr is a prefix operation such as -x.
+type UnaryExpr struct {
+	Op    string
+	OpPos int
+	X     Node
+}
+
+// BinaryExpr is an infix operation such as a + b.
+type BinaryExpr struct {
+	Op    string
+	OpPos int
+	X, Y  Node
+}
+
+// ParenExpr is a parenthesised expression.
+type ParenExpr struct {
+	Lparen int
+	X      Node
+	Rparen int
+}
+
+// ListExpr is a list literal such as [1, 2, 3].
+type ListExpr struct {
+	Lbrack int
+	Elems  []Node
+	Rbrack int
+}
+
+// CallExpr is a function call such as max(a, b).
+type CallExpr struct {
+	Name   *Ident
+	Args   []Node
+	Rparen int
+}
+
+// CondExpr is a conditional expression, written c ? a : b, if(c, a, b) or
+// piecewise(c1, a1, c2, a2, ..., otherwise). Its value is that of the first
+// of Values whose condition holds, or else that of Else. Only the conditions
+// up to the first that holds and the chosen value are evaluated.
+type CondExpr struct {
+	Conds, Values []Node
+	// Else is nil for a piecewise without an otherwise value.
+	Else     Node
+	From, To int
+}
+
+// UnitLit is a unit such as km or m/s^2, written after a number in a
+// QuantityExpr or as the target of a ConvertExpr.
+type UnitLit struct {
+	Unit units.Unit
+	// Names are the names of the units it is made of.
+	Names    []*Ident
+	From, To int
+}
+
+// QuantityExpr is a number or parenthesised expression followed by a unit,
+// such as 5 km or (1 + 2) m/s.
+type QuantityExpr struct {
+	X    Node
+	Unit *UnitLit
+}
+
+// ConvertExpr converts a quantity to another unit of the same dimension:
+// to(72 degF, degC).
+type ConvertExpr struct {
+	X        Node
+	Unit     *UnitLit
+	From, To int
+}
+
+// AssignStmt binds the value of an expression to a variable.
+type AssignStmt struct {
+	Name  *Ident
+	Value Node
+}
+
+// FuncDef defines a function, as in f(x, y) = x^2 + y.
+type FuncDef struct {
+	Name   *Ident
+	Params []*Ident
+	Body   Node
+}
+
+// Program is a parsed input: a sequence of statements separated by ";".
+type Program struct {
+	Statements []Node
+}
+
+func (n *NumberLit) Pos() int    { return n.From }
+func (n *NumberLit) End() int    { return n.To }
+func (n *BoolLit) Pos() int      { return n.From }
+func (n *BoolLit) End() int      { return n.To }
+func (n *Ident) Pos() int        { return n.From }
+func (n *Ident) End() int        { return n.To }
+func (n *UnaryExpr) Pos() int    { return n.OpPos }
+func (n *UnaryExpr) End() int    { return n.X.End() }
+func (n *BinaryExpr) Pos() int   { return n.X.Pos() }
+func (n *BinaryExpr) End() int   { return n.Y.End() }
+func (n *ParenExpr) Pos() int    { return n.Lparen }
+func (n *ParenExpr) End() int    { return n.Rparen + 1 }
+func (n *ListExpr) Pos() int     { return n.Lbrack }
+func (n *ListExpr) End() int     { return n.Rbrack + 1 }
+func (n *CallExpr) Pos() int     { return n.Name.Pos() }
+func (n *CallExpr) End() int     { return n.Rparen + 1 }
+func (n *CondExpr) Pos() int     { return n.From }
+func (n *CondExpr) End() int     { return n.To }
+func (n *UnitLit) Pos() int      { return n.From }
+func (n *UnitLit) End() int      { return n.To }
+func (n *QuantityExpr) Pos() int { return n.X.Pos() }
+func (n *QuantityExpr) End() int { return n.Unit.End() }
+func (n *ConvertExpr) Pos() int  { return n.From }
+func (n *ConvertExpr) End() int  { return n.To }
+func (n *AssignStmt) Pos() int   { return n.Name.Pos() }
+func (n *AssignStmt) End() int   { return n.Value.End() }
+func (n *FuncDef) Pos() int      { return n.Name.Pos() }
+func (n *FuncDef) End() int      { return n.Body.End() }
@@ -0,0 +1,683 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+)
+
+// Arbitrary-precision evaluation represents numbers as *big.Float. The
+// elementary functions below compute with guardBits more than the precision
+// of their argument and round the result back, so results are correct to
+// the argument's precision.
+
+// guardBits is the extra working precision used inside the functions.
+const guardBits = 64
+
+// bitsForDigits returns the big.Float precision, in bits, that holds the
+// given number of significant decimal digits.
+func bitsForDigits(digits int) uint {
+	return uint(math.Ceil(float64(digits) * math.Log2(10)))
+}
+
+// digitsForBits returns the number of significant decimal digits a
+// precision of prec bits holds.
+func digitsForBits(prec uint) int {
+	return int(float64(prec) * math.Log10(2))
+}
+
+// newFloat returns a zero *big.Float with the given precision.
+func newFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec)
+}
+
+// bigInt returns n as a *big.Float with the given precision.
+func bigInt(n int64, prec uint) *big.Float {
+	return newFloat(prec).SetInt64(n)
+}
+
+// round returns x rounded to prec bits.
+func round(x *big.Float, prec uint) *big.Float {
+	return newFloat(prec).Set(x)
+}
+
+// exponent returns the binary exponent of x, or 0 for zero.
+func exponent(x *big.Float) int {
+	if x.Sign() == 0 || x.IsInf() {
+		return 0
+	}
+	return x.MantExp(nil)
+}
+
+// negligible reports whether term no longer affects sum at prec bits.
+func negligible(term, sum *big.Float, prec uint) bool {
+	return term.Sign() == 0 || (sum.Sign() != 0 && exponent(term) < exponent(sum)-int(prec))
+}
+
+// constantCache memoises constants such as pi and ln 2 at the highest
+// precision computed so far, from which lower precisions are rounded, so
+// that it holds one value per constant however many precisions are used.
+var constantCache = struct {
+	sync.Mutex
+	values map[string]*big.Float
+}{values: make(map[string]*big.Float)}
+
+// cachedConstant returns the named constant at prec bits, computing it with
+// compute when it is not cached to at least prec plus guardBits bits. The
+// lock is not held while computing, as constants are computed from others.
+func cachedConstant(name string, prec uint, compute func(prec uint) *big.Float) *big.Float {
+	constantCache.Lock()
+	v, ok := constantCache.values[name]
+	constantCache.Unlock()
+	if ok && v.Prec() >= prec+guardBits {
+		return round(v, prec)
+	}
+
+	v = compute(prec + guardBits)
+	constantCache.Lock()
+	if cached, ok := constantCache.values[name]; !ok || cached.Prec() < v.Prec() {
+		constantCache.values[name] = v
+	}
+	constantCache.Unlock()
+	return round(v, prec)
+}
+
+// bigPi returns pi to prec bits, using Machin's formula
+// pi = 16 atan(1/5) - 4 atan(1/239).
+func bigPi(prec uint) *big.Float {
+	return cachedConstant("pi", prec, func(prec uint) *big.Float {
+		a := atanInv(5, prec)
+		a.Mul(a, bigInt(16, prec))
+		b := atanInv(239, prec)
+		b.Mul(b, bigInt(4, prec))
+		return a.Sub(a, b)
+	})
+}
+
+// bigLn2 returns ln 2 to prec bits, as 2 atanh(1/3).
+func bigLn2(prec uint) *big.Float {
+	return cachedConstant("ln2", prec, func(prec uint) *big.Float {
+		z := newFloat(prec).Quo(bigInt(1, prec), bigInt(3, prec))
+		s := atanhSeries(z, prec)
+		return s.Mul(s, bigInt(2, prec))
+	})
+}
+
+// atanInv returns atan(1/n) for an integer n > 1 from its Taylor series.
+func atanInv(n int64, prec uint) *big.Float {
+	x := newFloat(prec).Quo(bigInt(1, prec), bigInt(n, prec))
+	n2 := bigInt(n*n, prec)
+	sum := round(x, prec)
+	power := round(x, prec)
+	for k := int64(1); ; k++ {
+		power.Quo(power, n2)
+		term := newFloat(prec).Quo(power, bigInt(2*k+1, prec))
+		if k%2 == 1 {
+			sum.Sub(sum, term)
+		} else {
+			sum.Add(sum, term)
+		}
+		if negligible(term, sum, prec) {
+			return sum
+		}
+	}
+}
+
+// atanhSeries returns atanh(z) = z + z^3/3 + z^5/5 + ... for |z| < 1.
+func atanhSeries(z *big.Float, prec uint) *big.Float {
+	z2 := newFloat(prec).Mul(z, z)
+	sum := round(z, prec)
+	power := round(z, prec)
+	for k := int64(1); ; k++ {
+		power.Mul(power, z2)
+		term := newFloat(prec).Quo(power, bigInt(2*k+1, prec))
+		sum.Add(sum, term)
+		if negligible(term, sum, prec) {
+			return sum
+		}
+	}
+}
+
+// bigSqrt returns the square root of x.
+func bigSqrt(x *big.Float) (*big.Float, error) {
+	if x.Sign() < 0 {
+		return nil, &DomainError{Func: "sqrt", Msg: "square root of negative number"}
+	}
+	return newFloat(x.Prec()).Sqrt(x), nil
+}
+
+// bigExp returns e^x. The argument is reduced to r = x - n ln 2 with
+// |r| <= ln(2)/2 and scaled down by 2^scaleBits before summing the Taylor
+// series, then the result is squared back up and multiplied by 2^n.
+func bigExp(x *big.Float) *big.Float {
+	const scaleBits = 16
+	prec := x.Prec()
+	if x.Sign() == 0 {
+		return bigInt(1, prec)
+	}
+	if x.IsInf() {
+		if x.Sign() > 0 {
+			return newFloat(prec).SetInf(false)
+		}
+		return newFloat(prec)
+	}
+
+	// Results beyond the exponent range of big.Float overflow or underflow
+	if f, _ := x.Float64(); math.Abs(f) > float64(math.MaxInt32)*math.Ln2 {
+		if f > 0 {
+			return newFloat(prec).SetInf(false)
+		}
+		return newFloat(prec)
+	}
+
+	wp := prec + guardBits + scaleBits + uint(max(exponent(x), 0))
+	ln2 := bigLn2(wp)
+	q := newFloat(wp).Quo(x, ln2)
+	n := roundToInt(q).Int64()
+	r := newFloat(wp).Mul(bigInt(n, wp), ln2)
+	r.Sub(round(x, wp), r)
+	r.SetMantExp(r, -scaleBits)
+
+	sum := bigInt(1, wp)
+	term := bigInt(1, wp)
+	for k := int64(1); ; k++ {
+		term.Mul(term, r)
+		term.Quo(term, bigInt(k, wp))
+		sum.Add(sum, term)
+		if negligible(term, sum, wp) {
+			break
+		}
+	}
+	for i := 0; i < scaleBits; i++ {
+		sum.Mul(sum, sum)
+	}
+	return round(sum.SetMantExp(sum, int(n)), prec)
+}
+
+// bigLog returns the natural logarithm of x > 0. Writing x = m 2^e with m
+// in [1/sqrt 2, sqrt 2), log x = 2 atanh((m-1)/(m+1)) + e ln 2.
+func bigLog(x *big.Float) (*big.Float, error) {
+	prec := x.Prec()
+	if x.Sign() <= 0 {
+		return nil, &DomainError{Func: "log", Msg: "logarithm of non-positive number"}
+	}
+	if x.IsInf() {
+		return newFloat(prec).SetInf(false), nil
+	}
+	wp := prec + guardBits
+
+	m := newFloat(wp)
+	e := x.MantExp(m)
+	if m.Cmp(big.NewFloat(math.Sqrt2/2)) < 0 {
+		m.SetMantExp(m, 1)
+		e--
+	}
+	num := newFloat(wp).Sub(m, bigInt(1, wp))
+	den := newFloat(wp).Add(m, bigInt(1, wp))
+	z := num.Quo(num, den)
+
+	sum := atanhSeries(z, wp)
+	sum.Mul(sum, bigInt(2, wp))
+	sum.Add(sum, newFloat(wp+64).Mul(bigInt(int64(e), wp+64), bigLn2(wp+64)))
+	return round(sum, prec), nil
+}
+
+// bigSinCos returns sin x and cos x, reducing x modulo 2 pi and summing
+// both Taylor series.
+func bigSinCos(x *big.Float) (*big.Float, *big.Float) {
+	prec := x.Prec()
+	wp := prec + guardBits + uint(max(exponent(x), 0))
+
+	twoPi := newFloat(wp).SetMantExp(bigPi(wp), 1)
+	q := newFloat(wp).Quo(x, twoPi)
+	k := roundToInt(q)
+	r := newFloat(wp).Mul(newFloat(wp).SetInt(k), twoPi)
+	r.Sub(round(x, wp), r)
+
+	one := bigInt(1, wp)
+	sin := round(r, wp)
+	cos := bigInt(1, wp)
+	term := round(r, wp) // r^n / n!, starting at n = 1
+	for n := int64(2); ; n++ {
+		term.Mul(term, r)
+		term.Quo(term, bigInt(n, wp))
+		if n%2 == 0 {
+			if n%4 == 0 {
+				cos.Add(cos, term)
+			} else {
+				cos.Sub(cos, term)
+			}
+		} else {
+			if n%4 == 1 {
+				sin.Add(sin, term)
+			} else {
+				sin.Sub(sin, term)
+			}
+		}
+		if negligible(term, one, wp) {
+			break
+		}
+	}
+	return round(sin, prec), round(cos, prec)
+}
+
+// bigTan returns tan x.
+func bigTan(x *big.Float) (*big.Float, error) {
+	wide := round(x, x.Prec()+guardBits)
+	sin, cos := bigSinCos(wide)
+	if cos.Sign() == 0 {
+		return nil, &DomainError{Func: "tan", Msg: "tangent is undefined at odd multiples of pi/2"}
+	}
+	return round(sin.Quo(sin, cos), x.Prec()), nil
+}
+
+// bigAtan returns atan x. Arguments beyond 1 are reflected with
+// atan x = pi/2 - atan(1/x), and the rest halved with
+// atan x = 2 atan(x / (1 + sqrt(1 + x^2))) until the Taylor series
+// converges quickly.
+func bigAtan(x *big.Float) *big.Float {
+	const halvings = 8
+	prec := x.Prec()
+	if x.Sign() == 0 {
+		return newFloat(prec)
+	}
+	wp := prec + guardBits
+	halfPi := newFloat(wp).SetMantExp(bigPi(wp), -1)
+	if x.IsInf() {
+		if x.Sign() < 0 {
+			halfPi.Neg(halfPi)
+		}
+		return round(halfPi, prec)
+	}
+
+	one := bigInt(1, wp)
+	z := newFloat(wp).Abs(x)
+	reflect := z.Cmp(one) > 0
+	if reflect {
+		z.Quo(one, z)
+	}
+	for i := 0; i < halvings; i++ {
+		d := newFloat(wp).Mul(z, z)
+		d.Add(d, one).Sqrt(d).Add(d, one)
+		z.Quo(z, d)
+	}
+
+	z2 := newFloat(wp).Mul(z, z)
+	sum := round(z, wp)
+	power := round(z, wp)
+	for k := int64(1); ; k++ {
+		power.Mul(power, z2)
+		term := newFloat(wp).Quo(power, bigInt(2*k+1, wp))
+		if k%2 == 1 {
+			sum.Sub(sum, term)
+		} else {
+			sum.Add(sum, term)
+		}
+		if negligible(term, sum, wp) {
+			break
+		}
+	}
+	sum.SetMantExp(sum, halvings)
+	if reflect {
+		sum.Sub(halfPi, sum)
+	}
+	if x.Sign() < 0 {
+		sum.Neg(sum)
+	}
+	return round(sum, prec)
+}
+
+// bigAsin returns asin x for |x| <= 1, as atan(x / sqrt((1 - x)(1 + x))).
+func bigAsin(x *big.Float) (*big.Float, error) {
+	prec := x.Prec()
+	if x.IsInf() || newFloat(prec).Abs(x).Cmp(bigInt(1, prec)) > 0 {
+		return nil, &DomainError{Func: "asin", Msg: "argument outside [-1, 1]"}
+	}
+	wp := prec + guardBits
+	one := bigInt(1, wp)
+	d := newFloat(wp).Sub(one, x)
+	d.Mul(d, newFloat(wp).Add(one, x)).Sqrt(d)
+	if d.Sign() == 0 {
+		halfPi := newFloat(wp).SetMantExp(bigPi(wp), -1)
+		if x.Sign() < 0 {
+			halfPi.Neg(halfPi)
+		}
+		return round(halfPi, prec), nil
+	}
+	return round(bigAtan(d.Quo(x, d)), prec), nil
+}
+
+// bigAcos returns acos x for |x| <= 1, as 2 atan(sqrt((1 - x) / (1 + x))),
+// which keeps its precision near x = 1.
+func bigAcos(x *big.Float) (*big.Float, error) {
+	prec := x.Prec()
+	if x.IsInf() || newFloat(prec).Abs(x).Cmp(bigInt(1, prec)) > 0 {
+		return nil, &DomainError{Func: "acos", Msg: "argument outside [-1, 1]"}
+	}
+	wp := prec + guardBits
+	one := bigInt(1, wp)
+	den := newFloat(wp).Add(one, x)
+	if den.Sign() == 0 {
+		return round(bigPi(prec), prec), nil
+	}
+	t := newFloat(wp).Sub(one, x)
+	t.Quo(t, den).Sqrt(t)
+	a := bigAtan(t)
+	return round(a.SetMantExp(a, 1), prec), nil
+}
+
+// bigAtan2 returns the angle of the point (x, y) from the positive x axis,
+// in [-pi, pi], like math.Atan2.
+func bigAtan2(y, x *big.Float) *big.Float {
+	prec := max(y.Prec(), x.Prec())
+	wp := prec + guardBits
+	if x.Sign() == 0 {
+		if y.Sign() == 0 {
+			return newFloat(prec)
+		}
+		halfPi := newFloat(wp).SetMantExp(bigPi(wp), -1)
+		if y.Sign() < 0 {
+			halfPi.Neg(halfPi)
+		}
+		return round(halfPi, prec)
+	}
+	if x.IsInf() && y.IsInf() {
+		// The ratio is undefined; the angle is that of the diagonal
+		y, x = bigInt(int64(y.Sign()), wp), bigInt(int64(x.Sign()), wp)
+	}
+	a := bigAtan(newFloat(wp).Quo(y, x))
+	if x.Sign() < 0 {
+		if y.Sign() < 0 {
+			a.Sub(a, bigPi(wp))
+		} else {
+			a.Add(a, bigPi(wp))
+		}
+	}
+	return round(a, prec)
+}
+
+// bigSinh returns sinh x = (e^x - e^-x) / 2, computing small arguments
+// with extra precision for the bits lost to cancellation.
+func bigSinh(x *big.Float) *big.Float {
+	prec := x.Prec()
+	lost := max(-exponent(x), 0)
+	if x.Sign() == 0 || x.IsInf() || lost > int(prec) {
+		// sinh x = x (1 + x^2/6 + ...) rounds to x
+		return round(x, prec)
+	}
+	wp := prec + guardBits + uint(lost)
+	e := bigExp(round(x, wp))
+	e.Sub(e, newFloat(wp).Quo(bigInt(1, wp), e))
+	return round(e.SetMantExp(e, -1), prec)
+}
+
+// bigCosh returns cosh x = (e^x + e^-x) / 2.
+func bigCosh(x *big.Float) *big.Float {
+	prec := x.Prec()
+	wp := prec + guardBits
+	e := bigExp(round(x, wp))
+	e.Add(e, newFloat(wp).Quo(bigInt(1, wp), e))
+	return round(e.SetMantExp(e, -1), prec)
+}
+
+// bigTanh returns tanh x = sinh x / cosh x, which is 1 or -1 to the
+// precision of x once e^-2|x| is negligible.
+func bigTanh(x *big.Float) *big.Float {
+	prec := x.Prec()
+	if f, _ := x.Float64(); math.Abs(f) > float64(prec+1)*math.Ln2/2+1 {
+		return bigInt(int64(x.Sign()), prec)
+	}
+	wp := prec + guardBits
+	s := bigSinh(round(x, wp))
+	return round(s.Quo(s, bigCosh(round(x, wp))), prec)
+}
+
+// bigAsinh returns asinh x = log(x + sqrt(x^2 + 1)), computed for |x| and
+// with extra precision for small arguments, whose logarithm is of a number
+// close to 1.
+func bigAsinh(x *big.Float) *big.Float {
+	prec := x.Prec()
+	lost := max(-exponent(x), 0)
+	if x.Sign() == 0 || x.IsInf() || lost > int(prec) {
+		// asinh x = x (1 - x^2/6 + ...) rounds to x
+		return round(x, prec)
+	}
+	wp := prec + guardBits + uint(lost)
+	z := newFloat(wp).Abs(x)
+	t := newFloat(wp).Mul(z, z)
+	t.Add(t, bigInt(1, wp)).Sqrt(t).Add(t, z)
+	l, _ := bigLog(t)
+	if x.Sign() < 0 {
+		l.Neg(l)
+	}
+	return round(l, prec)
+}
+
+// bigAcosh returns acosh x = log(x + sqrt((x - 1)(x + 1))) for x >= 1.
+func bigAcosh(x *big.Float) (*big.Float, error) {
+	prec := x.Prec()
+	if x.Cmp(bigInt(1, prec)) < 0 {
+		return nil, &DomainError{Func: "acosh", Msg: "argument less than 1"}
+	}
+	if x.IsInf() {
+		return round(x, prec), nil
+	}
+	wp := prec + guardBits
+	one := bigInt(1, wp)
+	d := newFloat(wp).Sub(x, one)
+	if d.Sign() == 0 {
+		return newFloat(prec), nil
+	}
+	// The logarithm is of a number close to 1 when x is
+	wp += uint(min(max(-exponent(d), 0), int(prec)))
+	one = bigInt(1, wp)
+	d = newFloat(wp).Sub(x, one)
+	t := newFloat(wp).Add(x, one)
+	t.Mul(t, d).Sqrt(t).Add(t, x)
+	l, _ := bigLog(t)
+	return round(l, prec), nil
+}
+
+// bigAtanh returns atanh x for |x| < 1, and an infinity for |x| = 1. Small
+// arguments are summed from the series, and the rest computed as
+// log((1 + x) / (1 - x)) / 2.
+func bigAtanh(x *big.Float) (*big.Float, error) {
+	prec := x.Prec()
+	switch newFloat(prec).Abs(x).Cmp(bigInt(1, prec)) {
+	case 1:
+		return nil, &DomainError{Func: "atanh", Msg: "argument outside [-1, 1]"}
+	case 0:
+		return newFloat(prec).SetInf(x.Sign() < 0), nil
+	}
+	wp := prec + guardBits
+	if exponent(x) < 0 {
+		// |x| < 1/2
+		return round(atanhSeries(round(x, wp), wp), prec), nil
+	}
+	one := bigInt(1, wp)
+	t := newFloat(wp).Add(one, x)
+	t.Quo(t, newFloat(wp).Sub(one, x))
+	l, _ := bigLog(t)
+	return round(l.SetMantExp(l, -1), prec), nil
+}
+
+// bigPow returns x^y. Integer exponents are computed by repeated squaring;
+// others as e^(y log x), which requires x > 0.
+func bigPow(x, y *big.Float) (*big.Float, error) {
+	prec := max(x.Prec(), y.Prec())
+	if y.IsInt() && !y.IsInf() {
+		if n, acc := y.Int64(); acc == big.Exact {
+			return bigPowInt(x, n, prec)
+		}
+	}
+	switch x.Sign() {
+	case 0:
+		if y.Sign() < 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		return newFloat(prec), nil
+	case -1:
+		return nil, &DomainError{Func: "pow", Msg: "negative number raised to a non-integer power"}
+	}
+
+	// e^(y log x) loses as many bits as the exponent of y log x
+	wp := prec + guardBits
+	l, err := bigLog(round(x, wp))
+	if err != nil {
+		return nil, err
+	}
+	t := newFloat(wp).Mul(l, y)
+	wp += uint(max(exponent(t), 0))
+	l, _ = bigLog(round(x, wp))
+	t = newFloat(wp).Mul(l, y)
+	return round(bigExp(t), prec), nil
+}
+
+// bigPowInt returns x^n by repeated squaring.
+func bigPowInt(x *big.Float, n int64, prec uint) (*big.Float, error) {
+	if n < 0 {
+		if x.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+	}
+	m := n
+	if m < 0 {
+		m = -m
+	}
+	wp := prec + guardBits + uint(bitLen(m))
+	result := bigInt(1, wp)
+	base := round(x, wp)
+	for ; m > 0; m >>= 1 {
+		if m&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+	if n < 0 {
+		result.Quo(bigInt(1, wp), result)
+	}
+	return round(result, prec), nil
+}
+
+// bigFloor returns the largest integer not greater than x.
+func bigFloor(x *big.Float) *big.Float {
+	if x.IsInf() {
+		return x
+	}
+	i, acc := x.Int(nil) // truncates towards zero
+	if acc == big.Above {
+		i.Sub(i, big.NewInt(1))
+	}
+	return newFloat(x.Prec()).SetInt(i)
+}
+
+// bigCeil returns the smallest integer not less than x.
+func bigCeil(x *big.Float) *big.Float {
+	if x.IsInf() {
+		return x
+	}
+	i, acc := x.Int(nil)
+	if acc == big.Below {
+		i.Add(i, big.NewInt(1))
+	}
+	return newFloat(x.Prec()).SetInt(i)
+}
+
+// bigRound returns x rounded to the nearest integer, halves away from zero.
+func bigRound(x *big.Float) *big.Float {
+	if x.IsInf() {
+		return x
+	}
+	return newFloat(x.Prec()).SetInt(roundToInt(x))
+}
+
+// roundToInt returns x rounded to the nearest integer, halves away from
+// zero.
+func roundToInt(x *big.Float) *big.Int {
+	half := newFloat(x.Prec() + 1).SetFloat64(0.5)
+	if x.Sign() < 0 {
+		half.Neg(half)
+	}
+	i, _ := half.Add(half, x).Int(nil)
+	return i
+}
+
+// bigOp applies an arithmetic or ordering operator to two big floats,
+// computing with the larger of their precisions.
+func bigOp(op string, a, b *big.Float) (result interface{}, err error) {
+	// big.Float panics on operations without a result, such as Inf - Inf
+	defer func() {
+		if r := recover(); r != nil {
+			if nan, ok := r.(big.ErrNaN); ok {
+				result, err = nil, &DomainError{Msg: nan.Error()}
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	prec := max(a.Prec(), b.Prec())
+	switch op {
+	case "+":
+		return newFloat(prec).Add(a, b), nil
+	case "-":
+		return newFloat(prec).Sub(a, b), nil
+	case "*":
+		return newFloat(prec).Mul(a, b), nil
+	case "/":
+		if b.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		return newFloat(prec).Quo(a, b), nil
+	case "%":
+		if b.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		// Truncated remainder, matching math.Mod: a - b*trunc(a/b)
+		wp := prec + guardBits + uint(max(exponent(a)-exponent(b), 0))
+		t, _ := newFloat(wp).Quo(a, b).Int(nil)
+		r := newFloat(wp).Mul(newFloat(wp).SetInt(t), b)
+		return round(r.Sub(a, r), prec), nil
+	case "^":
+		val, err := bigPow(a, b)
+		if f, ok := floatFallback(err, func() (interface{}, error) {
+			x, _ := a.Float64()
+			y, _ := b.Float64()
+			return math.Pow(x, y), nil
+		}); ok {
+			return f, nil
+		}
+		return val, err
+	case "<":
+		return a.Cmp(b) < 0, nil
+	case "<=":
+		return a.Cmp(b) <= 0, nil
+	case ">":
+		return a.Cmp(b) > 0, nil
+	case ">=":
+		return a.Cmp(b) >= 0, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// toBigFloat converts a number to a *big.Float with the given precision.
+func toBigFloat(x interface{}, prec uint) (*big.Float, bool) {
+	switch v := x.(type) {
+	case *big.Float:
+		return v, true
+	case *big.Rat:
+		return newFloat(prec).SetRat(v), true
+	case float64:
+		if math.IsNaN(v) {
+			return nil, false
+		}
+		return newFloat(prec).SetFloat64(v), true
+	}
+	return nil, false
+}
+
+// bitLen returns the number of bits needed to represent n > 0.
+func bitLen(n int64) int {
+	return big.NewInt(n).BitLen()
+}
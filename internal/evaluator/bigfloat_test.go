@@ -0,0 +1,163 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// highPrecision holds reference values to 105 significant digits.
+var highPrecision = []struct {
+	input    string
+	expected string
+}{
+	{"sqrt(2)", "1.41421356237309504880168872420969807856967187537694807317667973799073247846210703885038753432764157273501"},
+	{"exp(1)", "2.71828182845904523536028747135266249775724709369995957496696762772407663035354759457138217852516642742747"},
+	{"log(2)", "0.693147180559945309417232121458176568075500134360255254120680009493393621969694715605863326996418687542001"},
+	{"log10(2)", "0.301029995663981195213738894724493026768189881462108541310427461127108189274424509486927252118186172040684"},
+	{"sin(1)", "0.841470984807896506652502321630298999622563060798371065672751709991910404391239668948639743543052695854349"},
+	{"cos(1)", "0.540302305868139717400936607442976603732310420617922227670097255381100394774471764517951856087183089343572"},
+	{"tan(1)", "1.55740772465490223050697480745836017308725077238152003838394660569886139715172728955509996520224298380463"},
+	{"2^(1/3)", "1.25992104989487316476721060727822835057025146470150798008197511215529967651395948372939656243625509415431"},
+	{"pow(2, 1/3)", "1.25992104989487316476721060727822835057025146470150798008197511215529967651395948372939656243625509415431"},
+	{"sin(100)", "-0.506365641109758793656557610459785432065032721290657323443392473594357913419476696499236664512927392207244"},
+	{"exp(-10)", "0.0000453999297624848515355915155605506102379180888665649692590713056509994216143022816525250045459477823217081"},
+	{"asin(0.5)", "0.523598775598298873077107230546583814032861566562517636829157432051302734381034833104672470890352844663691"},
+	{"acos(0.5)", "1.04719755119659774615421446109316762806572313312503527365831486410260546876206966620934494178070568932738"},
+	{"atan(1)", "0.785398163397448309615660845819875721049292349843776455243736148076954101571552249657008706335529266995537"},
+	{"atan2(-1, -1)", "-2.35619449019234492884698253745962716314787704953132936573120844423086230471465674897102611900658780098661"},
+	{"sinh(1)", "1.17520119364380145688238185059560081515571798133409587022956541301330756730432389560711745208962339184042"},
+	{"cosh(1)", "1.54308063481524377847790562075706168260152911236586370473740221471076906304922369896426472643554303558705"},
+	{"tanh(1)", "0.761594155955764888119458282604793590412768597257936551596810500121953244576638483458947521673676714421903"},
+	{"asinh(1)", "0.881373587019543025232609324979792309028160328261635410753295608653377184222026087833706891910256042856740"},
+	{"acosh(2)", "1.31695789692481670862504634730796844402698197146751647976847225692046018541644397607421901345010178355647"},
+	{"atanh(0.5)", "0.549306144334054845697622618461262852323745278911374725867347166818747146609304483436807877406866044393985"},
+}
+
+// TestPrecisionMode tests the built-in functions against high-precision
+// reference values
+func TestPrecisionMode(t *testing.T) {
+	for _, digits := range []int{50, 100} {
+		for _, tt := range highPrecision {
+			t.Run(fmt.Sprintf("%s to %d digits", tt.input, digits), func(t *testing.T) {
+				result, err := New(Options{Precision: digits}).Evaluate(tt.input)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				x, ok := result.(*big.Float)
+				if !ok {
+					t.Fatalf("Expected *big.Float, got %T", result)
+				}
+				checkDigits(t, x, tt.expected, digits)
+			})
+		}
+	}
+}
+
+// TestPrecisionArithmetic tests operators and exact-valued functions in
+// precision mode
+func TestPrecisionArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"Decimal literals", "0.1 + 0.2", "0.30000000000000000000000000000000000000000000000000", false},
+		{"Division", "1 / 3", "0.33333333333333333333333333333333333333333333333333", false},
+		{"Large power", "2 ^ 100", "1267650600228229401496703205376", false},
+		{"Large factorial", "fact(30)", "265252859812191058636308480000000", false},
+		{"Absolute value", "abs(-2.5)", "2.5", false},
+		{"Floor", "floor(-2.5)", "-3", false},
+		{"Round half away from zero", "round(2.5)", "3", false},
+		{"Minimum", "min(1/3, 1/4)", "0.25", false},
+		{"Comparison", "1 / 3 * 3 == 1", "true", false},
+		{"Square root of negative number", "sqrt(-2)", "", true},
+		{"Logarithm of zero", "log(0)", "-Inf", false},
+		{"Division by zero", "1 / (2 - 2)", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{Precision: 50}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestPrecisionDomainErrors tests that precision mode reports domain errors
+// with their function name, and returns NaN or an infinity where float64
+// sessions do, following the same strict or lenient policy
+func TestPrecisionDomainErrors(t *testing.T) {
+	for _, input := range []string{"sqrt(-1)", "asin(2)", "acosh(0)", "atanh(-3)"} {
+		_, err := New(Options{Precision: 30}).Evaluate(input)
+		var domainErr *DomainError
+		if !errors.As(err, &domainErr) {
+			t.Errorf("%s: expected a DomainError, got %v", input, err)
+		}
+	}
+
+	for _, input := range []string{"log(-1)", "log(0)", "(-8) ^ 0.5", "atanh(1)"} {
+		for _, strict := range []bool{false, true} {
+			float, floatErr := New(Options{Strict: strict}).Exec(input)
+			precise, err := New(Options{Precision: 30, Strict: strict}).Exec(input)
+			var nonFinite *NonFiniteError
+			if strict {
+				if !errors.As(err, &nonFinite) || floatErr == nil || err.Error() != floatErr.Error() {
+					t.Errorf("%s: expected the strict error %v, got %v", input, floatErr, err)
+				}
+				continue
+			}
+			if err != nil || floatErr != nil {
+				t.Fatalf("%s: unexpected errors %v and %v", input, floatErr, err)
+			}
+			if got, want := FormatValue(precise[0].Value), FormatValue(float[0].Value); got != want || len(precise[0].Warnings) != 1 {
+				t.Errorf("%s: expected %s with a warning, got %s with %v", input, want, got, precise[0].Warnings)
+			}
+		}
+	}
+}
+
+// TestConstantCache tests that constants are cached once, at the highest
+// precision used, and rounded correctly to lower precisions
+func TestConstantCache(t *testing.T) {
+	const pi = "3.14159265358979323846264338327950288419716939937510582097494459230781640628620899862803482534211706798214808651"
+	for _, prec := range []uint{300, 64, 200, 17, 150} {
+		want, _ := newFloat(prec).SetString(pi)
+		if got := bigPi(prec); got.Cmp(want) != 0 || got.Prec() != prec {
+			t.Errorf("pi at %d bits: expected %s, got %s", prec, want.Text('g', 40), got.Text('g', 40))
+		}
+	}
+	constantCache.Lock()
+	defer constantCache.Unlock()
+	if got := constantCache.values["pi"].Prec(); got < 300+guardBits {
+		t.Errorf("Expected pi to be cached at the highest precision used, got %d bits", got)
+	}
+}
+
+// checkDigits fails unless x agrees with expected to a relative error below
+// 10^-digits.
+func checkDigits(t *testing.T, x *big.Float, expected string, digits int) {
+	t.Helper()
+	prec := bitsForDigits(digits) + guardBits
+	want, _ := newFloat(prec).SetString(expected)
+	diff := newFloat(prec).Sub(x, want)
+	diff.Quo(diff, want).Abs(diff)
+	tolerance, _ := newFloat(prec).SetString(fmt.Sprintf("1e-%d", digits))
+	if diff.Cmp(tolerance) >= 0 {
+		t.Errorf("Expected %s, got %s", expected, x.Text('g', digits))
+	}
+}
@@ -0,0 +1,332 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// builtins is the registry of functions available in every session.
+var builtins = newBuiltins()
+
+// newBuiltins builds the registry of built-in functions.
+func newBuiltins() *Registry {
+	r := NewRegistry()
+	mustRegister(r, "sqrt", 1, sqrtFunc.function(), "Square root of x.")
+	mustRegister(r, "sin", 1, unary{
+		name: "sin",
+		big: func(x *big.Float) (interface{}, error) {
+			sin, _ := bigSinCos(x)
+			return sin, nil
+		},
+		float:   total(math.Sin),
+		complex: complexTotal(cmplx.Sin),
+	}.function(), "Sine of the angle x.")
+	mustRegister(r, "cos", 1, unary{
+		name: "cos",
+		big: func(x *big.Float) (interface{}, error) {
+			_, cos := bigSinCos(x)
+			return cos, nil
+		},
+		float:   total(math.Cos),
+		complex: complexTotal(cmplx.Cos),
+	}.function(), "Cosine of the angle x.")
+	mustRegister(r, "tan", 1, unary{
+		name: "tan",
+		big: func(x *big.Float) (interface{}, error) {
+			return bigTan(x)
+		},
+		float:   total(math.Tan),
+		complex: complexTotal(cmplx.Tan),
+	}.function(), "Tangent of the angle x.")
+	mustRegister(r, "log", 1, unary{
+		name: "log",
+		big: func(x *big.Float) (interface{}, error) {
+			return bigLog(x)
+		},
+		float:   total(math.Log),
+		complex: complexTotal(cmplx.Log),
+	}.function(), "Natural logarithm of x.")
+	mustRegister(r, "log10", 1, unary{
+		name: "log10",
+		big: func(x *big.Float) (interface{}, error) {
+			wp := x.Prec() + guardBits
+			l, err := bigLog(round(x, wp))
+			if err != nil {
+				return nil, err
+			}
+			ln10, _ := bigLog(bigInt(10, wp))
+			return round(l.Quo(l, ln10), x.Prec()), nil
+		},
+		float:   total(math.Log10),
+		complex: complexTotal(cmplx.Log10),
+	}.function(), "Base-10 logarithm of x.")
+	mustRegister(r, "exp", 1, unary{
+		name: "exp",
+		big: func(x *big.Float) (interface{}, error) {
+			return bigExp(x), nil
+		},
+		float:   total(math.Exp),
+		complex: complexTotal(cmplx.Exp),
+	}.function(), "e raised to the power x.")
+	mustRegister(r, "pow", 2, func(args ...interface{}) (interface{}, error) {
+		if err := numericArgs("pow", args); err != nil {
+			return nil, err
+		}
+		return binaryOp("^", args[0], args[1])
+	}, "x raised to the power y.")
+	mustRegister(r, "abs", 1, unary{
+		name:  "abs",
+		exact: exactTotal(func(x *big.Rat) *big.Rat { return new(big.Rat).Abs(x) }),
+		big:   bigTotal(func(x *big.Float) *big.Float { return newFloat(x.Prec()).Abs(x) }),
+		float: total(math.Abs),
+		complex: func(z complex128) (interface{}, error) {
+			return cmplx.Abs(z), nil
+		},
+	}.function(), "Absolute value of x, or the modulus of a complex number.")
+	mustRegister(r, "ceil", 1, unary{
+		name:  "ceil",
+		exact: exactTotal(ratCeil),
+		big:   bigTotal(bigCeil),
+		float: total(math.Ceil),
+	}.function(), "Smallest integer not less than x.")
+	mustRegister(r, "floor", 1, unary{
+		name:  "floor",
+		exact: exactTotal(ratFloor),
+		big:   bigTotal(bigFloor),
+		float: total(math.Floor),
+	}.function(), "Largest integer not greater than x.")
+	mustRegister(r, "round", 1, unary{
+		name:  "round",
+		exact: exactTotal(ratRound),
+		big:   bigTotal(bigRound),
+		float: total(math.Round),
+	}.function(), "x rounded to the nearest integer, halves away from zero.")
+	mustRegister(r, "re", 1, unary{
+		name:  "re",
+		exact: exactTotal(func(x *big.Rat) *big.Rat { return x }),
+		big:   bigTotal(func(x *big.Float) *big.Float { return x }),
+		float: total(func(x float64) float64 { return x }),
+		complex: func(z complex128) (interface{}, error) {
+			return real(z), nil
+		},
+	}.function(), "Real part of z.")
+	mustRegister(r, "im", 1, unary{
+		name:  "im",
+		exact: exactTotal(func(x *big.Rat) *big.Rat { return new(big.Rat) }),
+		big:   bigTotal(func(x *big.Float) *big.Float { return newFloat(x.Prec()) }),
+		float: total(func(x float64) float64 { return 0 }),
+		complex: func(z complex128) (interface{}, error) {
+			return imag(z), nil
+		},
+	}.function(), "Imaginary part of z.")
+	mustRegister(r, "conj", 1, unary{
+		name:    "conj",
+		exact:   exactTotal(func(x *big.Rat) *big.Rat { return x }),
+		big:     bigTotal(func(x *big.Float) *big.Float { return x }),
+		float:   total(func(x float64) float64 { return x }),
+		complex: complexTotal(cmplx.Conj),
+	}.function(), "Complex conjugate of z.")
+	mustRegister(r, "arg", 1, unary{
+		name: "arg",
+		big: func(x *big.Float) (interface{}, error) {
+			if x.Sign() < 0 {
+				return round(bigPi(x.Prec()), x.Prec()), nil
+			}
+			return newFloat(x.Prec()), nil
+		},
+		float: func(x float64) (interface{}, error) {
+			if x < 0 {
+				return math.Pi, nil
+			}
+			return 0.0, nil
+		},
+		complex: func(z complex128) (interface{}, error) {
+			return cmplx.Phase(z), nil
+		},
+	}.function(), "Argument (phase angle) of z, in the range (-pi, pi] in radians.")
+	registerTrig(r)
+	registerSpecial(r)
+	registerStats(r)
+	registerNumberTheory(r)
+	return r
+}
+
+// sqrtFunc is the square root, shared by sqrt and the statistical functions.
+var sqrtFunc = unary{
+	name: "sqrt",
+	exact: func(x *big.Rat) (interface{}, error) {
+		if x.Sign() < 0 {
+			return nil, &DomainError{Func: "sqrt", Msg: "square root of negative number"}
+		}
+		if root, ok := ratSqrt(x); ok {
+			return root, nil
+		}
+		f, _ := x.Float64()
+		return math.Sqrt(f), nil
+	},
+	big: func(x *big.Float) (interface{}, error) {
+		return bigSqrt(x)
+	},
+	float: func(x float64) (interface{}, error) {
+		if x < 0 {
+			return nil, &DomainError{Func: "sqrt", Msg: "square root of negative number"}
+		}
+		return math.Sqrt(x), nil
+	},
+	complex: complexTotal(cmplx.Sqrt),
+}
+
+// mustRegister registers a built-in function, panicking on an invalid
+// definition.
+func mustRegister(r *Registry, name string, arity int, fn Function, doc string) {
+	if err := r.Register(name, arity, fn, doc); err != nil {
+		panic(err)
+	}
+}
+
+// floatArg returns a numeric argument as a float64.
+func floatArg(name string, arg interface{}) (float64, error) {
+	x, ok := toFloat(arg)
+	if !ok {
+		return 0, &TypeError{Msg: fmt.Sprintf("%s expects a numeric argument", name)}
+	}
+	return x, nil
+}
+
+// floatArgs returns two numeric arguments as float64s.
+func floatArgs(name string, args []interface{}) (float64, float64, error) {
+	x, ok1 := toFloat(args[0])
+	y, ok2 := toFloat(args[1])
+	if !ok1 || !ok2 {
+		return 0, 0, &TypeError{Msg: fmt.Sprintf("%s expects numeric arguments", name)}
+	}
+	return x, y, nil
+}
+
+// unary holds the implementations of a function of one number for each
+// numeric representation. Rationals without an exact implementation and
+// big floats without a big implementation are converted to float64;
+// functions without a complex implementation reject complex arguments.
+type unary struct {
+	name    string
+	exact   func(x *big.Rat) (interface{}, error)
+	big     func(x *big.Float) (interface{}, error)
+	float   func(x float64) (interface{}, error)
+	complex func(z complex128) (interface{}, error)
+}
+
+// function returns the Function dispatching to u's implementations.
+func (u unary) function() Function {
+	return func(args ...interface{}) (interface{}, error) {
+		switch x := args[0].(type) {
+		case *big.Rat:
+			if u.exact != nil {
+				return u.exact(x)
+			}
+		case *big.Float:
+			if u.big != nil {
+				val, err := u.big(x)
+				if f, ok := floatFallback(err, func() (interface{}, error) {
+					f, _ := x.Float64()
+					return u.float(f)
+				}); ok {
+					return f, nil
+				}
+				return val, err
+			}
+		case complex128:
+			if u.complex == nil {
+				return nil, &TypeError{Msg: fmt.Sprintf("%s expects a real argument", u.name)}
+			}
+			return u.complex(x)
+		}
+		x, err := floatArg(u.name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return u.float(x)
+	}
+}
+
+// floatFallback returns the result of float, the float64 version of a
+// big float operation that failed with err, when err is a DomainError and
+// float returns NaN or an infinity instead of failing. The result is then
+// kept or rejected by Options.Strict, as in float64 sessions.
+func floatFallback(err error, float func() (interface{}, error)) (interface{}, bool) {
+	var domainErr *DomainError
+	if !errors.As(err, &domainErr) {
+		return nil, false
+	}
+	val, err := float()
+	if err != nil || nonFiniteValue(val) == "" {
+		return nil, false
+	}
+	return val, true
+}
+
+// total adapts a float function defined everywhere, such as math.Sin.
+func total(f func(x float64) float64) func(x float64) (interface{}, error) {
+	return func(x float64) (interface{}, error) {
+		return f(x), nil
+	}
+}
+
+// complexTotal adapts a complex function defined everywhere, such as
+// cmplx.Sin, reducing results with no imaginary part to real numbers.
+func complexTotal(f func(z complex128) complex128) func(z complex128) (interface{}, error) {
+	return func(z complex128) (interface{}, error) {
+		return fromComplex(f(z)), nil
+	}
+}
+
+// exactTotal adapts a rational function defined everywhere. f may modify
+// its argument, which is a copy.
+func exactTotal(f func(x *big.Rat) *big.Rat) func(x *big.Rat) (interface{}, error) {
+	return func(x *big.Rat) (interface{}, error) {
+		return f(new(big.Rat).Set(x)), nil
+	}
+}
+
+// bigTotal adapts a big float function defined everywhere.
+func bigTotal(f func(x *big.Float) *big.Float) func(x *big.Float) (interface{}, error) {
+	return func(x *big.Float) (interface{}, error) {
+		return f(x), nil
+	}
+}
+
+// ratSqrt returns the exact square root of x when its numerator and
+// denominator are both perfect squares.
+func ratSqrt(x *big.Rat) (*big.Rat, bool) {
+	num := new(big.Int).Sqrt(x.Num())
+	den := new(big.Int).Sqrt(x.Denom())
+	if new(big.Int).Mul(num, num).Cmp(x.Num()) != 0 || new(big.Int).Mul(den, den).Cmp(x.Denom()) != 0 {
+		return nil, false
+	}
+	return new(big.Rat).SetFrac(num, den), true
+}
+
+// ratFloor sets x to the largest integer not greater than x.
+func ratFloor(x *big.Rat) *big.Rat {
+	// Div rounds towards negative infinity for a positive divisor
+	return x.SetInt(new(big.Int).Div(x.Num(), x.Denom()))
+}
+
+// ratCeil sets x to the smallest integer not less than x.
+func ratCeil(x *big.Rat) *big.Rat {
+	if x.IsInt() {
+		return x
+	}
+	return ratFloor(x).Add(x, big.NewRat(1, 1))
+}
+
+// ratRound sets x to the nearest integer, rounding halves away from zero.
+func ratRound(x *big.Rat) *big.Rat {
+	half := big.NewRat(1, 2)
+	if x.Sign() < 0 {
+		return ratCeil(x.Sub(x, half))
+	}
+	return ratFloor(x.Add(x, half))
+}
@@ -0,0 +1,175 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// Complex numbers are complex128 whatever the session's mode, so an exact
+// or arbitrary-precision operand mixed with a complex one is converted to
+// float64. A complex result whose imaginary part is zero is reduced to a
+// real float64, so i*i is -1.
+
+// isComplex reports whether any of values is a complex number.
+func isComplex(values ...interface{}) bool {
+	for _, v := range values {
+		if _, ok := v.(complex128); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// toComplex converts a number to complex128.
+func toComplex(x interface{}) (complex128, bool) {
+	if z, ok := x.(complex128); ok {
+		return z, true
+	}
+	f, ok := toFloat(x)
+	return complex(f, 0), ok
+}
+
+// fromComplex returns z, or its real part if its imaginary part is zero.
+func fromComplex(z complex128) interface{} {
+	if imag(z) == 0 {
+		return real(z)
+	}
+	return z
+}
+
+// complexOp applies an arithmetic operator to two complex numbers. Complex
+// numbers are unordered, so they have no remainder or ordering operators.
+func complexOp(op string, a, b complex128) (interface{}, error) {
+	switch op {
+	case "+":
+		return fromComplex(a + b), nil
+	case "-":
+		return fromComplex(a - b), nil
+	case "*":
+		return fromComplex(a * b), nil
+	case "/":
+		if b == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		return fromComplex(a / b), nil
+	case "^":
+		if n := real(b); imag(b) == 0 && n == math.Trunc(n) && math.Abs(n) <= 1<<20 {
+			return complexPowInt(a, int64(n))
+		}
+		return fromComplex(cmplx.Pow(a, b)), nil
+	case "%", "<", "<=", ">", ">=":
+		return nil, &TypeError{Msg: fmt.Sprintf("operator %s is not defined for complex numbers", op)}
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// complexPowInt raises a complex number to an integer power by repeated
+// squaring, which keeps results such as i^2 free of rounding residue.
+func complexPowInt(z complex128, n int64) (interface{}, error) {
+	if n < 0 {
+		if z == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		z = 1 / z
+		n = -n
+	}
+	result := complex(1, 0)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result *= z
+		}
+		z *= z
+	}
+	return fromComplex(result), nil
+}
+
+// complexPow is x^y in sessions with Options.Complex: a negative real base
+// with a non-integer exponent gives the principal complex power instead of
+// an error or NaN.
+func complexPow(x, y interface{}) (interface{}, error) {
+	if !isComplex(x, y) {
+		a, ok1 := toFloat(x)
+		b, ok2 := toFloat(y)
+		if ok1 && ok2 && a < 0 && b != math.Trunc(b) {
+			return complexOp("^", complex(a, 0), complex(b, 0))
+		}
+	}
+	return binaryOp("^", x, y)
+}
+
+// complexOverrides replace built-in functions in sessions with
+// Options.Complex. Each is called with the real arguments outside the
+// domain of the built-in, for which it has no real result.
+var complexOverrides = map[string]struct {
+	f       func(z complex128) complex128
+	outside func(x interface{}) bool
+}{
+	"sqrt":  {cmplx.Sqrt, isNegative},
+	"log":   {cmplx.Log, isNegative},
+	"log10": {cmplx.Log10, isNegative},
+	"asin":  {cmplx.Asin, outsideUnit},
+	"acos":  {cmplx.Acos, outsideUnit},
+	"acosh": {cmplx.Acosh, func(x interface{}) bool { return less(x, intLike(1, x)) }},
+	"atanh": {cmplx.Atanh, outsideUnit},
+}
+
+// promoteComplex replaces the built-in functions in r that have complex
+// results outside their real domain, and pow, with versions returning
+// those results. Functions registered in place of a built-in are left
+// alone. It returns the names of the functions it replaced.
+func promoteComplex(r *Registry) map[string]bool {
+	promoted := make(map[string]bool)
+	for name, o := range complexOverrides {
+		en, ok := r.Lookup(name)
+		if builtin, _ := builtins.Lookup(name); !ok || en != builtin {
+			continue
+		}
+		fn, f, outside := en.Fn, o.f, o.outside
+		mustRegister(r, name, 1, func(args ...interface{}) (interface{}, error) {
+			if !outside(args[0]) {
+				return fn(args...)
+			}
+			z, _ := toComplex(args[0])
+			return fromComplex(f(z)), nil
+		}, en.Doc)
+		promoted[name] = true
+	}
+	if en, ok := r.Lookup("pow"); ok {
+		if builtin, _ := builtins.Lookup("pow"); en == builtin {
+			mustRegister(r, "pow", 2, func(args ...interface{}) (interface{}, error) {
+				if err := numericArgs("pow", args); err != nil {
+					return nil, err
+				}
+				return complexPow(args[0], args[1])
+			}, en.Doc)
+			promoted["pow"] = true
+		}
+	}
+	return promoted
+}
+
+// isNegative reports whether x is a real number less than zero.
+func isNegative(x interface{}) bool {
+	switch v := x.(type) {
+	case float64:
+		return v < 0
+	case *big.Rat:
+		return v.Sign() < 0
+	case *big.Float:
+		return v.Sign() < 0
+	}
+	return false
+}
+
+// outsideUnit reports whether x is a real number outside [-1, 1].
+func outsideUnit(x interface{}) bool {
+	return less(x, intLike(-1, x)) || less(intLike(1, x), x)
+}
+
+// less reports whether the real number x is less than y.
+func less(x, y interface{}) bool {
+	lt, err := binaryOp("<", x, y)
+	return err == nil && lt == true
+}
@@ -0,0 +1,149 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestComplexNumbers tests imaginary literals, complex arithmetic and the
+// complex built-in functions
+func TestComplexNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"Imaginary literal", "2i", "2i", false},
+		{"j suffix", "1.5e1j", "15i", false},
+		{"Imaginary unit", "3 + i", "3+i", false},
+		{"j unit", "-j", "-i", false},
+		{"Unit squared", "i * i", "-1", false},
+		{"Integer power", "i ^ 2", "-1", false},
+		{"Negative power", "(1 + i) ^ -1", "0.5-0.5i", false},
+		{"Multiplication", "(1 + 2i) * (3 - i)", "5+5i", false},
+		{"Division", "1 / (1 + i)", "0.5-0.5i", false},
+		{"Equality", "2i == 2j", "true", false},
+		{"Assigned i is a variable", "i = 3; i + 1", "4", false},
+		{"Real part", "re(2 + 3j)", "2", false},
+		{"Imaginary part", "im(2 + 3j)", "3", false},
+		{"Imaginary part of a real", "im(5)", "0", false},
+		{"Conjugate", "conj(2 + 3i)", "2-3i", false},
+		{"Argument", "arg(i)", "1.5707963267948966", false},
+		{"Argument of a negative real", "arg(-1)", "3.141592653589793", false},
+		{"Modulus", "abs(3 + 4i)", "5", false},
+		{"Complex square root", "sqrt(2i)", "1+i", false},
+		{"Complex exponential", "exp(0i)", "1", false},
+		{"Square root of negative number", "sqrt(-4)", "", true},
+		{"Ordering", "i < 1", "", true},
+		{"Remainder", "i % 2", "", true},
+		{"Real-only function", "floor(1 + i)", "", true},
+		{"Division by zero", "i / 0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestComplexMode tests that Options.Complex gives complex results where
+// real functions have none
+func TestComplexMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		input    string
+		expected string
+	}{
+		{"Square root", Options{Complex: true}, "sqrt(-4)", "2i"},
+		{"Real square root", Options{Complex: true}, "sqrt(4)", "2"},
+		{"Product of roots", Options{Complex: true}, "sqrt(-4) * sqrt(-4)", "-4"},
+		{"Logarithm", Options{Complex: true}, "log(-1)", "3.141592653589793i"},
+		{"Inverse sine", Options{Complex: true}, "asin(2)", "1.5707963267948966+1.3169578969248164i"},
+		{"Real inverse sine", Options{Complex: true}, "asin(1)", "1.5707963267948966"},
+		{"Inverse hyperbolic cosine", Options{Complex: true}, "acosh(0)", "1.5707963267948966i"},
+		{"Fractional power", Options{Complex: true}, "im((-4) ^ 0.5)", "2"},
+		{"Power function", Options{Complex: true}, "pow(-1, 0.5) == (-1) ^ 0.5", "true"},
+		{"Integer power stays real", Options{Complex: true}, "(-2) ^ 3", "-8"},
+		{"Exact session", Options{Complex: true, Exact: true}, "sqrt(-9/4) + 1/2", "0.5+1.5i"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(tt.options).Evaluate(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// Without the option the square root of a negative number is an error
+	_, err := New(Options{}).Evaluate("sqrt(-4)")
+	var domainErr *DomainError
+	if !errors.As(err, &domainErr) {
+		t.Errorf("Expected a DomainError, got %v", err)
+	}
+
+	// Functions registered in place of a built-in are not replaced
+	registry := Builtins()
+	if err := registry.Register("sqrt", 1, func(args ...interface{}) (interface{}, error) {
+		return 0.0, nil
+	}, "Always zero."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := New(Options{Registry: registry, Complex: true}).Evaluate("sqrt(-4)")
+	if err != nil || result != 0.0 {
+		t.Errorf("Expected 0, got %v (%v)", result, err)
+	}
+}
+
+// TestImaginaryLiteralLexing tests where an i or j suffix ends a number
+func TestImaginaryLiteralLexing(t *testing.T) {
+	tests := []struct {
+		input     string
+		imaginary bool
+		text      string
+	}{
+		{"2i", true, "2"},
+		{"2.5e-3j", true, "2.5e-3"},
+		{"2", false, "2"},
+	}
+	for _, tt := range tests {
+		node, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.input, err)
+		}
+		lit, ok := node.Statements[0].(*NumberLit)
+		if !ok || lit.Imaginary != tt.imaginary || lit.Text != tt.text {
+			t.Errorf("%s: expected imaginary=%v text %q, got %#v", tt.input, tt.imaginary, tt.text, node.Statements[0])
+		}
+	}
+
+	// 2in is a number followed by the unit in, not an imaginary literal
+	node, err := Parse("2in")
+	if err != nil {
+		t.Fatalf("2in: unexpected error: %v", err)
+	}
+	quantity, ok := node.Statements[0].(*QuantityExpr)
+	if !ok || quantity.X.(*NumberLit).Imaginary || quantity.Unit.Unit.String() != "in" {
+		t.Errorf("2in: expected 2 inches, got %#v", node.Statements[0])
+	}
+}
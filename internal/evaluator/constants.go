@@ -0,0 +1,117 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// Constant is a named, read-only value available in every session. Physical
+// constants carry their units; the mathematical ones are plain numbers.
+type Constant struct {
+	Name  string
+	Value float64
+	// Unit is the unit of Value as written, or empty for a plain number.
+	Unit string
+	Doc  string
+
+	// big computes the constant to a given precision in bits for sessions
+	// with Options.Precision set, and unit is Unit parsed.
+	big  func(prec uint) *big.Float
+	unit units.Unit
+}
+
+// constants lists the built-in constants. Physical constants have their
+// CODATA 2018 values, which are exact for those defining the SI.
+var constants = []Constant{
+	{Name: "pi", Value: math.Pi, Doc: "Ratio of a circle's circumference to its diameter.", big: bigPi},
+	{Name: "tau", Value: 2 * math.Pi, Doc: "Ratio of a circle's circumference to its radius, 2 pi.", big: bigTau},
+	{Name: "e", Value: math.E, Doc: "Base of the natural logarithm.", big: bigE},
+	{Name: "phi", Value: math.Phi, Doc: "Golden ratio, (1 + sqrt(5))/2.", big: bigPhi},
+
+	{Name: "c", Value: 299792458, Unit: "m/s", Doc: "Speed of light in vacuum."},
+	{Name: "h", Value: 6.62607015e-34, Unit: "J s", Doc: "Planck constant."},
+	{Name: "hbar", Value: 6.62607015e-34 / (2 * math.Pi), Unit: "J s", Doc: "Reduced Planck constant, h/(2 pi)."},
+	{Name: "G", Value: 6.67430e-11, Unit: "m^3/kg/s^2", Doc: "Newtonian constant of gravitation."},
+	{Name: "g_0", Value: 9.80665, Unit: "m/s^2", Doc: "Standard acceleration of gravity."},
+	{Name: "k_B", Value: 1.380649e-23, Unit: "J/K", Doc: "Boltzmann constant."},
+	{Name: "N_A", Value: 6.02214076e23, Unit: "mol^-1", Doc: "Avogadro constant."},
+	{Name: "q_e", Value: 1.602176634e-19, Unit: "C", Doc: "Elementary charge."},
+	{Name: "m_e", Value: 9.1093837015e-31, Unit: "kg", Doc: "Electron mass."},
+	{Name: "m_p", Value: 1.67262192369e-27, Unit: "kg", Doc: "Proton mass."},
+	{Name: "eps_0", Value: 8.8541878128e-12, Unit: "C/V/m", Doc: "Vacuum electric permittivity."},
+	{Name: "mu_0", Value: 1.25663706212e-6, Unit: "N/A^2", Doc: "Vacuum magnetic permeability."},
+	{Name: "sigma_SB", Value: 5.670374419e-8, Unit: "W/m^2/K^4", Doc: "Stefan-Boltzmann constant."},
+}
+
+// constantsByName indexes constants by name.
+var constantsByName = make(map[string]*Constant, len(constants))
+
+func init() {
+	for i := range constants {
+		c := &constants[i]
+		c.unit = units.One
+		if c.Unit != "" {
+			u, err := units.Parse(c.Unit)
+			if err != nil {
+				panic(err)
+			}
+			c.unit = u
+		}
+		constantsByName[c.Name] = c
+	}
+}
+
+// Constants returns the built-in constants in a fixed order.
+func Constants() []Constant {
+	return append([]Constant(nil), constants...)
+}
+
+// IsConstant reports whether name is a built-in constant, which cannot be
+// assigned.
+func IsConstant(name string) bool {
+	_, ok := constantsByName[name]
+	return ok
+}
+
+// constant returns the value of the named constant in the session's
+// representation: a quantity if it has a unit, a *big.Float at the
+// session's precision if one is set, and a float64 otherwise. Exact
+// sessions get float64 values, the constants being irrational or measured.
+func (e *Evaluator) constant(name string) (interface{}, bool) {
+	c, ok := constantsByName[name]
+	if !ok {
+		return nil, false
+	}
+	if !c.unit.Dimensionless() {
+		return units.New(c.Value, c.unit), true
+	}
+	if c.big != nil && !e.options.Exact && e.options.Precision > 0 {
+		prec := bitsForDigits(e.options.Precision)
+		return round(c.big(prec), prec), true
+	}
+	return c.Value, true
+}
+
+// bigTau returns 2 pi to prec bits.
+func bigTau(prec uint) *big.Float {
+	pi := bigPi(prec)
+	return newFloat(prec).SetMantExp(pi, 1)
+}
+
+// bigE returns e to prec bits.
+func bigE(prec uint) *big.Float {
+	return cachedConstant("e", prec, func(prec uint) *big.Float {
+		return bigExp(bigInt(1, prec))
+	})
+}
+
+// bigPhi returns the golden ratio to prec bits.
+func bigPhi(prec uint) *big.Float {
+	return cachedConstant("phi", prec, func(prec uint) *big.Float {
+		s := newFloat(prec).Sqrt(bigInt(5, prec))
+		s.Add(s, bigInt(1, prec))
+		return s.SetMantExp(s, -1)
+	})
+}
@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestConstants tests the built-in constants and their protection
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"pi", "pi", "3.141592653589793", false},
+		{"tau", "tau / pi", "2", false},
+		{"e", "log(e)", "1", false},
+		{"phi", "phi^2 - phi", "1", false},
+		{"Speed of light", "c", "299792458 m/s", false},
+		{"Units combine", "to(h * c / (500 nm), eV)", "2.479683968664005 eV", false},
+		{"Weight", "2 kg * g_0", "19.6133 N", false},
+		{"Parameters shadow constants", "f(c) = 2 * c; f(3)", "6", false},
+		{"Assignment is rejected", "pi = 3", "", true},
+		{"Assignment of a physical constant", "c = 1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	var constErr *ConstantError
+	_, err := Evaluate("x = 1; e = 2")
+	if !errors.As(err, &constErr) || constErr.Name != "e" || constErr.Statement != 1 || constErr.Column != 8 {
+		t.Errorf("Expected a constant error for e at statement 1, column 8, got %v", err)
+	}
+}
+
+// TestConstantPrecision tests constants computed at a session's precision
+func TestConstantPrecision(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"pi", "3.1415926535897932384626433832795"},
+		{"e", "2.7182818284590452353602874713527"},
+		{"phi", "1.6180339887498948482045868343656"},
+		{"tau", "6.2831853071795864769252867665590"},
+	}
+
+	for _, tt := range tests {
+		result, err := New(Options{Precision: 32}).Evaluate(tt.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got := FormatValue(result); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, got)
+		}
+	}
+}
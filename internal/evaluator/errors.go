@@ -0,0 +1,341 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Span locates an evaluation error in the input.
+type Span struct {
+	// Statement is the index of the failing statement, starting at 0.
+	Statement int
+	// Column and EndColumn are the 1-based columns of the first and last
+	// offending characters. Column is 0 when the position is unknown.
+	Column    int
+	EndColumn int
+	// Token is the offending source text.
+	Token string
+
+	// pos and end are the byte offsets of the offending text, valid when
+	// located is set.
+	pos, end int
+	located  bool
+}
+
+// span returns the Span embedded in an error, so positions can be filled in
+// as the error travels up through the evaluator.
+func (s *Span) span() *Span {
+	return s
+}
+
+// at records the byte range [pos, end) unless a position is already known.
+func (s *Span) at(pos, end int) {
+	if !s.located {
+		s.move(pos, end)
+	}
+}
+
+// move records the byte range [pos, end), replacing any known position.
+func (s *Span) move(pos, end int) {
+	s.pos, s.end, s.located = pos, end, true
+}
+
+// where returns the position prefix used in error messages.
+func (s *Span) where() string {
+	if s.Column == 0 {
+		return ""
+	}
+	return fmt.Sprintf("column %d: ", s.Column)
+}
+
+// positioned is implemented by all of the evaluator's error types.
+type positioned interface {
+	error
+	span() *Span
+}
+
+// SyntaxError reports malformed input.
+type SyntaxError struct {
+	Span
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.where() + e.Msg
+}
+
+// UndefinedVariableError reports a reference to a variable that has not been
+// assigned.
+type UndefinedVariableError struct {
+	Span
+	Name string
+}
+
+func (e *UndefinedVariableError) Error() string {
+	return e.where() + "undefined variable: " + e.Name
+}
+
+// UndefinedFunctionError reports a call to a function that does not exist.
+type UndefinedFunctionError struct {
+	Span
+	Name string
+}
+
+func (e *UndefinedFunctionError) Error() string {
+	return e.where() + "undefined function: " + e.Name
+}
+
+// AmbiguousUnitError reports a unit after a number whose name is also a
+// variable in scope, as in 2 A after A = 5. Multiplying by the variable
+// needs an explicit *.
+type AmbiguousUnitError struct {
+	Span
+	Name string
+}
+
+func (e *AmbiguousUnitError) Error() string {
+	return e.where() + "ambiguous unit: " + e.Name + " is also a variable, write * " + e.Name + " to multiply by it"
+}
+
+// DomainError reports a function argument outside the function's domain,
+// such as the square root of a negative number.
+type DomainError struct {
+	Span
+	Func string
+	Msg  string
+	// Err is the underlying error when a function failed with an error of
+	// its own, and nil otherwise.
+	Err error
+}
+
+func (e *DomainError) Error() string {
+	if e.Func == "" {
+		return e.where() + e.Msg
+	}
+	return e.where() + e.Func + ": " + e.Msg
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+// ArityError reports a function called with the wrong number of arguments.
+type ArityError struct {
+	Span
+	Func string
+	// Min and Max bound the accepted number of arguments. Max is -1 for
+	// functions that take any number of arguments from Min upwards.
+	Min, Max int
+	Got      int
+}
+
+func (e *ArityError) Error() string {
+	var want string
+	switch {
+	case e.Min == e.Max:
+		want = fmt.Sprintf("exactly %d %s", e.Min, plural(e.Min, "argument"))
+	case e.Max < 0:
+		want = fmt.Sprintf("at least %d %s", e.Min, plural(e.Min, "argument"))
+	default:
+		want = fmt.Sprintf("%d to %d arguments", e.Min, e.Max)
+	}
+	return fmt.Sprintf("%s%s expects %s, got %d", e.where(), e.Func, want, e.Got)
+}
+
+// DivisionByZeroError reports a division or modulo by zero.
+type DivisionByZeroError struct {
+	Span
+}
+
+func (e *DivisionByZeroError) Error() string {
+	return e.where() + "division by zero"
+}
+
+// NonFiniteError reports an operator or built-in function that returned
+// NaN or an infinity from finite operands, such as log(-1) or exp(1000).
+// It is an error in sessions with Options.Strict, and a warning attached to
+// the statement's Result otherwise.
+type NonFiniteError struct {
+	Span
+	// Func is the function that returned the value, or Op the operator.
+	Func string
+	Op   string
+	// Value is "NaN", "+Inf" or "-Inf".
+	Value string
+}
+
+func (e *NonFiniteError) Error() string {
+	name := e.Func
+	if name == "" {
+		name = "operator " + e.Op
+	}
+	if e.Value == "NaN" {
+		return e.where() + name + ": result is undefined (NaN)"
+	}
+	return e.where() + name + ": result is infinite (" + e.Value + ")"
+}
+
+// TypeError reports an operand or argument of the wrong kind, such as a
+// boolean where a number is required.
+type TypeError struct {
+	Span
+	Msg string
+}
+
+func (e *TypeError) Error() string {
+	return e.where() + e.Msg
+}
+
+// RecursionError reports user-defined function calls nested deeper than the
+// session allows.
+type RecursionError struct {
+	Span
+	Func  string
+	Depth int
+}
+
+func (e *RecursionError) Error() string {
+	return fmt.Sprintf("%s%s: maximum call depth of %d exceeded", e.where(), e.Func, e.Depth)
+}
+
+// RedefinitionError reports an attempt to define a function with the name
+// of a built-in.
+type RedefinitionError struct {
+	Span
+	Name string
+}
+
+func (e *RedefinitionError) Error() string {
+	return e.where() + "cannot redefine built-in function " + e.Name
+}
+
+// ConstantError reports an attempt to assign a built-in constant.
+type ConstantError struct {
+	Span
+	Name string
+}
+
+func (e *ConstantError) Error() string {
+	return e.where() + "cannot assign to constant " + e.Name
+}
+
+// locate fills in the statement index, columns and token of a positioned
+// error from its byte offsets into input.
+func locate(err error, input string) error {
+	var p positioned
+	if !errors.As(err, &p) {
+		return err
+	}
+	s := p.span()
+	if !s.located || s.Column != 0 {
+		return err
+	}
+	pos, end := clamp(s.pos, input), clamp(s.end, input)
+	s.Statement = strings.Count(input[:pos], ";")
+	s.Column = utf8.RuneCountInString(input[:pos]) + 1
+	s.EndColumn = s.Column
+	if end > pos {
+		s.Token = input[pos:end]
+		s.EndColumn = s.Column + utf8.RuneCountInString(s.Token) - 1
+	}
+	return err
+}
+
+// RenderError formats err for display, underlining the offending part of
+// input when the error carries a position:
+//
+//	undefined variable: C
+//	  C + 5
+//	  ^
+func RenderError(input string, err error) string {
+	if underline := Underline(input, err); underline != "" {
+		return Message(err) + "\n" + underline
+	}
+	return err.Error()
+}
+
+// Message returns the message of err without its column prefix.
+func Message(err error) string {
+	var p positioned
+	if !errors.As(err, &p) {
+		return err.Error()
+	}
+	return strings.TrimPrefix(err.Error(), p.span().where())
+}
+
+// Position returns the 1-based line and column in input at which a
+// positioned error occurred, or zeros when err carries no position.
+func Position(input string, err error) (line, column int) {
+	var p positioned
+	if !errors.As(err, &p) || p.span().Column == 0 {
+		return 0, 0
+	}
+	line, column = 1, p.span().Column
+	for _, l := range strings.Split(input, "\n") {
+		n := utf8.RuneCountInString(l) + 1
+		if column <= n {
+			break
+		}
+		column -= n
+		line++
+	}
+	return line, column
+}
+
+// Underline returns the line of input on which a positioned error occurred
+// with the offending text underlined, or "" when err carries no position.
+func Underline(input string, err error) string {
+	line, column := Position(input, err)
+	if line == 0 {
+		return ""
+	}
+	lines := strings.Split(input, "\n")
+	text := lines[min(line, len(lines))-1]
+
+	var p positioned
+	errors.As(err, &p)
+	width := p.span().EndColumn - p.span().Column
+	if width < 0 {
+		width = 0
+	}
+	return fmt.Sprintf("  %s\n  %s^%s", text, indent(text, column-1), strings.Repeat("~", width))
+}
+
+// indent returns the padding that lines up with the first n characters of
+// text, keeping its tabs so that the caret lands under the right character
+// however wide the terminal draws a tab.
+func indent(text string, n int) string {
+	var b strings.Builder
+	for _, r := range text {
+		if n == 0 {
+			break
+		}
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+		n--
+	}
+	return b.String() + strings.Repeat(" ", n)
+}
+
+// clamp limits a byte offset to the bounds of input.
+func clamp(offset int, input string) int {
+	if offset < 0 {
+		return 0
+	}
+	if offset > len(input) {
+		return len(input)
+	}
+	return offset
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
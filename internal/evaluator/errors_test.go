@@ -0,0 +1,217 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestErrorTypes tests that evaluation errors carry their type and position
+func TestErrorTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		check     func(err error) bool
+		statement int
+		column    int
+		endColumn int
+		token     string
+	}{
+		{
+			name:   "Syntax error",
+			input:  "5 + * 3",
+			check:  func(err error) bool { var e *SyntaxError; return errors.As(err, &e) },
+			column: 5, endColumn: 5, token: "*",
+		},
+		{
+			name:   "Unexpected character",
+			input:  "2 # 3",
+			check:  func(err error) bool { var e *SyntaxError; return errors.As(err, &e) },
+			column: 3, endColumn: 3, token: "#",
+		},
+		{
+			name:      "Undefined variable in second statement",
+			input:     "A = 1; A + Missing",
+			check:     func(err error) bool { var e *UndefinedVariableError; return errors.As(err, &e) && e.Name == "Missing" },
+			statement: 1, column: 12, endColumn: 18, token: "Missing",
+		},
+		{
+			name:   "Undefined function",
+			input:  "nope(1)",
+			check:  func(err error) bool { var e *UndefinedFunctionError; return errors.As(err, &e) },
+			column: 1, endColumn: 4, token: "nope",
+		},
+		{
+			name:   "Domain error",
+			input:  "1 + sqrt(-4)",
+			check:  func(err error) bool { var e *DomainError; return errors.As(err, &e) && e.Func == "sqrt" },
+			column: 5, endColumn: 12, token: "sqrt(-4)",
+		},
+		{
+			name:   "Arity error",
+			input:  "pow(1)",
+			check:  func(err error) bool { var e *ArityError; return errors.As(err, &e) && e.Got == 1 && e.Min == 2 },
+			column: 1, endColumn: 6, token: "pow(1)",
+		},
+		{
+			name:   "Division by zero",
+			input:  "10 / (5 - 5)",
+			check:  func(err error) bool { var e *DivisionByZeroError; return errors.As(err, &e) },
+			column: 6, endColumn: 12, token: "(5 - 5)",
+		},
+		{
+			name:   "Type error",
+			input:  "(1 < 2) + 1",
+			check:  func(err error) bool { var e *TypeError; return errors.As(err, &e) },
+			column: 1, endColumn: 11, token: "(1 < 2) + 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Options{}).Evaluate(tt.input)
+			if err == nil {
+				t.Fatalf("Expected an error, but got none")
+			}
+			if !tt.check(err) {
+				t.Fatalf("Unexpected error type %T: %v", err, err)
+			}
+
+			var p positioned
+			if !errors.As(err, &p) {
+				t.Fatalf("Error %T carries no position", err)
+			}
+			s := p.span()
+			if s.Statement != tt.statement || s.Column != tt.column || s.EndColumn != tt.endColumn || s.Token != tt.token {
+				t.Errorf("Expected statement %d, columns %d-%d, token %q; got statement %d, columns %d-%d, token %q",
+					tt.statement, tt.column, tt.endColumn, tt.token, s.Statement, s.Column, s.EndColumn, s.Token)
+			}
+		})
+	}
+}
+
+// TestRenderError tests the caret rendering of positioned errors
+func TestRenderError(t *testing.T) {
+	input := "A = 2; A * sqrt(-1)"
+	_, err := New(Options{}).Evaluate(input)
+	if err == nil {
+		t.Fatalf("Expected an error, but got none")
+	}
+
+	expected := "sqrt: square root of negative number\n" +
+		"  A = 2; A * sqrt(-1)\n" +
+		"             ^~~~~~~~"
+	if got := RenderError(input, err); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// Tabs are copied into the padding, so the caret lines up with them
+	tabbed := "A = 1;\n\tB = 2 +\t Missing"
+	_, err = New(Options{}).Evaluate(tabbed)
+	expected = "  \tB = 2 +\t Missing\n  \t       \t ^~~~~~~"
+	if got := Underline(tabbed, err); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+
+	plain := errors.New("plain failure")
+	if got := RenderError(input, plain); got != "plain failure" {
+		t.Errorf("Expected %q, got %q", "plain failure", got)
+	}
+}
+
+// TestErrorPosition tests the line and column of errors in multi-line input
+func TestErrorPosition(t *testing.T) {
+	input := "A = 1;\nB = A +\n  Missing * 2"
+	_, err := New(Options{}).Evaluate(input)
+	if err == nil {
+		t.Fatalf("Expected an error, but got none")
+	}
+
+	if line, column := Position(input, err); line != 3 || column != 3 {
+		t.Errorf("Expected line 3, column 3, got line %d, column %d", line, column)
+	}
+	if got := Message(err); got != "undefined variable: Missing" {
+		t.Errorf("Expected %q, got %q", "undefined variable: Missing", got)
+	}
+	expected := "    Missing * 2\n    ^~~~~~~"
+	if got := Underline(input, err); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// Tabs are copied into the padding, so the caret lines up with them
+	tabbed := "A = 1;\n\tB = 2 +\t Missing"
+	_, err = New(Options{}).Evaluate(tabbed)
+	expected = "  \tB = 2 +\t Missing\n  \t       \t ^~~~~~~"
+	if got := Underline(tabbed, err); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+
+	plain := errors.New("plain failure")
+	if line, column := Position(input, plain); line != 0 || column != 0 {
+		t.Errorf("Expected no position, got line %d, column %d", line, column)
+	}
+	if Message(plain) != "plain failure" || Underline(input, plain) != "" {
+		t.Errorf("Expected an unpositioned error to render plainly")
+	}
+}
+
+// TestNonFinitePolicy tests that NaN and infinite results are errors in
+// strict sessions and warnings otherwise
+func TestNonFinitePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		input  string
+		fn, op string
+		value  string
+		column int
+	}{
+		{"Logarithm of a negative number", Options{}, "log(-1)", "log", "", "NaN", 1},
+		{"Overflow", Options{}, "1 + exp(1000)", "exp", "", "+Inf", 5},
+		{"Operator overflow", Options{}, "2 ^ 5000", "", "^", "+Inf", 1},
+		{"Negative infinity", Options{}, "x = 0; log(x)", "log", "", "-Inf", 8},
+		{"Big float overflow", Options{Precision: 20}, "exp(10^12) > 0", "exp", "", "+Inf", 1},
+		{"Inside a user function", Options{}, "f(x) = exp(x); f(1000)", "exp", "", "+Inf", 16},
+		{"In a list", Options{}, "[1, exp(1000)]", "exp", "", "+Inf", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+
+			// Strict sessions fail at the first non-finite result
+			opts.Strict = true
+			_, err := New(opts).Evaluate(tt.input)
+			var nonFinite *NonFiniteError
+			if !errors.As(err, &nonFinite) {
+				t.Fatalf("Expected a NonFiniteError, got %v", err)
+			}
+			if nonFinite.Func != tt.fn || nonFinite.Op != tt.op || nonFinite.Value != tt.value || nonFinite.Column != tt.column {
+				t.Errorf("Expected %s%s giving %s at column %d, got %+v", tt.fn, tt.op, tt.value, tt.column, nonFinite)
+			}
+
+			// Lenient sessions keep the value and warn once
+			opts.Strict = false
+			results, err := New(opts).Exec(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			warnings := results[len(results)-1].Warnings
+			if len(warnings) != 1 || !errors.As(warnings[0], &nonFinite) || nonFinite.Column != tt.column {
+				t.Errorf("Expected one warning at column %d, got %v", tt.column, warnings)
+			}
+		})
+	}
+
+	// Operations on values that are already non-finite do not warn again,
+	// and finite results do not warn at all
+	session := New(Options{})
+	results, err := session.Exec("x = exp(1000); x + 1; x - x; 1 + 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, want := range []int{1, 0, 0, 0} {
+		if got := len(results[i].Warnings); got != want {
+			t.Errorf("Statement %d: expected %d warnings, got %d", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// exec runs a single statement of input. Assignments and function
+// definitions return a nil value.
+func (e *Evaluator) exec(stmt Node, input string) (interface{}, error) {
+	switch s := stmt.(type) {
+	case *AssignStmt:
+		if IsConstant(s.Name.Name) {
+			return nil, withSpan(&ConstantError{Name: s.Name.Name}, s.Name)
+		}
+		val, err := e.eval(s.Value)
+		if err != nil {
+			return nil, err
+		}
+		e.variables[s.Name.Name] = val
+		return nil, nil
+
+	case *FuncDef:
+		if _, ok := e.registry.Lookup(s.Name.Name); ok {
+			return nil, withSpan(&RedefinitionError{Name: s.Name.Name}, s.Name)
+		}
+		fn := &UserFunction{
+			Name: s.Name.Name,
+			Body: input[s.Body.Pos():s.Body.End()],
+			body: s.Body,
+		}
+		for _, param := range s.Params {
+			fn.Params = append(fn.Params, param.Name)
+		}
+		e.userFuncs[fn.Name] = fn
+		return nil, nil
+	}
+	return e.eval(stmt)
+}
+
+// eval evaluates an expression node.
+func (e *Evaluator) eval(node Node) (interface{}, error) {
+	switch n := node.(type) {
+	case *NumberLit:
+		if n.Imaginary {
+			return complex(0, n.Value), nil
+		}
+		if e.options.Exact {
+			val, ok := new(big.Rat).SetString(n.Text)
+			if !ok {
+				return nil, withSpan(&SyntaxError{Msg: fmt.Sprintf("invalid number %q", n.Text)}, n)
+			}
+			return val, nil
+		}
+		if e.options.Precision > 0 {
+			val, ok := newFloat(bitsForDigits(e.options.Precision)).SetString(n.Text)
+			if !ok {
+				return nil, withSpan(&SyntaxError{Msg: fmt.Sprintf("invalid number %q", n.Text)}, n)
+			}
+			return val, nil
+		}
+		return n.Value, nil
+
+	case *BoolLit:
+		return n.Value, nil
+
+	case *Ident:
+		if val, ok := e.locals[n.Name]; ok {
+			return val, nil
+		}
+		if val, ok := e.constant(n.Name); ok {
+			return val, nil
+		}
+		val, ok := e.variables[n.Name]
+		if !ok {
+			// i and j are the imaginary unit unless assigned
+			if n.Name == "i" || n.Name == "j" {
+				return complex(0, 1), nil
+			}
+			return nil, withSpan(&UndefinedVariableError{Name: n.Name}, n)
+		}
+		return val, nil
+
+	case *ParenExpr:
+		return e.eval(n.X)
+
+	case *ListExpr:
+		values := make([]interface{}, len(n.Elems))
+		for i, elem := range n.Elems {
+			val, err := e.eval(elem)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = val
+		}
+		return values, nil
+
+	case *UnaryExpr:
+		x, err := e.eval(n.X)
+		if err != nil {
+			return nil, err
+		}
+		val, err := unaryOp(n.Op, x)
+		if err != nil {
+			return nil, withSpan(err, n)
+		}
+		return e.finite(val, n, &NonFiniteError{Op: n.Op}, x)
+
+	case *BinaryExpr:
+		if n.Op == "&&" || n.Op == "||" {
+			return e.logical(n)
+		}
+		x, err := e.eval(n.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := e.eval(n.Y)
+		if err != nil {
+			return nil, err
+		}
+		var val interface{}
+		if n.Op == "^" && e.options.Complex {
+			val, err = complexPow(x, y)
+		} else {
+			val, err = binaryOp(n.Op, x, y)
+		}
+		if err != nil {
+			// Point division by zero at the divisor, anything else at the
+			// whole operation
+			var divErr *DivisionByZeroError
+			if errors.As(err, &divErr) {
+				return nil, withSpan(err, n.Y)
+			}
+			return nil, withSpan(err, n)
+		}
+		return e.finite(val, n, &NonFiniteError{Op: n.Op}, x, y)
+
+	case *CondExpr:
+		for i, cond := range n.Conds {
+			holds, err := e.condition(cond, "condition must be a boolean or a real number")
+			if err != nil {
+				return nil, err
+			}
+			if holds {
+				return e.eval(n.Values[i])
+			}
+		}
+		if n.Else == nil {
+			return nil, withSpan(&DomainError{Func: "piecewise", Msg: "no condition holds and there is no otherwise value"}, n)
+		}
+		return e.eval(n.Else)
+
+	case *QuantityExpr:
+		for _, name := range n.Unit.Names {
+			if e.isVariable(name.Name) {
+				return nil, withSpan(&AmbiguousUnitError{Name: name.Name}, name)
+			}
+		}
+		x, err := e.eval(n.X)
+		if err != nil {
+			return nil, err
+		}
+		unit := units.New(1, n.Unit.Unit)
+		if q, ok := x.(units.Quantity); ok {
+			val, err := quantityOp("*", q, unit)
+			if err != nil {
+				return nil, withSpan(err, n)
+			}
+			return val, nil
+		}
+		f, ok := toFloat(x)
+		if !ok {
+			return nil, withSpan(&TypeError{Msg: "a unit must follow a real number"}, n)
+		}
+		return units.New(f, n.Unit.Unit), nil
+
+	case *ConvertExpr:
+		x, err := e.eval(n.X)
+		if err != nil {
+			return nil, err
+		}
+		val, err := convert(x, n.Unit.Unit)
+		if err != nil {
+			return nil, withSpan(err, n)
+		}
+		return e.finite(val, n, &NonFiniteError{Func: "to"}, x)
+
+	case *CallExpr:
+		userFn, isUser := e.userFuncs[n.Name.Name]
+		fn, isBuiltin := e.registry.Lookup(n.Name.Name)
+		if !isUser && !isBuiltin {
+			return nil, withSpan(&UndefinedFunctionError{Name: n.Name.Name}, n.Name)
+		}
+		args := make([]interface{}, len(n.Args))
+		for i, arg := range n.Args {
+			val, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = val
+		}
+		if isUser {
+			return e.callUser(userFn, n, args)
+		}
+		val, err := fn.Call(args...)
+		if err != nil {
+			if _, ok := err.(positioned); !ok {
+				err = &DomainError{Func: n.Name.Name, Msg: err.Error(), Err: err}
+			}
+			return nil, withSpan(err, n)
+		}
+		return e.finite(val, n, &NonFiniteError{Func: n.Name.Name}, args...)
+	}
+	return nil, fmt.Errorf("cannot evaluate %T", node)
+}
+
+// isVariable reports whether name is a variable or parameter in scope.
+func (e *Evaluator) isVariable(name string) bool {
+	if _, ok := e.locals[name]; ok {
+		return true
+	}
+	_, ok := e.variables[name]
+	return ok
+}
+
+// logical evaluates a && or || operation, evaluating the right operand only
+// when the left one does not decide the result.
+func (e *Evaluator) logical(n *BinaryExpr) (interface{}, error) {
+	msg := fmt.Sprintf("operator %s expects boolean or real operands", n.Op)
+	x, err := e.condition(n.X, msg)
+	if err != nil {
+		return nil, err
+	}
+	if x == (n.Op == "||") {
+		return x, nil
+	}
+	return e.condition(n.Y, msg)
+}
+
+// condition evaluates node as a truth value, failing with a TypeError with
+// the given message if it is neither a boolean nor a real number.
+func (e *Evaluator) condition(node Node, msg string) (bool, error) {
+	val, err := e.eval(node)
+	if err != nil {
+		return false, err
+	}
+	holds, ok := truth(val)
+	if !ok {
+		return false, withSpan(&TypeError{Msg: msg}, node)
+	}
+	return holds, nil
+}
+
+// callUser calls a user-defined function with evaluated arguments. Errors
+// raised inside the body are reported at the call, since the body's
+// positions refer to the input that defined it.
+func (e *Evaluator) callUser(fn *UserFunction, call *CallExpr, args []interface{}) (interface{}, error) {
+	if len(args) != len(fn.Params) {
+		return nil, withSpan(&ArityError{Func: fn.Name, Min: len(fn.Params), Max: len(fn.Params), Got: len(args)}, call)
+	}
+	maxDepth := e.options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if e.depth >= maxDepth {
+		return nil, withSpan(&RecursionError{Func: fn.Name, Depth: maxDepth}, call)
+	}
+
+	locals := make(map[string]interface{}, len(args))
+	for i, param := range fn.Params {
+		locals[param] = args[i]
+	}
+	saved := e.locals
+	e.locals = locals
+	e.depth++
+	defer func() {
+		e.locals = saved
+		e.depth--
+	}()
+
+	warnings := len(e.warnings)
+	val, err := e.eval(fn.body)
+	if err != nil {
+		if p, ok := err.(positioned); ok {
+			p.span().move(call.Pos(), call.End())
+		}
+		return nil, err
+	}
+	for _, w := range e.warnings[warnings:] {
+		w.(positioned).span().move(call.Pos(), call.End())
+	}
+	return val, nil
+}
+
+// finite applies the session's policy on non-finite numbers to the value
+// of node, computed from operands: a value that is or holds NaN or an
+// infinity when the operands do not is reported as nonFinite, an error in
+// strict sessions and a warning otherwise.
+func (e *Evaluator) finite(val interface{}, node Node, nonFinite *NonFiniteError, operands ...interface{}) (interface{}, error) {
+	if nonFinite.Value = nonFiniteValue(val); nonFinite.Value == "" {
+		return val, nil
+	}
+	for _, x := range operands {
+		if nonFiniteValue(x) != "" {
+			return val, nil
+		}
+	}
+	nonFinite.at(node.Pos(), node.End())
+	if e.options.Strict {
+		return nil, nonFinite
+	}
+	e.warnings = append(e.warnings, nonFinite)
+	return val, nil
+}
+
+// nonFiniteValue returns "NaN", "+Inf" or "-Inf" for a number that is not
+// finite, or a value holding one, and "" otherwise.
+func nonFiniteValue(v interface{}) string {
+	switch x := v.(type) {
+	case float64:
+		return nonFiniteFloat(x)
+	case *big.Float:
+		if x.IsInf() {
+			return nonFiniteFloat(math.Inf(x.Sign()))
+		}
+	case complex128:
+		if s := nonFiniteFloat(real(x)); s != "" {
+			return s
+		}
+		return nonFiniteFloat(imag(x))
+	case units.Quantity:
+		return nonFiniteFloat(x.Value)
+	case []interface{}:
+		for _, elem := range x {
+			if s := nonFiniteValue(elem); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// nonFiniteFloat returns "NaN", "+Inf" or "-Inf" for a float64 that is not
+// finite, and "" otherwise.
+func nonFiniteFloat(x float64) string {
+	switch {
+	case math.IsNaN(x):
+		return "NaN"
+	case math.IsInf(x, 1):
+		return "+Inf"
+	case math.IsInf(x, -1):
+		return "-Inf"
+	}
+	return ""
+}
+
+// withSpan attaches the position of node to err unless it already has one.
+func withSpan(err error, node Node) error {
+	if p, ok := err.(positioned); ok {
+		p.span().at(node.Pos(), node.End())
+	}
+	return err
+}
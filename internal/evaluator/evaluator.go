@@ -2,247 +2,299 @@ package evaluator
 
 import (
 	"fmt"
-	"math"
+	"sort"
 	"strings"
-
-	"github.com/Knetic/govaluate"
+	"sync"
 )
 
-// variables stores user-defined variables
-var variables = make(map[string]interface{})
+// Options configures an evaluation session.
+type Options struct {
+	// Variables seeds the session's variable scope. Reset restores it.
+	Variables map[string]interface{}
+	// Registry holds the functions available to the session. Nil means the
+	// built-in functions. The session works on its own copy, so functions
+	// registered on the session do not affect the registry or other
+	// sessions.
+	Registry *Registry
+	// Exact evaluates numeric literals as exact rationals (*big.Rat), so
+	// integers and fractions stay exact until a function without an exact
+	// result, such as sin, forces a float64.
+	Exact bool
+	// Precision, when positive, evaluates numeric literals as *big.Float
+	// values holding that many significant decimal digits, and the built-in
+	// functions compute their results to the same precision. Exact takes
+	// precedence over Precision.
+	Precision int
+	// Complex gives real functions complex results where they have no real
+	// one, so sqrt(-4) is 2i rather than an error, log(-1) is pi*i and
+	// (-8)^(1/3) is the principal cube root.
+	Complex bool
+	// Angle is the unit of the angles taken by sin, cos and tan and
+	// returned by asin, acos, atan, atan2 and arg. The zero value is
+	// Radians.
+	Angle AngleUnit
+	// FactorialGamma makes fact of a non-integer x return gamma(x + 1)
+	// instead of an error, so fact(0.5) is sqrt(pi)/2.
+	FactorialGamma bool
+	// Strict makes an operator or built-in function that returns NaN or an
+	// infinity from finite operands fail with a NonFiniteError. Otherwise
+	// the value is kept and the NonFiniteError is added to the warnings of
+	// the statement's Result. With Precision, the functions and operators
+	// that return NaN or an infinity for float64 arguments, such as
+	// log(-1), return that float64 under the same policy.
+	Strict bool
+	// MaxDepth limits how deeply calls to user-defined functions may nest.
+	// Zero means DefaultMaxDepth.
+	MaxDepth int
+}
 
-// functions maps custom functions to their implementations
-var functions = map[string]govaluate.ExpressionFunction{
-	"sqrt": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("sqrt expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("sqrt expects a numeric argument")
-		}
-		if val < 0 {
-			return nil, fmt.Errorf("square root of negative number")
-		}
-		return math.Sqrt(val), nil
-	},
-	"sin": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("sin expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("sin expects a numeric argument")
-		}
-		return math.Sin(val), nil
-	},
-	"cos": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("cos expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("cos expects a numeric argument")
-		}
-		return math.Cos(val), nil
-	},
-	"tan": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("tan expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("tan expects a numeric argument")
-		}
-		return math.Tan(val), nil
-	},
-	"fact": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("fact expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("fact expects a numeric argument")
-		}
-		if val < 0 {
-			return nil, fmt.Errorf("factorial is not defined for negative numbers")
-		}
-		result := 1.0
-		for i := 1.0; i <= val; i++ {
-			result *= i
-		}
-		return result, nil
-	},
-	"log": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("log expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("log expects a numeric argument")
-		}
-		return math.Log(val), nil
-	},
-	"log10": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("log10 expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("log10 expects a numeric argument")
-		}
-		return math.Log10(val), nil
-	},
-	"exp": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("exp expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("exp expects a numeric argument")
-		}
-		return math.Exp(val), nil
-	},
-	"pow": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 2 {
-			return nil, fmt.Errorf("pow expects exactly 2 arguments")
-		}
-		base, ok1 := args[0].(float64)
-		exponent, ok2 := args[1].(float64)
-		if !ok1 || !ok2 {
-			return nil, fmt.Errorf("pow expects numeric arguments")
-		}
-		return math.Pow(base, exponent), nil
-	},
-	"abs": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("abs expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("abs expects a numeric argument")
-		}
-		return math.Abs(val), nil
-	},
-	"ceil": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("ceil expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("ceil expects a numeric argument")
-		}
-		return math.Ceil(val), nil
-	},
-	"floor": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("floor expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("floor expects a numeric argument")
-		}
-		return math.Floor(val), nil
-	},
-	"round": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("round expects exactly 1 argument")
-		}
-		val, ok := args[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("round expects a numeric argument")
-		}
-		return math.Round(val), nil
-	},
-	"min": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 2 {
-			return nil, fmt.Errorf("min expects exactly 2 arguments")
-		}
-		val1, ok1 := args[0].(float64)
-		val2, ok2 := args[1].(float64)
-		if !ok1 || !ok2 {
-			return nil, fmt.Errorf("min expects numeric arguments")
-		}
-		return math.Min(val1, val2), nil
-	},
-	"max": func(args ...interface{}) (interface{}, error) {
-		if len(args) != 2 {
-			return nil, fmt.Errorf("max expects exactly 2 arguments")
-		}
-		val1, ok1 := args[0].(float64)
-		val2, ok2 := args[1].(float64)
-		if !ok1 || !ok2 {
-			return nil, fmt.Errorf("max expects numeric arguments")
-		}
-		return math.Max(val1, val2), nil
-	},
+// DefaultMaxDepth is the call depth limit for sessions that do not set
+// Options.MaxDepth.
+const DefaultMaxDepth = 256
+
+// UserFunction is a function defined in a session by a statement such as
+// f(x, y) = x^2 + y.
+type UserFunction struct {
+	Name   string
+	Params []string
+	// Body is the source text of the function body.
+	Body string
+
+	body Node
+}
+
+// String returns the definition of the function.
+func (f *UserFunction) String() string {
+	return fmt.Sprintf("%s(%s) = %s", f.Name, strings.Join(f.Params, ", "), f.Body)
+}
+
+// Evaluator is an evaluation session with its own variable scope, function
+// table and options. It is safe for concurrent use.
+type Evaluator struct {
+	mu        sync.Mutex
+	options   Options
+	variables map[string]interface{}
+	registry  *Registry
+	userFuncs map[string]*UserFunction
+
+	// locals holds the arguments of the user-defined function being
+	// evaluated, and depth how many such calls are in progress.
+	locals map[string]interface{}
+	depth  int
+	// warnings collects the warnings of the statement being evaluated.
+	warnings []error
+}
+
+// defaultEvaluator is the session used by the package-level Evaluate.
+var defaultEvaluator = New(Options{})
+
+// New creates an evaluation session configured by opts.
+func New(opts Options) *Evaluator {
+	registry := opts.Registry
+	if registry == nil {
+		registry = builtins
+	}
+	e := &Evaluator{
+		options:  opts,
+		registry: registry.Clone(),
+	}
+	var promoted map[string]bool
+	if opts.Complex {
+		promoted = promoteComplex(e.registry)
+	}
+	if opts.Angle != Radians {
+		promoteAngle(e.registry, opts.Angle, promoted)
+	}
+	if opts.FactorialGamma {
+		promoteFactorial(e.registry)
+	}
+	e.variables = copyVariables(opts.Variables)
+	e.userFuncs = make(map[string]*UserFunction)
+	return e
 }
 
-// Evaluate evaluates a mathematical expression or assigns a variable
+// Evaluate evaluates a mathematical expression or assigns a variable using
+// the default session.
 func Evaluate(expression string) (interface{}, error) {
-	// Check for empty expression
-	if strings.TrimSpace(expression) == "" {
-		return nil, fmt.Errorf("empty expression")
+	return defaultEvaluator.Evaluate(expression)
+}
+
+// Reset discards all assignments and function definitions made in the
+// session, restoring the variables it was created with.
+func (e *Evaluator) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.variables = copyVariables(e.options.Variables)
+	e.userFuncs = make(map[string]*UserFunction)
+}
+
+// Snapshot returns a copy of the session's variables.
+func (e *Evaluator) Snapshot() map[string]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return copyVariables(e.variables)
+}
+
+// SetVariable assigns a variable in the session, as the statement
+// name = value would. A variable named like a built-in constant is hidden
+// by the constant.
+func (e *Evaluator) SetVariable(name string, value interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.variables[name] = value
+}
+
+// Clone returns an independent session with the same options, functions and
+// function definitions, and a copy of the current variables.
+func (e *Evaluator) Clone() *Evaluator {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c := &Evaluator{
+		options:   e.options,
+		variables: copyVariables(e.variables),
+		registry:  e.registry.Clone(),
+		userFuncs: make(map[string]*UserFunction, len(e.userFuncs)),
+	}
+	for name, fn := range e.userFuncs {
+		c.userFuncs[name] = fn
 	}
+	return c
+}
 
-	// Split the input into individual statements (e.g., "A = 5; A + 3")
-	statements := strings.Split(expression, ";")
-	var result interface{}
+// Register adds a function to the session, overriding any registered
+// function of the same name for this session only. See Registry.Register.
+func (e *Evaluator) Register(name string, arity int, fn Function, doc string) error {
+	return e.registry.Register(name, arity, fn, doc)
+}
 
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
-		}
+// RegisterVariadic adds a function taking minArgs or more arguments to the
+// session. See Registry.RegisterVariadic.
+func (e *Evaluator) RegisterVariadic(name string, minArgs int, fn Function, doc string) error {
+	return e.registry.RegisterVariadic(name, minArgs, fn, doc)
+}
 
-		// Check if the statement is a variable assignment (e.g., "A = 5")
-		if strings.Contains(stmt, "=") {
-			parts := strings.SplitN(stmt, "=", 2)
-			if len(parts) != 2 {
-				return nil, fmt.Errorf("invalid variable assignment: %s", stmt)
-			}
+// Functions returns the registered functions available to the session,
+// sorted by name.
+func (e *Evaluator) Functions() []*Entry {
+	return e.registry.Entries()
+}
 
-			// Extract variable name and value
-			varName := strings.TrimSpace(parts[0])
-			varValue := strings.TrimSpace(parts[1])
+// UserFunctions returns the functions defined in the session, sorted by
+// name.
+func (e *Evaluator) UserFunctions() []*UserFunction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	funcs := make([]*UserFunction, 0, len(e.userFuncs))
+	for _, fn := range e.userFuncs {
+		funcs = append(funcs, fn)
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].Name < funcs[j].Name })
+	return funcs
+}
 
-			// Evaluate the value expression
-			expr, err := govaluate.NewEvaluableExpressionWithFunctions(varValue, functions)
-			if err != nil {
-				return nil, fmt.Errorf("invalid value expression: %v", err)
-			}
+// copyVariables returns a shallow copy of a variable scope.
+func copyVariables(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for name, val := range src {
+		dst[name] = val
+	}
+	return dst
+}
 
-			val, err := expr.Evaluate(variables)
-			if err != nil {
-				return nil, fmt.Errorf("failed to evaluate value expression: %v", err)
-			}
+// Result is the outcome of one statement.
+type Result struct {
+	// Statement is the index of the statement in the input, starting at 0.
+	Statement int
+	// Line is the line of the input the statement starts on, starting at 1.
+	Line int
+	// Source is the text of the statement.
+	Source string
+	// Value is the value of an expression, or nil for an assignment or
+	// function definition.
+	Value interface{}
+	// Warnings holds a *NonFiniteError, located like errors, for each
+	// operation of the statement that returned NaN or an infinity in a
+	// session without Options.Strict.
+	Warnings []error
+}
 
-			// Store the variable in the map
-			variables[varName] = val
-			continue
+// Evaluate evaluates a mathematical expression or assigns a variable. When
+// the input holds several statements, the value of the last expression is
+// returned.
+func (e *Evaluator) Evaluate(expression string) (interface{}, error) {
+	results, err := e.Exec(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	for _, r := range results {
+		// Assignments produce no value and leave the previous result in place
+		if r.Value != nil {
+			result = r.Value
 		}
+	}
+	return result, nil
+}
 
-		// Replace the exponent operator (^) with ** (supported by govaluate)
-		stmt = strings.ReplaceAll(stmt, "^", "**")
+// Exec evaluates each statement of input in turn and returns their results.
+// Evaluation stops at the first error, which is one of the error types in
+// this package with its position resolved against input; the results of the
+// statements before it are still returned.
+func (e *Evaluator) Exec(input string) ([]Result, error) {
+	results, errs := e.execStatements(input, false)
+	if len(errs) > 0 {
+		return results, errs[0]
+	}
+	return results, nil
+}
 
-		// Evaluate the expression using the stored variables and custom functions
-		expr, err := govaluate.NewEvaluableExpressionWithFunctions(stmt, functions)
-		if err != nil {
-			return nil, fmt.Errorf("invalid expression: %v", err)
-		}
+// ExecAll evaluates each statement of input in turn like Exec, but goes on
+// with the next statement after one fails. It returns the results of the
+// statements that succeeded and the errors of those that failed, in order.
+// A syntax error fails the whole input, as no statement can run.
+func (e *Evaluator) ExecAll(input string) ([]Result, []error) {
+	return e.execStatements(input, true)
+}
 
-		result, err = expr.Evaluate(variables)
-		if err != nil {
-			return nil, fmt.Errorf("failed to evaluate expression: %v", err)
-		}
+// execStatements evaluates the statements of input, stopping at the first
+// failure unless keepGoing is set.
+func (e *Evaluator) execStatements(input string, keepGoing bool) ([]Result, []error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Check for empty expression
+	if strings.TrimSpace(input) == "" {
+		return nil, []error{&SyntaxError{Msg: "empty expression"}}
+	}
+
+	program, err := parse(input)
+	if err != nil {
+		return nil, []error{locate(err, input)}
+	}
 
-		// Check for division by zero
-		if strings.Contains(stmt, "/") {
-			if val, ok := result.(float64); ok && math.IsInf(val, 0) {
-				return nil, fmt.Errorf("division by zero")
+	results := make([]Result, 0, len(program.Statements))
+	var errs []error
+	for _, stmt := range program.Statements {
+		e.warnings = nil
+		val, err := e.exec(stmt, input)
+		if err != nil {
+			errs = append(errs, locate(err, input))
+			if !keepGoing {
+				break
 			}
+			continue
 		}
+		for _, w := range e.warnings {
+			locate(w, input)
+		}
+		results = append(results, Result{
+			Statement: strings.Count(input[:stmt.Pos()], ";"),
+			Line:      strings.Count(input[:stmt.Pos()], "\n") + 1,
+			Source:    input[stmt.Pos():stmt.End()],
+			Value:     val,
+			Warnings:  e.warnings,
+		})
 	}
-
-	return result, nil
+	return results, errs
 }
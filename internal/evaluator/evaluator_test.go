@@ -1,6 +1,9 @@
 package evaluator
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -110,13 +113,13 @@ func TestFunctions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fn, ok := functions[tt.function]
+			fn, ok := builtins.Lookup(tt.function)
 			if !ok {
 				t.Errorf("Function %s not found", tt.function)
 				return
 			}
 
-			result, err := fn(tt.args...)
+			result, err := fn.Call(tt.args...)
 
 			// Check for errors
 			if tt.hasError {
@@ -138,4 +141,302 @@ func TestFunctions(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestSessionIsolation tests that sessions do not share variables
+func TestSessionIsolation(t *testing.T) {
+	a := New(Options{})
+	b := New(Options{})
+
+	if _, err := a.Evaluate("X = 10"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := b.Evaluate("X + 1"); err == nil {
+		t.Errorf("Expected an error for a variable assigned in another session, but got none")
+	}
+
+	result, err := a.Evaluate("X + 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != 11.0 {
+		t.Errorf("Expected %v, got %v", 11.0, result)
+	}
+}
+
+// TestSessionOptions tests seeded variables and per-session functions
+func TestSessionOptions(t *testing.T) {
+	registry := Builtins()
+	err := registry.Register("double", 1, func(args ...interface{}) (interface{}, error) {
+		return args[0].(float64) * 2, nil
+	}, "Twice x.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	e := New(Options{
+		Variables: map[string]interface{}{"rate": 0.5},
+		Registry:  registry,
+	})
+
+	result, err := e.Evaluate("double(rate)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != 1.0 {
+		t.Errorf("Expected %v, got %v", 1.0, result)
+	}
+
+	if _, err := Evaluate("double(2)"); err == nil {
+		t.Errorf("Expected an error for a function registered on another session, but got none")
+	}
+}
+
+// TestResetSnapshotClone tests session state management
+func TestResetSnapshotClone(t *testing.T) {
+	e := New(Options{Variables: map[string]interface{}{"A": 1.0}})
+	if _, err := e.Evaluate("B = 2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	snapshot := e.Snapshot()
+	if len(snapshot) != 2 || snapshot["A"] != 1.0 || snapshot["B"] != 2.0 {
+		t.Errorf("Unexpected snapshot: %v", snapshot)
+	}
+	snapshot["A"] = 100.0
+	if result, _ := e.Evaluate("A"); result != 1.0 {
+		t.Errorf("Modifying a snapshot changed the session: A = %v", result)
+	}
+
+	clone := e.Clone()
+	if _, err := clone.Evaluate("B = 3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result, _ := e.Evaluate("B"); result != 2.0 {
+		t.Errorf("Assigning in a clone changed the original: B = %v", result)
+	}
+	if result, _ := clone.Evaluate("A + B"); result != 4.0 {
+		t.Errorf("Expected %v, got %v", 4.0, result)
+	}
+
+	e.Reset()
+	if _, err := e.Evaluate("B"); err == nil {
+		t.Errorf("Expected B to be undefined after Reset, but it evaluated")
+	}
+	if result, _ := e.Evaluate("A"); result != 1.0 {
+		t.Errorf("Expected seeded variable to survive Reset, got %v", result)
+	}
+
+	e.SetVariable("C", 7.0)
+	if result, _ := e.Evaluate("C * 2"); result != 14.0 {
+		t.Errorf("Expected %v, got %v", 14.0, result)
+	}
+}
+
+// TestConcurrentEvaluate tests that a session can be shared between goroutines
+func TestConcurrentEvaluate(t *testing.T) {
+	e := New(Options{})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stmt := fmt.Sprintf("V%d = %d; V%d * 2", i, i, i)
+			result, err := e.Evaluate(stmt)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if result != float64(i*2) {
+				t.Errorf("Expected %v, got %v", float64(i*2), result)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := len(e.Snapshot()); n != 20 {
+		t.Errorf("Expected 20 variables, got %d", n)
+	}
+}
+
+// TestExec tests per-statement results, including partial results on error
+func TestExec(t *testing.T) {
+	e := New(Options{})
+	results, err := e.Exec("A = 2; A * 3; ; A + Missing; 1")
+	if err == nil {
+		t.Fatalf("Expected an error, but got none")
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results before the error, got %d", len(results))
+	}
+	if results[0].Value != nil || results[0].Source != "A = 2" {
+		t.Errorf("Unexpected assignment result: %+v", results[0])
+	}
+	if results[1].Value != 6.0 || results[1].Source != "A * 3" || results[1].Statement != 1 {
+		t.Errorf("Unexpected expression result: %+v", results[1])
+	}
+
+	var undefined *UndefinedVariableError
+	if !errors.As(err, &undefined) || undefined.Statement != 3 {
+		t.Errorf("Expected an undefined variable error in statement 3, got %v", err)
+	}
+
+	results, err = e.Exec("1;\n2;\n\n  3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, line := range []int{1, 2, 4} {
+		if results[i].Line != line {
+			t.Errorf("Expected statement %d on line %d, got %d", i, line, results[i].Line)
+		}
+	}
+}
+
+// TestUserFunctions tests functions defined in expressions
+func TestUserFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+		hasError bool
+	}{
+		{"Two parameters", "f(x, y) = x^2 + y; f(3, 4)", 13.0, false},
+		{"No parameters", "k() = 42; k() / 2", 21.0, false},
+		{"Uses session variables", "A = 10; g(x) = x + A; A = 20; g(1)", 21.0, false},
+		{"Parameters shadow variables", "x = 100; h(x) = x * 2; h(3) + x", 106.0, false},
+		{"Calls other functions", "sq(x) = x * x; hyp(a, b) = sqrt(sq(a) + sq(b)); hyp(3, 4)", 5.0, false},
+		{"Redefinition", "f(x) = x; f(x) = 2 * x; f(4)", 8.0, false},
+		{"Wrong number of arguments", "f(x) = x; f(1, 2)", nil, true},
+		{"Parameters are not global", "f(x) = x; f(1); x", nil, true},
+		{"Built-in redefinition", "sqrt(x) = x", nil, true},
+		{"Unbounded recursion", "loop(x) = loop(x + 1); loop(0)", nil, true},
+		{"Non-name parameter", "f(1) = 2", nil, true},
+		{"Duplicate parameter", "f(x, x) = x", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// TestUserFunctionErrors tests the errors raised by user-defined functions
+func TestUserFunctionErrors(t *testing.T) {
+	e := New(Options{MaxDepth: 10})
+
+	var redefinition *RedefinitionError
+	if _, err := e.Evaluate("max(a, b) = a"); !errors.As(err, &redefinition) || redefinition.Name != "max" {
+		t.Errorf("Expected a redefinition error for max, got %v", err)
+	}
+
+	input := "down(n) = down(n - 1); 1 + down(3)"
+	var recursion *RecursionError
+	_, err := e.Evaluate(input)
+	if !errors.As(err, &recursion) || recursion.Depth != 10 {
+		t.Fatalf("Expected a recursion error at depth 10, got %v", err)
+	}
+	if recursion.Token != "down(3)" || recursion.Statement != 1 {
+		t.Errorf("Expected the error at the call down(3) in statement 1, got %q in statement %d", recursion.Token, recursion.Statement)
+	}
+
+	input = "inv(x) = 1 / x; inv(2) + inv(0)"
+	var division *DivisionByZeroError
+	if _, err := e.Evaluate(input); !errors.As(err, &division) || division.Token != "inv(0)" {
+		t.Errorf("Expected division by zero reported at inv(0), got %v", err)
+	}
+}
+
+// TestConditionals tests boolean operators and conditional expressions
+func TestConditionals(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+		hasError bool
+	}{
+		{"Boolean literals", "true != false", true, false},
+		{"And", "true && false", false, false},
+		{"Or", "false || true", true, false},
+		{"Not", "!(2 > 1)", false, false},
+		{"Numbers as conditions", "!0 && 2", true, false},
+		{"And short-circuits", "false && 1 / 0", false, false},
+		{"Or short-circuits", "true || undefined", true, false},
+		{"Ternary", "2 > 1 ? 10 : 20", 10.0, false},
+		{"Only the chosen branch runs", "1 < 2 ? 1 : 1 / 0", 1.0, false},
+		{"If", "if(0, 1, 2)", 2.0, false},
+		{"Recursive if", "f(n) = if(n <= 1, 1, n * f(n - 1)); f(5)", 120.0, false},
+		{"Piecewise", "tier(q) = piecewise(q < 100, 10, q < 1000, 8, 6); tier(50) + tier(500) + tier(5000)", 24.0, false},
+		{"Piecewise without otherwise", "piecewise(1 > 2, 1)", nil, true},
+		{"Complex condition", "i ? 1 : 2", nil, true},
+		{"Arithmetic on booleans", "true + 1", nil, true},
+		{"Not of a complex number", "!i", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, but got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	// Conditions work on exact and arbitrary-precision numbers
+	for _, opts := range []Options{{Exact: true}, {Precision: 30}} {
+		result, err := New(opts).Evaluate("1/3 > 0.3 && !(1/3 - 1/3) ? 1 : 2")
+		if err != nil || FormatValue(result) != "1" {
+			t.Errorf("%+v: expected 1, got %v (%v)", opts, result, err)
+		}
+	}
+}
+
+// TestListUserFunctions tests listing, cloning and resetting definitions
+func TestListUserFunctions(t *testing.T) {
+	e := New(Options{})
+	if _, err := e.Evaluate("g(x) = x + 1; f(x, y) = x^2 + y"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	funcs := e.UserFunctions()
+	if len(funcs) != 2 {
+		t.Fatalf("Expected 2 functions, got %d", len(funcs))
+	}
+	if got := funcs[0].String(); got != "f(x, y) = x^2 + y" {
+		t.Errorf("Expected %q, got %q", "f(x, y) = x^2 + y", got)
+	}
+	if got := funcs[1].String(); got != "g(x) = x + 1" {
+		t.Errorf("Expected %q, got %q", "g(x) = x + 1", got)
+	}
+
+	clone := e.Clone()
+	e.Reset()
+	if n := len(e.UserFunctions()); n != 0 {
+		t.Errorf("Expected no functions after Reset, got %d", n)
+	}
+	if result, err := clone.Evaluate("g(1)"); err != nil || result != 2.0 {
+		t.Errorf("Expected the clone to keep g, got %v, %v", result, err)
+	}
+}
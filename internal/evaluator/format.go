@@ -0,0 +1,12 @@
+package evaluator
+
+import "github.com/trenchesdeveloper/gomathpro/internal/format"
+
+// FormatValue renders a result for display in its exact form: float64
+// values in the shortest form that reads back exactly, exact rationals as
+// an integer or a fraction such as 1/2, big floats with as many significant
+// digits as their precision holds and complex numbers in the form the
+// evaluator accepts, such as 1+2i. Use package format for other layouts.
+func FormatValue(v interface{}) string {
+	return format.Value(v, format.Options{})
+}
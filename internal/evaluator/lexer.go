@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind identifies the lexical class of a token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLparen
+	tokenRparen
+	tokenLbracket
+	tokenRbracket
+	tokenComma
+	tokenSemicolon
+)
+
+// token is a lexical token with its byte range in the input.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	end  int
+}
+
+// operators lists the operator spellings, longest first so that "**",
+// "==" and "!=" win over "*", "=" and "!".
+var operators = []string{
+	"**", "==", "!=", "<=", ">=", "&&", "||", "..",
+	"+", "-", "*", "/", "%", "^", "<", ">", "=", "!", "?", ":",
+}
+
+// lex splits the input into tokens. The returned slice always ends with a
+// tokenEOF.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case isDigit(r) || (r == '.' && i+1 < len(input) && isDigit(rune(input[i+1]))):
+			end := scanNumber(input, i)
+			tokens = append(tokens, token{kind: tokenNumber, text: input[i:end], pos: i, end: end})
+			i = end
+		case isIdentStart(r):
+			end := i + size
+			for end < len(input) {
+				r, size := utf8.DecodeRuneInString(input[end:])
+				if !isIdentStart(r) && !isDigit(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[i:end], pos: i, end: end})
+			i = end
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLparen, text: "(", pos: i, end: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRparen, text: ")", pos: i, end: i + 1})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{kind: tokenLbracket, text: "[", pos: i, end: i + 1})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{kind: tokenRbracket, text: "]", pos: i, end: i + 1})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i, end: i + 1})
+			i++
+		case r == ';':
+			tokens = append(tokens, token{kind: tokenSemicolon, text: ";", pos: i, end: i + 1})
+			i++
+		default:
+			op := matchOperator(input[i:])
+			if op == "" {
+				return nil, newSyntaxError(i, i+size, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i, end: i + len(op)})
+			i += len(op)
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(input), end: len(input)})
+	return tokens, nil
+}
+
+// scanNumber returns the end offset of the number starting at start. It
+// accepts an integer part, an optional fraction, an optional exponent and
+// an optional imaginary suffix i or j, as in 2.5i. A "." followed by
+// another is a range operator, so 1..10 is 1, "..", 10.
+func scanNumber(input string, start int) int {
+	i := start
+	for i < len(input) && isDigit(rune(input[i])) {
+		i++
+	}
+	if strings.HasPrefix(input[i:], "..") {
+		return i
+	}
+	if i < len(input) && input[i] == '.' {
+		i++
+		for i < len(input) && isDigit(rune(input[i])) {
+			i++
+		}
+	}
+	if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+		j := i + 1
+		if j < len(input) && (input[j] == '+' || input[j] == '-') {
+			j++
+		}
+		if j < len(input) && isDigit(rune(input[j])) {
+			for j < len(input) && isDigit(rune(input[j])) {
+				j++
+			}
+			i = j
+		}
+	}
+	if i < len(input) && (input[i] == 'i' || input[i] == 'j') {
+		// 2in is 2 followed by the identifier in, not an imaginary number
+		r, _ := utf8.DecodeRuneInString(input[i+1:])
+		if i+1 == len(input) || (!isIdentStart(r) && !isDigit(r)) {
+			i++
+		}
+	}
+	return i
+}
+
+// matchOperator returns the operator at the start of s, or "" if there is none.
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Lists are []interface{} values, written [1, 2, 3] or as a range 1..10.
+// They are passed to functions such as sum and mean, compare equal element
+// by element, and are not operands of the arithmetic operators.
+
+// maxRange is the largest number of values a range may produce.
+const maxRange = 1000000
+
+// isList reports whether any of values is a list.
+func isList(values ...interface{}) bool {
+	for _, v := range values {
+		if _, ok := v.([]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeOf returns the integers from lo to hi inclusive, counting down if hi
+// is less than lo, in the representation of the bounds.
+func rangeOf(lo, hi interface{}) (interface{}, error) {
+	a, ok1 := toFloat(lo)
+	b, ok2 := toFloat(hi)
+	if !ok1 || !ok2 {
+		return nil, &TypeError{Msg: "a range expects real bounds"}
+	}
+	if a != math.Trunc(a) || b != math.Trunc(b) {
+		return nil, &DomainError{Msg: "the bounds of a range must be integers"}
+	}
+	if math.Abs(b-a) >= maxRange {
+		return nil, &DomainError{Msg: fmt.Sprintf("a range may hold at most %d values", maxRange)}
+	}
+
+	step := int64(1)
+	if b < a {
+		step = -1
+	}
+	n := int64(math.Abs(b-a)) + 1
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = intLike(int64(a)+int64(i)*step, lo, hi)
+	}
+	return values, nil
+}
+
+// intLike returns n in the representation arithmetic on values would give:
+// a *big.Float if any of them is one, a *big.Rat if all of them are, and a
+// float64 otherwise.
+func intLike(n int64, values ...interface{}) interface{} {
+	if prec := bigPrec(values...); prec > 0 {
+		return bigInt(n, prec)
+	}
+	for _, v := range values {
+		if _, ok := v.(*big.Rat); !ok {
+			return float64(n)
+		}
+	}
+	return big.NewRat(n, 1)
+}
+
+// equalLists reports whether two lists have equal elements.
+func equalLists(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,379 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// The combinatorial and number-theoretic functions compute with big.Int, so
+// their results are exact however large. Their arguments must be integers,
+// in any representation. A result is returned in the representation of the
+// arguments: a *big.Rat in exact sessions, a *big.Float with enough
+// precision to hold it exactly in arbitrary-precision sessions, and
+// otherwise a float64 if it holds the result exactly, or else a *big.Rat.
+
+// Limits on the arguments of the functions whose cost grows with them.
+const (
+	maxFactorial = 100000
+	maxFibonacci = 1000000
+	// rhoIterations bounds each attempt of Pollard's rho method at finding
+	// a factor, so that factorint gives up on products of large primes.
+	rhoIterations = 1 << 18
+)
+
+// registerNumberTheory adds the combinatorial and number-theoretic
+// functions to r.
+func registerNumberTheory(r *Registry) {
+	mustRegister(r, "fact", 1, integers("fact", func(n []*big.Int) (interface{}, error) {
+		if n[0].Sign() < 0 {
+			return nil, &DomainError{Func: "fact", Msg: "factorial is not defined for negative numbers"}
+		}
+		if !n[0].IsInt64() || n[0].Int64() > maxFactorial {
+			return nil, &DomainError{Func: "fact", Msg: fmt.Sprintf("%s is too large, the limit is %d", n[0], maxFactorial)}
+		}
+		return new(big.Int).MulRange(1, n[0].Int64()), nil
+	}), "Factorial of a non-negative integer n.")
+	mustRegister(r, "nCr", 2, integers("nCr", func(n []*big.Int) (interface{}, error) {
+		if n[0].Sign() < 0 || n[1].Sign() < 0 {
+			return nil, &DomainError{Func: "nCr", Msg: "n and r must not be negative"}
+		}
+		return binomial(n[0], n[1])
+	}), "Number of ways to choose r of n items, regardless of order.")
+	mustRegister(r, "nPr", 2, integers("nPr", func(n []*big.Int) (interface{}, error) {
+		if n[0].Sign() < 0 || n[1].Sign() < 0 {
+			return nil, &DomainError{Func: "nPr", Msg: "n and r must not be negative"}
+		}
+		if n[1].Cmp(n[0]) > 0 {
+			return new(big.Int), nil
+		}
+		if !n[1].IsInt64() || n[1].Int64() > maxFactorial {
+			return nil, &DomainError{Func: "nPr", Msg: fmt.Sprintf("r = %s is too large, the limit is %d", n[1], maxFactorial)}
+		}
+		return fallingFactorial(n[0], n[1].Int64()), nil
+	}), "Number of ordered arrangements of r of n items.")
+	mustRegister(r, "binomial", 2, integers("binomial", func(n []*big.Int) (interface{}, error) {
+		if n[1].Sign() < 0 {
+			return nil, &DomainError{Func: "binomial", Msg: "k must not be negative"}
+		}
+		return binomial(n[0], n[1])
+	}), "Binomial coefficient of n and k for any integer n, n(n-1)...(n-k+1)/k!.")
+	mustRegisterVariadic(r, "gcd", 1, flatten(integers("gcd", func(n []*big.Int) (interface{}, error) {
+		g := new(big.Int)
+		for _, x := range n {
+			g.GCD(nil, nil, g, new(big.Int).Abs(x))
+		}
+		return g, nil
+	})), "Greatest common divisor of the integers or lists of them.")
+	mustRegisterVariadic(r, "lcm", 1, flatten(integers("lcm", func(n []*big.Int) (interface{}, error) {
+		l := big.NewInt(1)
+		for _, x := range n {
+			if x.Sign() == 0 {
+				return new(big.Int), nil
+			}
+			g := new(big.Int).GCD(nil, nil, l, new(big.Int).Abs(x))
+			l.Mul(l, new(big.Int).Abs(x)).Div(l, g)
+		}
+		return l, nil
+	})), "Least common multiple of the integers or lists of them.")
+	mustRegister(r, "mod", 2, integers("mod", func(n []*big.Int) (interface{}, error) {
+		if n[1].Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		return new(big.Int).Mod(n[0], n[1]), nil
+	}), "Remainder of a divided by n, from 0 to |n| - 1.")
+	mustRegister(r, "modpow", 3, integers("modpow", func(n []*big.Int) (interface{}, error) {
+		m := new(big.Int).Abs(n[2])
+		if m.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		p := new(big.Int).Exp(n[0], n[1], m)
+		if p == nil {
+			return nil, &DomainError{Func: "modpow", Msg: fmt.Sprintf("%s has no inverse modulo %s", n[0], m)}
+		}
+		return p.Mod(p, m), nil
+	}), "b raised to the power e modulo m; a negative e uses the inverse of b.")
+	mustRegister(r, "modinv", 2, integers("modinv", func(n []*big.Int) (interface{}, error) {
+		m := new(big.Int).Abs(n[1])
+		if m.Sign() == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		inv := new(big.Int).ModInverse(new(big.Int).Mod(n[0], m), m)
+		if inv == nil {
+			return nil, &DomainError{Func: "modinv", Msg: fmt.Sprintf("%s has no inverse modulo %s", n[0], m)}
+		}
+		return inv, nil
+	}), "Inverse of a modulo m: the x from 0 to |m| - 1 with a x = 1 (mod m).")
+	mustRegister(r, "isprime", 1, integers("isprime", func(n []*big.Int) (interface{}, error) {
+		return n[0].Sign() > 0 && n[0].ProbablyPrime(20), nil
+	}), "Whether n is prime. Exact below 2^64, and with negligible error above.")
+	mustRegister(r, "nextprime", 1, integers("nextprime", func(n []*big.Int) (interface{}, error) {
+		p := new(big.Int).Add(n[0], big.NewInt(1))
+		if p.Cmp(big.NewInt(2)) < 0 {
+			return big.NewInt(2), nil
+		}
+		for !p.ProbablyPrime(20) {
+			p.Add(p, big.NewInt(1))
+		}
+		return p, nil
+	}), "Smallest prime greater than n.")
+	mustRegister(r, "factorint", 1, integers("factorint", func(n []*big.Int) (interface{}, error) {
+		if n[0].Sign() <= 0 {
+			return nil, &DomainError{Func: "factorint", Msg: "only positive integers have a prime factorization"}
+		}
+		factors, err := factorize(n[0])
+		if err != nil {
+			return nil, err
+		}
+		pairs := make([]interface{}, len(factors))
+		for i, f := range factors {
+			pairs[i] = []interface{}{f.prime, big.NewInt(int64(f.power))}
+		}
+		return pairs, nil
+	}), "Prime factorization of n as a list of [prime, exponent] pairs.")
+	mustRegister(r, "fib", 1, integers("fib", func(n []*big.Int) (interface{}, error) {
+		if !n[0].IsInt64() || n[0].Int64() > maxFibonacci || n[0].Int64() < -maxFibonacci {
+			return nil, &DomainError{Func: "fib", Msg: fmt.Sprintf("%s is too large, the limit is %d", n[0], maxFibonacci)}
+		}
+		return fibonacci(n[0].Int64()), nil
+	}), "The n-th Fibonacci number, with fib(0) = 0 and fib(1) = 1.")
+}
+
+// integers adapts a function of integer arguments to a Function. Each
+// *big.Int in the result, which may be nested in lists, is converted to the
+// representation of the arguments.
+func integers(name string, f func(n []*big.Int) (interface{}, error)) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, &DomainError{Func: name, Msg: "needs at least 1 value"}
+		}
+		n := make([]*big.Int, len(args))
+		for i, arg := range args {
+			var err error
+			if n[i], err = toInt(name, arg); err != nil {
+				return nil, err
+			}
+		}
+		result, err := f(n)
+		if err != nil {
+			return nil, err
+		}
+		return fromInts(result, args), nil
+	}
+}
+
+// flatten adapts a variadic Function to take lists among its arguments in
+// place of their elements, so gcd([4, 6], 8) is gcd(4, 6, 8).
+func flatten(fn Function) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		var values []interface{}
+		for _, arg := range args {
+			if list, ok := arg.([]interface{}); ok {
+				values = append(values, list...)
+			} else {
+				values = append(values, arg)
+			}
+		}
+		return fn(values...)
+	}
+}
+
+// toInt converts an integer argument of the named function to a big.Int.
+func toInt(name string, x interface{}) (*big.Int, error) {
+	switch v := x.(type) {
+	case float64:
+		if !math.IsInf(v, 0) && !math.IsNaN(v) {
+			if f := big.NewFloat(v); f.IsInt() {
+				n, _ := f.Int(nil)
+				return n, nil
+			}
+		}
+	case *big.Rat:
+		if v.IsInt() {
+			return new(big.Int).Set(v.Num()), nil
+		}
+	case *big.Float:
+		if !v.IsInf() && v.IsInt() {
+			n, _ := v.Int(nil)
+			return n, nil
+		}
+	default:
+		return nil, &TypeError{Msg: fmt.Sprintf("%s expects integer arguments", name)}
+	}
+	return nil, &DomainError{Func: name, Msg: fmt.Sprintf("%s is not an integer", FormatValue(x))}
+}
+
+// fromInts converts the big.Ints in a result to the representation of the
+// arguments, as described at the top of this file.
+func fromInts(result interface{}, args []interface{}) interface{} {
+	switch v := result.(type) {
+	case *big.Int:
+		if prec := bigPrec(args...); prec > 0 {
+			return newFloat(max(prec, uint(v.BitLen()))).SetInt(v)
+		}
+		for _, arg := range args {
+			if _, ok := arg.(float64); ok && v.BitLen() <= 53 {
+				f, _ := new(big.Float).SetInt(v).Float64()
+				return f
+			}
+		}
+		return new(big.Rat).SetInt(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, x := range v {
+			values[i] = fromInts(x, args)
+		}
+		return values
+	}
+	return result
+}
+
+// binomial returns the binomial coefficient of n and k >= 0 for any integer
+// n, which is 0 for 0 <= n < k.
+func binomial(n, k *big.Int) (*big.Int, error) {
+	if n.Sign() >= 0 && k.Cmp(n) > 0 {
+		return new(big.Int), nil
+	}
+	// Use the smaller of k and n - k for non-negative n
+	if n.Sign() >= 0 {
+		if rest := new(big.Int).Sub(n, k); rest.Cmp(k) < 0 {
+			k = rest
+		}
+	}
+	if !k.IsInt64() || k.Int64() > maxFactorial {
+		return nil, &DomainError{Func: "binomial", Msg: fmt.Sprintf("k = %s is too large, the limit is %d", k, maxFactorial)}
+	}
+	c := fallingFactorial(n, k.Int64())
+	return c.Quo(c, new(big.Int).MulRange(1, k.Int64())), nil
+}
+
+// fallingFactorial returns n (n-1) ... (n-k+1).
+func fallingFactorial(n *big.Int, k int64) *big.Int {
+	p := big.NewInt(1)
+	f := new(big.Int).Set(n)
+	for i := int64(0); i < k; i++ {
+		p.Mul(p, f)
+		f.Sub(f, big.NewInt(1))
+	}
+	return p
+}
+
+// fibonacci returns the n-th Fibonacci number by fast doubling, using
+// F(-n) = (-1)^(n+1) F(n) for negative n.
+func fibonacci(n int64) *big.Int {
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+	// a, b = F(k), F(k+1) for the prefix k of the bits of n
+	a, b := new(big.Int), big.NewInt(1)
+	for bit := 62; bit >= 0; bit-- {
+		// F(2k) = F(k) (2 F(k+1) - F(k)), F(2k+1) = F(k)^2 + F(k+1)^2
+		t := new(big.Int).Lsh(b, 1)
+		t.Sub(t, a).Mul(t, a)
+		u := new(big.Int).Mul(a, a)
+		u.Add(u, new(big.Int).Mul(b, b))
+		a, b = t, u
+		if n>>uint(bit)&1 == 1 {
+			a, b = b, a.Add(a, b)
+		}
+	}
+	if neg && n%2 == 0 {
+		a.Neg(a)
+	}
+	return a
+}
+
+// primeFactor is a prime and its exponent in a factorization.
+type primeFactor struct {
+	prime *big.Int
+	power int
+}
+
+// factorize returns the prime factorization of n > 0 in increasing order of
+// the primes. Small factors are found by trial division and the rest with
+// Pollard's rho method.
+func factorize(n *big.Int) ([]primeFactor, error) {
+	var primes []*big.Int
+	rest := new(big.Int).Set(n)
+	for p := int64(2); p < 1000 && rest.Cmp(big.NewInt(1)) > 0; p++ {
+		d := big.NewInt(p)
+		for new(big.Int).Mod(rest, d).Sign() == 0 {
+			primes = append(primes, d)
+			rest.Quo(rest, d)
+		}
+	}
+
+	pending := []*big.Int{rest}
+	for len(pending) > 0 {
+		m := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		switch {
+		case m.Cmp(big.NewInt(1)) == 0:
+		case m.ProbablyPrime(20):
+			primes = append(primes, m)
+		default:
+			d := rho(m)
+			if d == nil {
+				return nil, &DomainError{Func: "factorint", Msg: fmt.Sprintf("could not factor %s, whose prime factors are all large", m)}
+			}
+			pending = append(pending, d, new(big.Int).Quo(m, d))
+		}
+	}
+
+	sort.Slice(primes, func(i, j int) bool { return primes[i].Cmp(primes[j]) < 0 })
+	var factors []primeFactor
+	for _, p := range primes {
+		if k := len(factors); k > 0 && factors[k-1].prime.Cmp(p) == 0 {
+			factors[k-1].power++
+			continue
+		}
+		factors = append(factors, primeFactor{prime: p, power: 1})
+	}
+	return factors, nil
+}
+
+// rho returns a non-trivial factor of the composite n by Pollard's rho
+// method with Brent's cycle detection, which takes the gcd of a product of
+// rhoBatch differences at a time, or nil if none is found within
+// rhoIterations steps.
+func rho(n *big.Int) *big.Int {
+	const rhoBatch = 128
+	one := big.NewInt(1)
+	for c := int64(1); c <= 3; c++ {
+		step := func(x *big.Int) {
+			x.Mul(x, x).Add(x, big.NewInt(c)).Mod(x, n)
+		}
+		x, y, ys := new(big.Int), big.NewInt(2), new(big.Int)
+		q, d, diff := big.NewInt(1), big.NewInt(1), new(big.Int)
+		for r, steps := 1, 0; d.Cmp(one) == 0 && steps < rhoIterations; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				step(y)
+			}
+			for k := 0; k < r && d.Cmp(one) == 0; k += rhoBatch {
+				ys.Set(y)
+				for i := 0; i < min(rhoBatch, r-k); i++ {
+					step(y)
+					q.Mul(q, diff.Abs(diff.Sub(x, y))).Mod(q, n)
+				}
+				d.GCD(nil, nil, q, n)
+			}
+			steps += 2 * r
+		}
+		if d.Cmp(n) == 0 {
+			// The batch met the cycle; retrace it a step at a time
+			for {
+				step(ys)
+				if d.GCD(nil, nil, diff.Abs(diff.Sub(x, ys)), n); d.Cmp(one) != 0 {
+					break
+				}
+			}
+		}
+		if d.Cmp(one) != 0 && d.Cmp(n) != 0 {
+			return d
+		}
+	}
+	return nil
+}
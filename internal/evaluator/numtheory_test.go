@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNumberTheory tests the combinatorial and number-theoretic functions
+func TestNumberTheory(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected string
+		hasError bool
+	}{
+		{"Factorial", "fact(5)", Options{}, "120", false},
+		{"Factorial of zero", "fact(0)", Options{}, "1", false},
+		{"Large factorial is exact", "fact(25)", Options{}, "15511210043330985984000000", false},
+		{"Factorial past float64", "fact(171) > 1e300", Options{}, "true", false},
+		{"Big float factorial", "fact(30)", Options{Precision: 40}, "265252859812191058636308480000000", false},
+		{"Combinations", "nCr(10, 3)", Options{}, "120", false},
+		{"Permutations", "nPr(10, 3)", Options{}, "720", false},
+		{"Choose more than n", "nCr(3, 5)", Options{}, "0", false},
+		{"Binomial of a negative n", "binomial(-3, 2)", Options{}, "6", false},
+		{"Gcd", "gcd(12, 18, 24)", Options{}, "6", false},
+		{"Lcm of a list", "lcm([4, 6, 10])", Options{}, "60", false},
+		{"Gcd of negatives", "gcd(-4, 6)", Options{}, "2", false},
+		{"Euclidean mod", "mod(-7, 3)", Options{}, "2", false},
+		{"Modular power", "modpow(3, 200, 1000007)", Options{}, "959082", false},
+		{"Large modular power", "modpow(2, 100, 1000000007)", Options{}, "976371285", false},
+		{"Large combinations", "nCr(50, 25)", Options{}, "126410606437752", false},
+		{"Combinations past float64", "nCr(100, 50)", Options{}, "100891344545564193334812497256", false},
+		{"Negative exponent", "modpow(3, -1, 7)", Options{}, "5", false},
+		{"Modular inverse", "modinv(3, 7)", Options{}, "5", false},
+		{"Prime", "isprime(97)", Options{}, "true", false},
+		{"Composite", "isprime(91)", Options{}, "false", false},
+		{"Large prime", "isprime(2^61 - 1)", Options{Exact: true}, "true", false},
+		{"Next prime", "nextprime(90)", Options{}, "97", false},
+		{"Factorization", "factorint(360)", Options{}, "[[2, 3], [3, 2], [5, 1]]", false},
+		{"Factorization of a prime", "factorint(97)", Options{}, "[[97, 1]]", false},
+		{"Large factorization", "factorint(2^64 + 1)", Options{Exact: true}, "[[274177, 1], [67280421310721, 1]]", false},
+		{"Fibonacci", "fib(100)", Options{}, "354224848179261915075", false},
+		{"Negative Fibonacci", "fib(-6)", Options{}, "-8", false},
+		{"Exact integers", "nCr(6, 2)", Options{Exact: true}, "15", false},
+		{"Integer-valued float", "fact(4.0)", Options{}, "24", false},
+		{"Non-integer factorial", "fact(3.5)", Options{}, "", true},
+		{"Negative factorial", "fact(-1)", Options{}, "", true},
+		{"Factorial too large", "fact(1e6)", Options{}, "", true},
+		{"Negative combinations", "nCr(-1, 2)", Options{}, "", true},
+		{"Zero modulus", "mod(5, 0)", Options{}, "", true},
+		{"No inverse", "modinv(2, 4)", Options{}, "", true},
+		{"Factorization of zero", "factorint(0)", Options{}, "", true},
+		{"Complex argument", "gcd(2i, 4)", Options{}, "", true},
+		{"Lists only for gcd and lcm", "fact([1, 2])", Options{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(tt.opts).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", FormatValue(result))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	var domainErr *DomainError
+	if _, err := Evaluate("fact(2.5)"); !errors.As(err, &domainErr) || domainErr.Func != "fact" {
+		t.Errorf("Expected a fact domain error, got %v", err)
+	}
+}
@@ -0,0 +1,518 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// Operator precedence levels, from loosest to tightest binding.
+const (
+	precLowest = iota
+	precOr
+	precAnd
+	precComparison
+	precRange
+	precAdditive
+	precMultiplicative
+	precUnary
+	precPower
+)
+
+// binaryPrecedence maps each infix operator to its precedence.
+var binaryPrecedence = map[string]int{
+	"||": precOr,
+	"&&": precAnd,
+	"==": precComparison,
+	"!=": precComparison,
+	"<":  precComparison,
+	"<=": precComparison,
+	">":  precComparison,
+	">=": precComparison,
+	"..": precRange,
+	"+":  precAdditive,
+	"-":  precAdditive,
+	"*":  precMultiplicative,
+	"/":  precMultiplicative,
+	"%":  precMultiplicative,
+	"^":  precPower,
+	"**": precPower,
+}
+
+// rightAssociative lists the infix operators that group right to left.
+var rightAssociative = map[string]bool{
+	"^":  true,
+	"**": true,
+}
+
+// conditionals are the names of the functions that are parsed as
+// conditional expressions, so that only the chosen branch is evaluated.
+var conditionals = map[string]bool{
+	"if":        true,
+	"piecewise": true,
+}
+
+// parser is a precedence-climbing parser over the tokens of one input.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses an input of ";"-separated statements into a Program.
+// Empty statements are dropped. Malformed input is reported as a
+// *SyntaxError.
+func Parse(input string) (*Program, error) {
+	program, err := parse(input)
+	if err != nil {
+		return nil, locate(err, input)
+	}
+	return program, nil
+}
+
+// Incomplete reports whether input ends in the middle of a statement, as
+// with an unclosed parenthesis or a trailing operator, so an interactive
+// caller should read another line before evaluating it.
+func Incomplete(input string) bool {
+	_, err := parse(input)
+	var syntaxErr *SyntaxError
+	return errors.As(err, &syntaxErr) && syntaxErr.pos == len(input)
+}
+
+// parse is Parse without the error positions resolved to columns.
+func parse(input string) (*Program, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	program := &Program{}
+	for {
+		switch p.peek().kind {
+		case tokenEOF:
+			return program, nil
+		case tokenSemicolon:
+			p.next()
+			continue
+		}
+
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		program.Statements = append(program.Statements, stmt)
+
+		switch tok := p.peek(); tok.kind {
+		case tokenEOF, tokenSemicolon:
+		default:
+			return nil, p.unexpected(tok)
+		}
+	}
+}
+
+// parseStatement parses an assignment, a function definition or an
+// expression. The left-hand side of "=" is parsed as an expression first and
+// must turn out to be a name or a call with names as arguments.
+func (p *parser) parseStatement() (Node, error) {
+	target, err := p.parseExpr(precLowest)
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokenOperator || tok.text != "=" {
+		return target, nil
+	}
+	p.next()
+
+	value, err := p.parseExpr(precLowest)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := target.(type) {
+	case *Ident:
+		return &AssignStmt{Name: t, Value: value}, nil
+
+	case *CallExpr:
+		def := &FuncDef{Name: t.Name, Body: value}
+		seen := make(map[string]bool, len(t.Args))
+		for _, arg := range t.Args {
+			param, ok := arg.(*Ident)
+			if !ok {
+				return nil, newSyntaxError(arg.Pos(), arg.End(), "function parameters must be names")
+			}
+			if seen[param.Name] {
+				return nil, newSyntaxError(arg.Pos(), arg.End(), "duplicate parameter %s", param.Name)
+			}
+			seen[param.Name] = true
+			def.Params = append(def.Params, param)
+		}
+		return def, nil
+	}
+	return nil, newSyntaxError(target.Pos(), target.End(), "cannot assign to this expression")
+}
+
+// parseExpr parses an expression whose infix operators all bind at least as
+// tightly as minPrec. The conditional operator c ? a : b binds loosest of
+// all and groups right to left.
+func (p *parser) parseExpr(minPrec int) (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOperator {
+			return left, nil
+		}
+		if tok.text == "?" {
+			if minPrec > precLowest {
+				return left, nil
+			}
+			if left, err = p.parseTernary(left); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		prec, ok := binaryPrecedence[tok.text]
+		if !ok || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+
+		next := prec + 1
+		if rightAssociative[tok.text] {
+			next = prec
+		}
+		right, err := p.parseExpr(next)
+		if err != nil {
+			return nil, err
+		}
+
+		op := tok.text
+		if op == "**" {
+			op = "^"
+		}
+		left = &BinaryExpr{Op: op, OpPos: tok.pos, X: left, Y: right}
+	}
+}
+
+// parseTernary parses the branches of c ? a : b after the condition.
+func (p *parser) parseTernary(cond Node) (Node, error) {
+	p.next()
+	then, err := p.parseExpr(precLowest)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenOperator, ":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpr(precLowest)
+	if err != nil {
+		return nil, err
+	}
+	return &CondExpr{
+		Conds:  []Node{cond},
+		Values: []Node{then},
+		Else:   otherwise,
+		From:   cond.Pos(),
+		To:     otherwise.End(),
+	}, nil
+}
+
+// parseUnary parses a prefix "+", "-" or "!", which binds looser than "^"
+// so that -2^2 is -(2^2).
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "-" || tok.text == "+" || tok.text == "!") {
+		p.next()
+		x, err := p.parseExpr(precUnary)
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: tok.text, OpPos: tok.pos, X: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a literal, variable, function call, list or
+// parenthesised expression.
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		text := strings.TrimRight(tok.text, "ij")
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, newSyntaxError(tok.pos, tok.end, "invalid number %q", tok.text)
+		}
+		return p.parseQuantity(&NumberLit{Text: text, Value: value, Imaginary: text != tok.text, From: tok.pos, To: tok.end})
+
+	case tokenIdent:
+		ident := &Ident{Name: tok.text, From: tok.pos, To: tok.end}
+		if p.peek().kind == tokenLparen && ident.Name == "to" {
+			return p.parseConvert(ident)
+		}
+		if p.peek().kind == tokenLparen {
+			call, err := p.parseCall(ident)
+			if err != nil || !conditionals[ident.Name] {
+				return call, err
+			}
+			return conditional(call.(*CallExpr))
+		}
+		if tok.text == "true" || tok.text == "false" {
+			return &BoolLit{Value: tok.text == "true", From: tok.pos, To: tok.end}, nil
+		}
+		return ident, nil
+
+	case tokenLparen:
+		x, err := p.parseExpr(precLowest)
+		if err != nil {
+			return nil, err
+		}
+		rparen, err := p.expect(tokenRparen, ")")
+		if err != nil {
+			return nil, err
+		}
+		return p.parseQuantity(&ParenExpr{Lparen: tok.pos, X: x, Rparen: rparen.pos})
+
+	case tokenLbracket:
+		return p.parseList(tok)
+	}
+	return nil, p.unexpected(tok)
+}
+
+// parseList parses the elements of a list literal after its "[".
+func (p *parser) parseList(lbrack token) (Node, error) {
+	list := &ListExpr{Lbrack: lbrack.pos}
+	if p.peek().kind == tokenRbracket {
+		list.Rbrack = p.next().pos
+		return list, nil
+	}
+	for {
+		elem, err := p.parseExpr(precLowest)
+		if err != nil {
+			return nil, err
+		}
+		list.Elems = append(list.Elems, elem)
+
+		tok := p.next()
+		switch tok.kind {
+		case tokenComma:
+			continue
+		case tokenRbracket:
+			list.Rbrack = tok.pos
+			return list, nil
+		}
+		return nil, p.unexpected(tok)
+	}
+}
+
+// parseQuantity parses the unit, if any, written after a number or a
+// parenthesised expression x, as in 5 km or (1 + 2) m/s.
+func (p *parser) parseQuantity(x Node) (Node, error) {
+	if !p.atUnit(0) {
+		return x, nil
+	}
+	unit, err := p.parseUnit()
+	if err != nil {
+		return nil, err
+	}
+	return &QuantityExpr{X: x, Unit: unit}, nil
+}
+
+// parseConvert parses the arguments of to(x, unit), whose second argument
+// is a unit rather than an expression.
+func (p *parser) parseConvert(name *Ident) (Node, error) {
+	p.next()
+	x, err := p.parseExpr(precLowest)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenComma, ","); err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); !p.atUnit(0) {
+		if tok.kind == tokenIdent {
+			return nil, newSyntaxError(tok.pos, tok.end, "unknown unit: %s", tok.text)
+		}
+		return nil, p.unexpected(p.next())
+	}
+	unit, err := p.parseUnit()
+	if err != nil {
+		return nil, err
+	}
+	rparen, err := p.expect(tokenRparen, ")")
+	if err != nil {
+		return nil, err
+	}
+	return &ConvertExpr{X: x, Unit: unit, From: name.Pos(), To: rparen.end}, nil
+}
+
+// parseUnit parses a unit: the names of units separated by spaces, "*" or
+// "/", each optionally raised to an integer power, as in kg m^2/s^2. A "*"
+// or "/" is part of the unit only when the name of a unit follows it, so
+// 10 m / 2 s is a quotient of two quantities.
+func (p *parser) parseUnit() (*UnitLit, error) {
+	lit := &UnitLit{Unit: units.One, From: p.peek().pos}
+	for first := true; ; first = false {
+		divide := false
+		if !first {
+			tok := p.peek()
+			switch {
+			case p.atUnit(0):
+			case tok.kind == tokenOperator && (tok.text == "*" || tok.text == "/") && p.atUnit(1):
+				divide = tok.text == "/"
+				p.next()
+			default:
+				return lit, nil
+			}
+		}
+
+		name := p.next()
+		unit, _ := units.Lookup(name.text)
+		lit.Names = append(lit.Names, &Ident{Name: name.text, From: name.pos, To: name.end})
+		lit.To = name.end
+		power := 1
+		if tok := p.peek(); tok.kind == tokenOperator && tok.text == "^" {
+			p.next()
+			sign := 1
+			if tok := p.peek(); tok.kind == tokenOperator && tok.text == "-" {
+				p.next()
+				sign = -1
+			}
+			num := p.next()
+			n, err := strconv.Atoi(num.text)
+			if num.kind != tokenNumber || err != nil {
+				return nil, newSyntaxError(num.pos, num.end, "the power of a unit must be an integer")
+			}
+			power = sign * n
+			lit.To = num.end
+		}
+		if divide {
+			power = -power
+		}
+
+		unit, err := unit.Pow(power)
+		if err == nil {
+			lit.Unit, err = lit.Unit.Mul(unit)
+		}
+		if err != nil {
+			return nil, newSyntaxError(name.pos, lit.To, "%s", err)
+		}
+	}
+}
+
+// atUnit reports whether the token n positions ahead is the name of a unit
+// rather than of a function being called.
+func (p *parser) atUnit(n int) bool {
+	tok := p.peekAt(n)
+	if tok.kind != tokenIdent || p.peekAt(n+1).kind == tokenLparen {
+		return false
+	}
+	_, ok := units.Lookup(tok.text)
+	return ok
+}
+
+// parseCall parses the parenthesised argument list of a call to name.
+func (p *parser) parseCall(name *Ident) (Node, error) {
+	p.next()
+	call := &CallExpr{Name: name}
+	if p.peek().kind == tokenRparen {
+		call.Rparen = p.next().pos
+		return call, nil
+	}
+	for {
+		arg, err := p.parseExpr(precLowest)
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+
+		tok := p.next()
+		switch tok.kind {
+		case tokenComma:
+			continue
+		case tokenRparen:
+			call.Rparen = tok.pos
+			return call, nil
+		}
+		return nil, p.unexpected(tok)
+	}
+}
+
+// conditional converts a call to if or piecewise into a CondExpr.
+func conditional(call *CallExpr) (Node, error) {
+	name, args := call.Name.Name, call.Args
+	if name == "if" && len(args) != 3 {
+		return nil, withSpan(&ArityError{Func: name, Min: 3, Max: 3, Got: len(args)}, call)
+	}
+	if name == "piecewise" && len(args) < 2 {
+		return nil, withSpan(&ArityError{Func: name, Min: 2, Max: -1, Got: len(args)}, call)
+	}
+
+	cond := &CondExpr{From: call.Pos(), To: call.End()}
+	for ; len(args) >= 2; args = args[2:] {
+		cond.Conds = append(cond.Conds, args[0])
+		cond.Values = append(cond.Values, args[1])
+	}
+	if len(args) == 1 {
+		cond.Else = args[0]
+	}
+	return cond, nil
+}
+
+// expect consumes the next token if it has the given kind, and for
+// operators the given text.
+func (p *parser) expect(kind tokenKind, text string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind || kind == tokenOperator && tok.text != text {
+		if tok.kind == tokenEOF {
+			return tok, newSyntaxError(tok.pos, tok.end, "expected %q, found end of input", text)
+		}
+		return tok, newSyntaxError(tok.pos, tok.end, "expected %q, found %q", text, tok.text)
+	}
+	return tok, nil
+}
+
+// unexpected reports tok as out of place.
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == tokenEOF {
+		return newSyntaxError(tok.pos, tok.end, "unexpected end of input")
+	}
+	return newSyntaxError(tok.pos, tok.end, "unexpected %q", tok.text)
+}
+
+// peek returns the next token without consuming it.
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+// peekAt returns the token n positions ahead without consuming anything.
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+// next consumes and returns the next token. The final EOF token is never
+// consumed.
+func (p *parser) next() token {
+	tok := p.peek()
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return tok
+}
+
+// newSyntaxError returns a *SyntaxError for the byte range [pos, end).
+func newSyntaxError(pos, end int, format string, args ...interface{}) *SyntaxError {
+	err := &SyntaxError{Msg: fmt.Sprintf(format, args...)}
+	err.at(pos, end)
+	return err
+}
@@ -0,0 +1,151 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+// TestParsePrecedence tests operator precedence and associativity
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"Multiplication before addition", "2 + 3 * 4", 14.0},
+		{"Parentheses", "(2 + 3) * 4", 20.0},
+		{"Left-associative subtraction", "10 - 4 - 3", 3.0},
+		{"Left-associative division", "64 / 4 / 2", 8.0},
+		{"Right-associative exponent", "2 ^ 3 ^ 2", 512.0},
+		{"Double-star exponent", "2 ** 3", 8.0},
+		{"Unary minus binds looser than exponent", "-2 ^ 2", -4.0},
+		{"Negative exponent", "2 ^ -1", 0.5},
+		{"Modulo", "7 % 3", 1.0},
+		{"Scientific notation", "1.5e3 + .5", 1500.5},
+		{"Comparison below arithmetic", "1 + 2 == 3", true},
+		{"Less or equal", "2 <= 3", true},
+		{"Greater", "2 > 3", false},
+		{"Not equal", "2 != 3", true},
+		{"Exponent in assignment", "P = 2 ^ 4; P", 16.0},
+		{"Comparison after assignment", "Q = 5; Q == 5", true},
+		{"Comparison in assignment", "R = 3 <= 4; R", true},
+		{"Trailing semicolon", "S = 1; S + 1;", 2.0},
+		{"And before or", "true || false && false", true},
+		{"Comparison before and", "1 < 2 && 3 < 2", false},
+		{"Not binds tighter than comparison", "!0 == true", true},
+		{"Conditional below or", "false || true ? 1 : 2", 1.0},
+		{"Right-associative conditional", "5 > 9 ? 1 : 5 > 3 ? 2 : 3", 2.0},
+		{"Conditional in assignment", "T = 1 > 0 ? 4 : 5; T", 4.0},
+		{"Conditional in arguments", "max(1 > 2 ? 1 : 2, 0)", 2.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// TestParseErrors tests that malformed input is rejected
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Dangling operator", "5 +"},
+		{"Double operator", "5 + * 3"},
+		{"Unclosed parenthesis", "(1 + 2"},
+		{"Unopened parenthesis", "1 + 2)"},
+		{"Assignment to literal", "5 = 3"},
+		{"Assignment inside expression", "1 + A = 2"},
+		{"Missing argument", "max(1, )"},
+		{"Unknown character", "2 # 3"},
+		{"Adjacent operands", "2 3"},
+		{"Conditional without else", "1 ? 2"},
+		{"Conditional with wrong separator", "1 ? 2 , 3"},
+		{"If with two arguments", "if(1, 2)"},
+		{"Piecewise with one argument", "piecewise(1)"},
+		{"Assignment to boolean", "true = 1"},
+		{"Unclosed list", "[1, 2"},
+		{"Missing list element", "[1, , 2]"},
+		{"Mismatched brackets", "[1, 2)"},
+		{"Range without end", "1.."},
+		{"Single dot", "1 . 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("Expected an error, but got none")
+			}
+		})
+	}
+}
+
+// TestParsePositions tests the source positions recorded in the syntax tree
+func TestParsePositions(t *testing.T) {
+	program, err := Parse("A = 1; max(A, 2) + 3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(program.Statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(program.Statements))
+	}
+
+	assign, ok := program.Statements[0].(*AssignStmt)
+	if !ok {
+		t.Fatalf("Expected *AssignStmt, got %T", program.Statements[0])
+	}
+	if assign.Pos() != 0 || assign.End() != 5 {
+		t.Errorf("Expected assignment at [0, 5), got [%d, %d)", assign.Pos(), assign.End())
+	}
+
+	sum, ok := program.Statements[1].(*BinaryExpr)
+	if !ok {
+		t.Fatalf("Expected *BinaryExpr, got %T", program.Statements[1])
+	}
+	if sum.Op != "+" || sum.OpPos != 17 {
+		t.Errorf("Expected + at 17, got %s at %d", sum.Op, sum.OpPos)
+	}
+	call, ok := sum.X.(*CallExpr)
+	if !ok {
+		t.Fatalf("Expected *CallExpr, got %T", sum.X)
+	}
+	if call.Pos() != 7 || call.End() != 16 || len(call.Args) != 2 {
+		t.Errorf("Expected call at [7, 16) with 2 args, got [%d, %d) with %d", call.Pos(), call.End(), len(call.Args))
+	}
+}
+
+// TestIncomplete tests the detection of input that continues on another line
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input      string
+		incomplete bool
+	}{
+		{"1 + 2", false},
+		{"(1 + 2", true},
+		{"max(1,", true},
+		{"2 *", true},
+		{"f(x) =", true},
+		{"A > 0 ?", true},
+		{"A > 0 ? 1 :", true},
+		{"A &&", true},
+		{"[1, 2", true},
+		{"1..", true},
+		{"A = 1;", false},
+		{"1 + )", false},
+		{"2 $", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := Incomplete(tt.input); got != tt.incomplete {
+			t.Errorf("Incomplete(%q): expected %v, got %v", tt.input, tt.incomplete, got)
+		}
+	}
+}
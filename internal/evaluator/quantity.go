@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// Quantities are units.Quantity values whatever the session's mode, so an
+// exact or arbitrary-precision number given a unit is converted to float64.
+// Quantities take precedence over the other kinds of number: a number
+// combined with a quantity is a dimensionless quantity, and a quantity
+// whose units cancel is reduced to a float64.
+
+// isQuantity reports whether any of values is a quantity.
+func isQuantity(values ...interface{}) bool {
+	for _, v := range values {
+		if _, ok := v.(units.Quantity); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// toQuantity converts a quantity or a real number to a quantity.
+func toQuantity(x interface{}) (units.Quantity, bool) {
+	if q, ok := x.(units.Quantity); ok {
+		return q, true
+	}
+	f, ok := toFloat(x)
+	return units.New(f, units.One), ok
+}
+
+// fromQuantity returns q, or its value if it is dimensionless.
+func fromQuantity(q units.Quantity) interface{} {
+	if q.Unit.Dimensionless() {
+		return q.Value
+	}
+	return q
+}
+
+// quantityOp applies an arithmetic or ordering operator to two quantities.
+// Addition, subtraction and ordering need quantities of the same dimension.
+func quantityOp(op string, a, b units.Quantity) (interface{}, error) {
+	var (
+		q   units.Quantity
+		err error
+	)
+	switch op {
+	case "+":
+		q, err = units.Add(a, b)
+	case "-":
+		q, err = units.Sub(a, b)
+	case "*":
+		q, err = units.Mul(a, b)
+	case "/":
+		if b.Value == 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		q, err = units.Div(a, b)
+	case "^":
+		if !b.Unit.Dimensionless() || b.Value != math.Trunc(b.Value) || math.Abs(b.Value) > 1<<20 {
+			return nil, &TypeError{Msg: "a quantity can only be raised to an integer power"}
+		}
+		if a.Value == 0 && b.Value < 0 {
+			return nil, &DivisionByZeroError{}
+		}
+		q, err = units.Pow(a, int(b.Value))
+	case "<", "<=", ">", ">=":
+		c, err := units.Compare(a, b)
+		if err != nil {
+			return nil, &TypeError{Msg: err.Error()}
+		}
+		return compare(op, c), nil
+	default:
+		return nil, &TypeError{Msg: fmt.Sprintf("operator %s is not defined for quantities", op)}
+	}
+	if err != nil {
+		return nil, &TypeError{Msg: err.Error()}
+	}
+	return fromQuantity(q), nil
+}
+
+// compare applies an ordering operator to the result of a comparison
+// function, which is negative, zero or positive.
+func compare(op string, c int) bool {
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// convert expresses a quantity in another unit.
+func convert(x interface{}, unit units.Unit) (interface{}, error) {
+	q, ok := x.(units.Quantity)
+	if !ok {
+		if _, ok := toFloat(x); !ok {
+			return nil, &TypeError{Msg: "to expects a quantity"}
+		}
+		return nil, &TypeError{Msg: fmt.Sprintf("cannot convert a number without a unit to %s", unit)}
+	}
+	q, err := units.Convert(q, unit)
+	if err != nil {
+		return nil, &TypeError{Msg: err.Error()}
+	}
+	return q, nil
+}
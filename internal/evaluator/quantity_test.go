@@ -0,0 +1,123 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestQuantities tests numbers with units and conversions between units
+func TestQuantities(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"Quantity", "5 km", "5 km", false},
+		{"Addition converts to the left unit", "5 km + 300 m", "5.3 km", false},
+		{"Units cancel", "9.81 m/s^2 * 3 s", "29.43 m/s", false},
+		{"Quotient of quantities", "10 m / 2 s", "5 m/s", false},
+		{"Dimensionless quotient", "5 km / 500 m", "10", false},
+		{"Derived unit", "2 kg * 3 m/s^2", "6 N", false},
+		{"Same dimension merges", "2 km * 500 m", "1 km^2", false},
+		{"Power", "(3 m)^2", "9 m^2", false},
+		{"Unit after parentheses", "(1 + 2) m", "3 m", false},
+		{"Negative quantity", "-(2 s)", "-2 s", false},
+		{"Scaling", "2 * 3 m", "6 m", false},
+		{"Comparison", "5 min > 200 s", "true", false},
+		{"Equality across units", "3 ft == 1 yd", "true", false},
+		{"Quantity in a variable", "d = 5 km; t = 20 min; to(d / t, km/hr)", "15 km/hr", false},
+		{"Conversion", "to(1 mi, km)", "1.609344 km", false},
+		{"Compound conversion", "to(1 kW * 2 hr, kWh)", "2 kWh", false},
+		{"Temperature", "to(-40 degC, degF) == -40 degF", "true", false},
+		{"Exact numbers get units", "1/4 m", "0.25 m^-1", false},
+		{"Unit names are variables elsewhere", "m = 2; m * 3 km", "6 km", false},
+		{"Incompatible addition", "1 m + 1 s", "", true},
+		{"Number plus quantity", "1 + 1 m", "", true},
+		{"Incompatible conversion", "to(1 m, s)", "", true},
+		{"Conversion of a number", "to(3, m)", "", true},
+		{"Offset units do not add", "20 degC + 5 degC", "", true},
+		{"Non-integer power", "(2 m)^0.5", "", true},
+		{"Remainder", "5 m % 2 m", "", true},
+		{"Division by zero", "1 m / 0 s", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, but got %v", FormatValue(result))
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestUnitSyntax tests where units are recognised and how unit errors are
+// located
+func TestUnitSyntax(t *testing.T) {
+	if _, err := New(Options{}).Evaluate("C + 5"); !errors.As(err, new(*UndefinedVariableError)) {
+		t.Errorf("Expected a unit name on its own to be an undefined variable, got %v", err)
+	}
+	if _, err := Parse("2 x"); err == nil {
+		t.Error("Expected a name that is not a unit to be rejected after a number")
+	}
+
+	var syntaxErr *SyntaxError
+	_, err := Parse("to(1 m, furlong)")
+	if !errors.As(err, &syntaxErr) || syntaxErr.Token != "furlong" {
+		t.Errorf("Expected an unknown unit error at furlong, got %v", err)
+	}
+	_, err = Parse("5 m^x")
+	if !errors.As(err, &syntaxErr) || syntaxErr.Token != "x" {
+		t.Errorf("Expected an error at the power x, got %v", err)
+	}
+	if !Incomplete("5 m/s^") {
+		t.Error("Expected a unit without its power to be incomplete")
+	}
+}
+
+// TestAmbiguousUnit tests that a unit after a number is rejected when a
+// variable or parameter of the same name is in scope
+func TestAmbiguousUnit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		token string
+	}{
+		{"Variable", "A = 5; 2 A", "A"},
+		{"Compound unit", "s = 2; 10 m/s", "s"},
+		{"Parameter", "f(m) = 3 m; f(2)", "f(2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Options{}).Exec(tt.input)
+			var ambiguous *AmbiguousUnitError
+			if !errors.As(err, &ambiguous) || ambiguous.Token != tt.token {
+				t.Errorf("Expected an ambiguous unit error at %s, got %v", tt.token, err)
+			}
+		})
+	}
+
+	// Multiplying explicitly uses the variable, and other names stay units
+	results, err := New(Options{}).Exec("A = 5; 2 * A; 3 m")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := FormatValue(results[1].Value); got != "10" {
+		t.Errorf("Expected 2 * A = 10, got %s", got)
+	}
+	if got := FormatValue(results[2].Value); got != "3 m" {
+		t.Errorf("Expected 3 m, got %s", got)
+	}
+}
@@ -0,0 +1,139 @@
+package evaluator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Function is the Go implementation of a function callable from
+// expressions. It is only called with a number of arguments its
+// registration accepts.
+type Function func(args ...interface{}) (interface{}, error)
+
+// Variadic is the arity of a function that accepts any number of arguments.
+const Variadic = -1
+
+// Entry is a function registered in a Registry.
+type Entry struct {
+	Name string
+	// MinArgs and MaxArgs bound the number of arguments the function
+	// accepts. MaxArgs is Variadic when there is no upper bound.
+	MinArgs, MaxArgs int
+	// Doc is a one-line description of the function.
+	Doc string
+	Fn  Function
+}
+
+// Call checks the number of arguments and calls the function.
+func (en *Entry) Call(args ...interface{}) (interface{}, error) {
+	if len(args) < en.MinArgs || (en.MaxArgs != Variadic && len(args) > en.MaxArgs) {
+		return nil, &ArityError{Func: en.Name, Min: en.MinArgs, Max: en.MaxArgs, Got: len(args)}
+	}
+	return en.Fn(args...)
+}
+
+// Arity describes the accepted number of arguments, such as "2" or "1+".
+func (en *Entry) Arity() string {
+	switch {
+	case en.MaxArgs == Variadic:
+		return strconv.Itoa(en.MinArgs) + "+"
+	case en.MinArgs == en.MaxArgs:
+		return strconv.Itoa(en.MinArgs)
+	}
+	return fmt.Sprintf("%d-%d", en.MinArgs, en.MaxArgs)
+}
+
+// Registry is a table of functions callable from expressions. It is safe
+// for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*Entry
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*Entry)}
+}
+
+// Builtins returns a copy of the registry of built-in functions, to be
+// extended and passed in Options.Registry.
+func Builtins() *Registry {
+	return builtins.Clone()
+}
+
+// Register adds fn under name, replacing any function already registered
+// with that name. arity is the exact number of arguments fn takes, or
+// Variadic for any number.
+func (r *Registry) Register(name string, arity int, fn Function, doc string) error {
+	if arity == Variadic {
+		return r.RegisterVariadic(name, 0, fn, doc)
+	}
+	if arity < 0 {
+		return fmt.Errorf("invalid arity %d for function %s", arity, name)
+	}
+	return r.add(&Entry{Name: name, MinArgs: arity, MaxArgs: arity, Doc: doc, Fn: fn})
+}
+
+// RegisterVariadic adds fn under name as a function taking minArgs or more
+// arguments.
+func (r *Registry) RegisterVariadic(name string, minArgs int, fn Function, doc string) error {
+	if minArgs < 0 {
+		return fmt.Errorf("invalid minimum arity %d for function %s", minArgs, name)
+	}
+	return r.add(&Entry{Name: name, MinArgs: minArgs, MaxArgs: Variadic, Doc: doc, Fn: fn})
+}
+
+// add validates and stores an entry.
+func (r *Registry) add(en *Entry) error {
+	if !isIdentifier(en.Name) {
+		return fmt.Errorf("invalid function name %q", en.Name)
+	}
+	if en.Fn == nil {
+		return fmt.Errorf("function %s has no implementation", en.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[en.Name] = en
+	return nil
+}
+
+// Lookup returns the function registered under name.
+func (r *Registry) Lookup(name string) (*Entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	en, ok := r.entries[name]
+	return en, ok
+}
+
+// Entries returns the registered functions sorted by name.
+func (r *Registry) Entries() []*Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]*Entry, 0, len(r.entries))
+	for _, en := range r.entries {
+		entries = append(entries, en)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Clone returns an independent copy of the registry.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c := &Registry{entries: make(map[string]*Entry, len(r.entries))}
+	for name, en := range r.entries {
+		c.entries[name] = en
+	}
+	return c
+}
+
+// isIdentifier reports whether name can be written in an expression as a
+// function name.
+func isIdentifier(name string) bool {
+	tokens, err := lex(name)
+	return err == nil && len(tokens) == 2 && tokens[0].kind == tokenIdent && tokens[0].text == name
+}
@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRegister tests registering functions with fixed and variable arity
+func TestRegister(t *testing.T) {
+	clamp := func(args ...interface{}) (interface{}, error) {
+		x, lo, hi := args[0].(float64), args[1].(float64), args[2].(float64)
+		if x < lo {
+			return lo, nil
+		}
+		if x > hi {
+			return hi, nil
+		}
+		return x, nil
+	}
+	sum := func(args ...interface{}) (interface{}, error) {
+		total := 0.0
+		for _, arg := range args {
+			total += arg.(float64)
+		}
+		return total, nil
+	}
+
+	registry := Builtins()
+	if err := registry.Register("clamp", 3, clamp, "x limited to [lo, hi]."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := registry.RegisterVariadic("total", 1, sum, "Sum of the arguments."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	e := New(Options{Registry: registry})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+		hasError bool
+	}{
+		{"Fixed arity", "clamp(15, 0, 10)", 10.0, false},
+		{"Variadic with one argument", "total(4)", 4.0, false},
+		{"Variadic with many arguments", "total(1, 2, 3, 4)", 10.0, false},
+		{"Built-ins still available", "sqrt(total(9, 7))", 4.0, false},
+		{"Too few arguments", "clamp(1, 2)", nil, true},
+		{"Below variadic minimum", "total()", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := e.Evaluate(tt.input)
+			if tt.hasError {
+				var arity *ArityError
+				if !errors.As(err, &arity) {
+					t.Errorf("Expected an arity error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// TestRegisterValidation tests that invalid registrations are rejected
+func TestRegisterValidation(t *testing.T) {
+	noop := func(args ...interface{}) (interface{}, error) { return 0.0, nil }
+	registry := NewRegistry()
+
+	tests := []struct {
+		name  string
+		fname string
+		arity int
+		fn    Function
+	}{
+		{"Empty name", "", 1, noop},
+		{"Name with operator", "a+b", 1, noop},
+		{"Name starting with digit", "2f", 1, noop},
+		{"Negative arity", "f", -2, noop},
+		{"Missing implementation", "f", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.Register(tt.fname, tt.arity, tt.fn, ""); err == nil {
+				t.Errorf("Expected an error, but got none")
+			}
+		})
+	}
+	if n := len(registry.Entries()); n != 0 {
+		t.Errorf("Expected no entries after failed registrations, got %d", n)
+	}
+}
+
+// TestSessionOverride tests that functions registered on a session stay in
+// that session
+func TestSessionOverride(t *testing.T) {
+	half := func(args ...interface{}) (interface{}, error) { return args[0].(float64) / 2, nil }
+
+	a := New(Options{})
+	b := New(Options{})
+	if err := a.Register("sqrt", 1, half, "Not really a square root."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result, _ := a.Evaluate("sqrt(16)"); result != 8.0 {
+		t.Errorf("Expected the override to return %v, got %v", 8.0, result)
+	}
+	if result, _ := b.Evaluate("sqrt(16)"); result != 4.0 {
+		t.Errorf("Expected the built-in to return %v, got %v", 4.0, result)
+	}
+	if result, _ := a.Clone().Evaluate("sqrt(16)"); result != 8.0 {
+		t.Errorf("Expected the clone to keep the override, got %v", result)
+	}
+	if en, _ := builtins.Lookup("sqrt"); en.Doc == "Not really a square root." {
+		t.Errorf("Session registration changed the built-in registry")
+	}
+}
+
+// TestBuiltinDocs tests that every built-in is documented
+func TestBuiltinDocs(t *testing.T) {
+	for _, en := range Builtins().Entries() {
+		if en.Doc == "" {
+			t.Errorf("Built-in %s has no documentation", en.Name)
+		}
+	}
+}
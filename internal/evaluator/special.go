@@ -0,0 +1,223 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// The special functions are computed in double precision. gamma of a
+// positive integer is exact in exact and arbitrary-precision sessions, as
+// fact is; other arguments in those sessions are converted to float64.
+
+// maxBesselOrder limits the order of the Bessel functions, whose cost grows
+// with it.
+const maxBesselOrder = 1 << 16
+
+// registerSpecial adds the special functions to r.
+func registerSpecial(r *Registry) {
+	mustRegister(r, "gamma", 1, gammaFunc.function(), "Gamma function of x, which is (x-1)! for positive integers.")
+	mustRegister(r, "lgamma", 1, unary{
+		name: "lgamma",
+		float: func(x float64) (interface{}, error) {
+			if isPole(x) {
+				return nil, poleError("lgamma")
+			}
+			l, _ := math.Lgamma(x)
+			return l, nil
+		},
+	}.function(), "Natural logarithm of the absolute value of gamma(x).")
+	mustRegister(r, "beta", 2, func(args ...interface{}) (interface{}, error) {
+		a, b, err := floatArgs("beta", args)
+		if err != nil {
+			return nil, err
+		}
+		return beta(a, b)
+	}, "Beta function of a and b, gamma(a) gamma(b) / gamma(a + b).")
+	mustRegister(r, "erf", 1, unary{
+		name:  "erf",
+		float: total(math.Erf),
+	}.function(), "Error function of x.")
+	mustRegister(r, "erfc", 1, unary{
+		name:  "erfc",
+		float: total(math.Erfc),
+	}.function(), "Complementary error function of x, 1 - erf(x).")
+	mustRegister(r, "erfinv", 1, unary{
+		name: "erfinv",
+		float: func(x float64) (interface{}, error) {
+			if x < -1 || x > 1 {
+				return nil, &DomainError{Func: "erfinv", Msg: "argument outside [-1, 1]"}
+			}
+			return math.Erfinv(x), nil
+		},
+	}.function(), "Inverse error function of x in [-1, 1].")
+	mustRegister(r, "besselj", 2, bessel("besselj", func(n int, x float64) (interface{}, error) {
+		return math.Jn(n, x), nil
+	}), "Bessel function of the first kind of integer order n at x.")
+	mustRegister(r, "bessely", 2, bessel("bessely", func(n int, x float64) (interface{}, error) {
+		if x <= 0 {
+			return nil, &DomainError{Func: "bessely", Msg: "Bessel functions of the second kind are defined for x > 0"}
+		}
+		return math.Yn(n, x), nil
+	}), "Bessel function of the second kind of integer order n at x > 0.")
+	mustRegister(r, "zeta", 1, unary{
+		name: "zeta",
+		float: func(s float64) (interface{}, error) {
+			if s == 1 {
+				return nil, &DomainError{Func: "zeta", Msg: "the zeta function has a pole at 1"}
+			}
+			return zeta(s), nil
+		},
+	}.function(), "Riemann zeta function of a real s.")
+}
+
+// gammaFunc is the gamma function, shared by gamma and fact in sessions
+// with Options.FactorialGamma.
+var gammaFunc = unary{
+	name: "gamma",
+	exact: func(x *big.Rat) (interface{}, error) {
+		if n, ok := factorialOf(x); ok {
+			return new(big.Rat).SetInt(n), nil
+		}
+		f, _ := x.Float64()
+		return gamma(f)
+	},
+	big: func(x *big.Float) (interface{}, error) {
+		if n, ok := factorialOf(x); ok {
+			return newFloat(max(x.Prec(), uint(n.BitLen()))).SetInt(n), nil
+		}
+		f, _ := x.Float64()
+		return gamma(f)
+	},
+	float: gamma,
+}
+
+// factorialOf returns (x-1)! when x is a positive integer small enough for
+// fact.
+func factorialOf(x interface{}) (*big.Int, bool) {
+	n, err := toInt("gamma", x)
+	if err != nil || n.Sign() <= 0 || !n.IsInt64() || n.Int64() > maxFactorial+1 {
+		return nil, false
+	}
+	return new(big.Int).MulRange(1, n.Int64()-1), true
+}
+
+// gamma returns the gamma function of x, which has poles at zero and the
+// negative integers.
+func gamma(x float64) (interface{}, error) {
+	if isPole(x) {
+		return nil, poleError("gamma")
+	}
+	return math.Gamma(x), nil
+}
+
+// isPole reports whether x is a pole of the gamma function.
+func isPole(x float64) bool {
+	return x <= 0 && x == math.Trunc(x) && !math.IsInf(x, 0)
+}
+
+// poleError reports an argument of the named function at a pole of gamma.
+func poleError(name string) error {
+	return &DomainError{Func: name, Msg: "the gamma function has poles at zero and the negative integers"}
+}
+
+// beta returns gamma(a) gamma(b) / gamma(a + b), computed from the
+// logarithms of the gamma functions when they overflow.
+func beta(a, b float64) (interface{}, error) {
+	if isPole(a) || isPole(b) {
+		return nil, poleError("beta")
+	}
+	if isPole(a + b) {
+		return 0.0, nil
+	}
+	ga, gb, gab := math.Gamma(a), math.Gamma(b), math.Gamma(a+b)
+	if !math.IsInf(ga, 0) && !math.IsInf(gb, 0) && !math.IsInf(gab, 0) {
+		return ga * gb / gab, nil
+	}
+	la, sa := math.Lgamma(a)
+	lb, sb := math.Lgamma(b)
+	lab, sab := math.Lgamma(a + b)
+	return float64(sa*sb*sab) * math.Exp(la+lb-lab), nil
+}
+
+// bessel returns the Bessel function f of an integer order and a real
+// argument as a Function.
+func bessel(name string, f func(n int, x float64) (interface{}, error)) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		n, err := toInt(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if !n.IsInt64() || n.Int64() > maxBesselOrder || n.Int64() < -maxBesselOrder {
+			return nil, &DomainError{Func: name, Msg: fmt.Sprintf("the order %s is too large, the limit is %d", n, maxBesselOrder)}
+		}
+		x, err := floatArg(name, args[1])
+		if err != nil {
+			return nil, err
+		}
+		return f(int(n.Int64()), x)
+	}
+}
+
+// zeta returns the Riemann zeta function of a real s != 1. For s >= 1/2 it
+// sums the alternating eta series with Borwein's acceleration, as
+// zeta(s) = eta(s) / (1 - 2^(1-s)); smaller s are reflected with the
+// functional equation zeta(s) = 2^s pi^(s-1) sin(pi s/2) gamma(1-s) zeta(1-s).
+func zeta(s float64) float64 {
+	switch {
+	case math.IsNaN(s):
+		return s
+	case math.IsInf(s, 1):
+		return 1
+	case s == 0:
+		return -0.5
+	case s < 0 && math.Mod(s, 2) == 0:
+		// The trivial zeros
+		return 0
+	case s < 0.5:
+		return math.Pow(2, s) * math.Pow(math.Pi, s-1) * math.Sin(math.Pi*s/2) * math.Gamma(1-s) * zeta(1-s)
+	}
+
+	// d[k] = n sum_{i=0}^{k} (n+i-1)! 4^i / ((n-i)! (2i)!)
+	const n = 30
+	var d [n + 1]float64
+	term := 1.0
+	d[0] = term
+	for i := 0; i < n; i++ {
+		term *= 4 * float64(n+i) * float64(n-i) / float64((2*i+1)*(2*i+2))
+		d[i+1] = d[i] + term
+	}
+	eta := 0.0
+	for k := n - 1; k >= 0; k-- {
+		t := (d[k] - d[n]) / math.Pow(float64(k+1), s)
+		if k%2 == 1 {
+			t = -t
+		}
+		eta += t
+	}
+	eta /= -d[n]
+	return eta / -math.Expm1((1-s)*math.Ln2)
+}
+
+// promoteFactorial replaces the built-in fact in r with a version returning
+// gamma(x + 1) for a non-integer x instead of an error. A function
+// registered in place of fact is left alone.
+func promoteFactorial(r *Registry) {
+	en, ok := r.Lookup("fact")
+	if builtin, _ := builtins.Lookup("fact"); !ok || en != builtin {
+		return
+	}
+	fn := en.Fn
+	mustRegister(r, "fact", 1, func(args ...interface{}) (interface{}, error) {
+		var domainErr *DomainError
+		if _, err := toInt("fact", args[0]); !errors.As(err, &domainErr) {
+			return fn(args...)
+		}
+		x, err := binaryOp("+", args[0], intLike(1, args[0]))
+		if err != nil {
+			return nil, err
+		}
+		return gammaFunc.function()(x)
+	}, "Factorial of n, or gamma(n + 1) for a non-integer n.")
+}
@@ -0,0 +1,102 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSpecialFunctions tests the special functions against reference values
+func TestSpecialFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected string
+		hasError bool
+	}{
+		{"Gamma of an integer", "gamma(5)", Options{}, "24", false},
+		{"Gamma of a half", "abs(gamma(0.5) - sqrt(pi)) < 1e-15", Options{}, "true", false},
+		{"Gamma of a negative number", "abs(gamma(-0.5) + 2 * sqrt(pi)) < 1e-14", Options{}, "true", false},
+		{"Exact gamma", "gamma(26)", Options{Exact: true}, "15511210043330985984000000", false},
+		{"Big float gamma", "gamma(31)", Options{Precision: 40}, "265252859812191058636308480000000", false},
+		{"Log gamma", "abs(lgamma(100) - 359.1342053695754) < 1e-12", Options{}, "true", false},
+		{"Log gamma past overflow", "lgamma(1000) > 5900", Options{}, "true", false},
+		{"Beta", "beta(2, 3)", Options{}, "0.08333333333333333", false},
+		{"Beta of large arguments", "abs(log(beta(200, 300)) + 337.98011306546) < 1e-9", Options{}, "true", false},
+		{"Error function", "erf(1)", Options{}, "0.8427007929497149", false},
+		{"Complementary error function", "erfc(1)", Options{}, "0.15729920705028513", false},
+		{"Inverse error function", "abs(erf(erfinv(0.3)) - 0.3) < 1e-15", Options{}, "true", false},
+		{"Bessel function of the first kind", "besselj(0, 1)", Options{}, "0.7651976865579666", false},
+		{"Bessel function of the second kind", "bessely(1, 2)", Options{}, "-0.10703243154093756", false},
+		{"Zeta of 2", "abs(zeta(2) - pi^2 / 6) < 1e-15", Options{}, "true", false},
+		{"Zeta of 3", "abs(zeta(3) - 1.2020569031595942) < 1e-15", Options{}, "true", false},
+		{"Zeta below 1", "abs(zeta(0.5) + 1.4603545088095868) < 1e-14", Options{}, "true", false},
+		{"Zeta of 0", "zeta(0)", Options{}, "-0.5", false},
+		{"Zeta of -1", "abs(zeta(-1) + 1/12) < 1e-15", Options{}, "true", false},
+		{"Trivial zero", "zeta(-4)", Options{}, "0", false},
+		{"Gamma at a pole", "gamma(-2)", Options{}, "", true},
+		{"Log gamma at a pole", "lgamma(0)", Options{}, "", true},
+		{"Beta at a pole", "beta(-1, 2)", Options{}, "", true},
+		{"Inverse error function out of range", "erfinv(1.5)", Options{}, "", true},
+		{"Bessel function of a non-integer order", "besselj(0.5, 1)", Options{}, "", true},
+		{"Bessel function of the second kind at 0", "bessely(0, 0)", Options{}, "", true},
+		{"Zeta pole", "zeta(1)", Options{}, "", true},
+		{"Complex argument", "gamma(1i)", Options{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(tt.opts).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", FormatValue(result))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestFactorialGamma tests that Options.FactorialGamma extends fact to
+// non-integers
+func TestFactorialGamma(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+	}{
+		{"Half", Options{FactorialGamma: true}, "abs(fact(0.5) - sqrt(pi) / 2) < 1e-15", "true"},
+		{"Integers stay exact", Options{FactorialGamma: true}, "fact(25)", "15511210043330985984000000"},
+		{"Negative non-integer", Options{FactorialGamma: true}, "abs(fact(-0.5) - sqrt(pi)) < 1e-15", "true"},
+		{"Exact session", Options{FactorialGamma: true, Exact: true}, "fact(3/2) > 1.329", "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(tt.opts).Evaluate(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// Negative integers remain an error, and without the option so do
+	// non-integers
+	var domainErr *DomainError
+	if _, err := New(Options{FactorialGamma: true}).Evaluate("fact(-1)"); !errors.As(err, &domainErr) || domainErr.Func != "fact" {
+		t.Errorf("Expected a fact domain error, got %v", err)
+	}
+	if _, err := New(Options{}).Evaluate("fact(0.5)"); !errors.As(err, &domainErr) {
+		t.Errorf("Expected a domain error, got %v", err)
+	}
+}
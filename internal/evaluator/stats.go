@@ -0,0 +1,424 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// The statistical functions take their data as any mix of numbers and
+// lists, so sum(1, 2, 3), sum([1, 2, 3]) and sum(1..3) agree. They compute
+// with the arithmetic operators, so exact data gives exact sums, means and
+// variances. Quantities are accepted where the result has a unit: the data
+// are converted to the unit of the first value, and the result is given in
+// it, or in its square for a variance.
+
+// registerStats adds the statistical functions to r.
+func registerStats(r *Registry) {
+	mustRegisterVariadic(r, "min", 1, func(args ...interface{}) (interface{}, error) {
+		return extremum("min", args, "<")
+	}, "Smallest of the values.")
+	mustRegisterVariadic(r, "max", 1, func(args ...interface{}) (interface{}, error) {
+		return extremum("max", args, ">")
+	}, "Largest of the values.")
+	mustRegisterVariadic(r, "sum", 1, statistic("sum", 1, sum), "Sum of the values.")
+	mustRegisterVariadic(r, "mean", 1, statistic("mean", 1, mean), "Arithmetic mean of the values.")
+	mustRegisterVariadic(r, "median", 1, statistic("median", 1, median), "Median of the values: the middle one, or the mean of the middle two.")
+	mustRegisterVariadic(r, "mode", 1, statistic("mode", 1, mode), "Most frequent of the values, the smallest on a tie.")
+	mustRegisterVariadic(r, "var", 1, statistic("var", 2, variance), "Sample variance of the values.")
+	mustRegisterVariadic(r, "stdev", 1, statistic("stdev", 2, stdev), "Sample standard deviation of the values.")
+	mustRegisterVariadic(r, "percentile", 2, func(args ...interface{}) (interface{}, error) {
+		p, err := fraction("percentile", args[len(args)-1], 100)
+		if err != nil {
+			return nil, err
+		}
+		return statistic("percentile", 1, func(xs []interface{}) (interface{}, error) {
+			return quantile(xs, p)
+		})(args[:len(args)-1]...)
+	}, "The p-th percentile of the values, given first, for p from 0 to 100, interpolating linearly.")
+	mustRegisterVariadic(r, "quantile", 2, func(args ...interface{}) (interface{}, error) {
+		q, err := fraction("quantile", args[len(args)-1], 1)
+		if err != nil {
+			return nil, err
+		}
+		return statistic("quantile", 1, func(xs []interface{}) (interface{}, error) {
+			return quantile(xs, q)
+		})(args[:len(args)-1]...)
+	}, "The q-quantile of the values, given first, for q from 0 to 1, interpolating linearly.")
+	mustRegister(r, "cov", 2, paired("cov", func(xs, ys []interface{}) (interface{}, error) {
+		sxy, _, _, err := moments(xs, ys)
+		if err != nil {
+			return nil, err
+		}
+		return binaryOp("/", sxy, intLike(int64(len(xs)-1), sxy))
+	}), "Sample covariance of two lists of the same length.")
+	mustRegister(r, "corr", 2, paired("corr", func(xs, ys []interface{}) (interface{}, error) {
+		sxy, sxx, syy, err := moments(xs, ys)
+		if err != nil {
+			return nil, err
+		}
+		if isZero(sxx) || isZero(syy) {
+			return nil, &DomainError{Func: "corr", Msg: "correlation of constant data"}
+		}
+		d, err := binaryOp("*", sxx, syy)
+		if err != nil {
+			return nil, err
+		}
+		if d, err = sqrtFunc.function()(d); err != nil {
+			return nil, err
+		}
+		return binaryOp("/", sxy, d)
+	}), "Pearson correlation coefficient of two lists of the same length.")
+	mustRegister(r, "linreg", 2, paired("linreg", linreg),
+		"Least-squares line through the points of two lists xs and ys, as [slope, intercept].")
+}
+
+// mustRegisterVariadic registers a built-in function taking minArgs or more
+// arguments, panicking on an invalid definition.
+func mustRegisterVariadic(r *Registry, name string, minArgs int, fn Function, doc string) {
+	if err := r.RegisterVariadic(name, minArgs, fn, doc); err != nil {
+		panic(err)
+	}
+}
+
+// statistic adapts a function of at least minValues data values, checked
+// to be real numbers or quantities of one dimension, to a Function taking
+// numbers and lists.
+func statistic(name string, minValues int, f func(xs []interface{}) (interface{}, error)) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		xs, err := data(name, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(xs) < minValues {
+			return nil, &DomainError{Func: name, Msg: fmt.Sprintf("needs at least %d %s", minValues, plural(minValues, "value"))}
+		}
+		xs, unit, err := stripUnits(name, xs)
+		if err != nil {
+			return nil, err
+		}
+		result, err := f(xs)
+		if err != nil || unit.Dimensionless() {
+			return result, err
+		}
+		if name == "var" {
+			if unit, err = unit.Pow(2); err != nil {
+				return nil, &TypeError{Msg: err.Error()}
+			}
+		}
+		value, _ := toFloat(result)
+		return units.New(value, unit), nil
+	}
+}
+
+// data flattens the arguments of a statistical function, which are numbers
+// and lists of numbers, into a list of values.
+func data(name string, args []interface{}) ([]interface{}, error) {
+	var xs []interface{}
+	for _, arg := range args {
+		values, ok := arg.([]interface{})
+		if !ok {
+			values = []interface{}{arg}
+		}
+		for _, x := range values {
+			if _, ok := toFloat(x); !ok && !isQuantity(x) {
+				return nil, &TypeError{Msg: fmt.Sprintf("%s expects real numbers or lists of them", name)}
+			}
+			xs = append(xs, x)
+		}
+	}
+	return xs, nil
+}
+
+// stripUnits converts quantities among xs to the unit of the first one and
+// returns their values with that unit. Data without quantities are
+// returned unchanged with the unit One.
+func stripUnits(name string, xs []interface{}) ([]interface{}, units.Unit, error) {
+	if !isQuantity(xs...) {
+		return xs, units.One, nil
+	}
+	var unit units.Unit
+	values := make([]interface{}, len(xs))
+	for i, x := range xs {
+		q, _ := toQuantity(x)
+		if i == 0 {
+			unit = q.Unit
+		}
+		q, err := units.Convert(q, unit)
+		if err != nil {
+			return nil, units.Unit{}, &TypeError{Msg: fmt.Sprintf("%s: %v", name, err)}
+		}
+		values[i] = q.Value
+	}
+	return values, unit, nil
+}
+
+// paired adapts a function of two equally long lists of at least two real
+// numbers to a Function.
+func paired(name string, f func(xs, ys []interface{}) (interface{}, error)) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		xs, ok1 := args[0].([]interface{})
+		ys, ok2 := args[1].([]interface{})
+		if !ok1 || !ok2 {
+			return nil, &TypeError{Msg: fmt.Sprintf("%s expects two lists", name)}
+		}
+		if len(xs) != len(ys) {
+			return nil, &DomainError{Func: name, Msg: fmt.Sprintf("lists of different lengths, %d and %d", len(xs), len(ys))}
+		}
+		if len(xs) < 2 {
+			return nil, &DomainError{Func: name, Msg: "needs at least 2 points"}
+		}
+		for _, v := range append(append([]interface{}(nil), xs...), ys...) {
+			if _, ok := toFloat(v); !ok {
+				return nil, &TypeError{Msg: fmt.Sprintf("%s expects lists of real numbers", name)}
+			}
+		}
+		return f(xs, ys)
+	}
+}
+
+// fraction returns a percentile or quantile argument, which must lie
+// between 0 and max, as a fraction between 0 and 1.
+func fraction(name string, x interface{}, max int64) (interface{}, error) {
+	f, ok := toFloat(x)
+	if !ok {
+		return nil, &TypeError{Msg: fmt.Sprintf("%s expects a real number as its last argument", name)}
+	}
+	if f < 0 || f > float64(max) {
+		return nil, &DomainError{Func: name, Msg: fmt.Sprintf("%s must be between 0 and %d", FormatValue(x), max)}
+	}
+	return binaryOp("/", x, intLike(max, x))
+}
+
+// extremum returns the value that compares to every other with op.
+func extremum(name string, args []interface{}, op string) (interface{}, error) {
+	xs, err := data(name, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(xs) == 0 {
+		return nil, &DomainError{Func: name, Msg: "needs at least 1 value"}
+	}
+	best := xs[0]
+	for _, x := range xs[1:] {
+		if a, ok := best.(float64); ok {
+			if b, ok := x.(float64); ok {
+				if name == "min" {
+					best = math.Min(a, b)
+				} else {
+					best = math.Max(a, b)
+				}
+				continue
+			}
+		}
+		better, err := binaryOp(op, x, best)
+		if err != nil {
+			return nil, err
+		}
+		if better.(bool) {
+			best = x
+		}
+	}
+	return best, nil
+}
+
+// sum adds the values.
+func sum(xs []interface{}) (interface{}, error) {
+	total := xs[0]
+	for _, x := range xs[1:] {
+		var err error
+		if total, err = binaryOp("+", total, x); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+// mean returns the arithmetic mean of the values.
+func mean(xs []interface{}) (interface{}, error) {
+	total, err := sum(xs)
+	if err != nil {
+		return nil, err
+	}
+	return binaryOp("/", total, intLike(int64(len(xs)), total))
+}
+
+// sorted returns a sorted copy of the values.
+func sorted(xs []interface{}) ([]interface{}, error) {
+	s := append([]interface{}(nil), xs...)
+	var err error
+	sort.SliceStable(s, func(i, j int) bool {
+		less, e := binaryOp("<", s[i], s[j])
+		if e != nil {
+			err = e
+			return false
+		}
+		return less.(bool)
+	})
+	return s, err
+}
+
+// median returns the middle value, or the mean of the middle two.
+func median(xs []interface{}) (interface{}, error) {
+	s, err := sorted(xs)
+	if err != nil {
+		return nil, err
+	}
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2], nil
+	}
+	return mean(s[n/2-1 : n/2+1])
+}
+
+// mode returns the most frequent value, the smallest of them on a tie.
+func mode(xs []interface{}) (interface{}, error) {
+	s, err := sorted(xs)
+	if err != nil {
+		return nil, err
+	}
+	best, bestCount := s[0], 0
+	for i := 0; i < len(s); {
+		j := i + 1
+		for j < len(s) && equal(s[j], s[i]) {
+			j++
+		}
+		if j-i > bestCount {
+			best, bestCount = s[i], j-i
+		}
+		i = j
+	}
+	return best, nil
+}
+
+// variance returns the sample variance of the values, dividing by n - 1.
+func variance(xs []interface{}) (interface{}, error) {
+	ss, _, _, err := moments(xs, xs)
+	if err != nil {
+		return nil, err
+	}
+	return binaryOp("/", ss, intLike(int64(len(xs)-1), ss))
+}
+
+// stdev returns the sample standard deviation of the values.
+func stdev(xs []interface{}) (interface{}, error) {
+	v, err := variance(xs)
+	if err != nil {
+		return nil, err
+	}
+	return sqrtFunc.function()(v)
+}
+
+// quantile returns the q-quantile of the values for a fraction q,
+// interpolating linearly between the nearest two of the sorted values.
+func quantile(xs []interface{}, q interface{}) (interface{}, error) {
+	s, err := sorted(xs)
+	if err != nil {
+		return nil, err
+	}
+	h, err := binaryOp("*", q, intLike(int64(len(s)-1), q))
+	if err != nil {
+		return nil, err
+	}
+	f, _ := toFloat(h)
+	i := int(math.Floor(f))
+	if i >= len(s)-1 {
+		return s[len(s)-1], nil
+	}
+	frac, err := binaryOp("-", h, intLike(int64(i), h))
+	if err != nil || isZero(frac) {
+		return s[i], err
+	}
+	d, err := binaryOp("-", s[i+1], s[i])
+	if err == nil {
+		d, err = binaryOp("*", frac, d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return binaryOp("+", s[i], d)
+}
+
+// moments returns the sums of the products of the deviations from the mean
+// of xs and ys, sum (x - mx)(y - my), and of their squares.
+func moments(xs, ys []interface{}) (sxy, sxx, syy interface{}, err error) {
+	mx, err := mean(xs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	my, err := mean(ys)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sxy, sxx, syy = intLike(0, mx, my), intLike(0, mx), intLike(0, my)
+	for i := range xs {
+		dx, err := binaryOp("-", xs[i], mx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		dy, err := binaryOp("-", ys[i], my)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if sxy, err = addProduct(sxy, dx, dy); err != nil {
+			return nil, nil, nil, err
+		}
+		if sxx, err = addProduct(sxx, dx, dx); err != nil {
+			return nil, nil, nil, err
+		}
+		if syy, err = addProduct(syy, dy, dy); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return sxy, sxx, syy, nil
+}
+
+// addProduct returns acc + a*b.
+func addProduct(acc, a, b interface{}) (interface{}, error) {
+	p, err := binaryOp("*", a, b)
+	if err != nil {
+		return nil, err
+	}
+	return binaryOp("+", acc, p)
+}
+
+// linreg returns the slope and intercept of the least-squares line through
+// the points (xs[i], ys[i]).
+func linreg(xs, ys []interface{}) (interface{}, error) {
+	sxy, sxx, _, err := moments(xs, ys)
+	if err != nil {
+		return nil, err
+	}
+	if isZero(sxx) {
+		return nil, &DomainError{Func: "linreg", Msg: "the x values are all equal"}
+	}
+	slope, err := binaryOp("/", sxy, sxx)
+	if err != nil {
+		return nil, err
+	}
+	mx, _ := mean(xs)
+	my, _ := mean(ys)
+	intercept, err := binaryOp("*", slope, mx)
+	if err == nil {
+		intercept, err = binaryOp("-", my, intercept)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{slope, intercept}, nil
+}
+
+// isZero reports whether a real number is zero.
+func isZero(x interface{}) bool {
+	switch v := x.(type) {
+	case *big.Rat:
+		return v.Sign() == 0
+	case *big.Float:
+		return v.Sign() == 0
+	}
+	f, _ := toFloat(x)
+	return f == 0
+}
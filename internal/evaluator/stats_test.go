@@ -0,0 +1,118 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestLists tests list literals, ranges and list equality
+func TestLists(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"List", "[1, 2.5, 3]", "[1, 2.5, 3]", false},
+		{"Empty list", "[]", "[]", false},
+		{"Elements are expressions", "A = 2; [A, A^2, sqrt(16)]", "[2, 4, 4]", false},
+		{"Nested list", "[[1, 2], [3]]", "[[1, 2], [3]]", false},
+		{"Range", "1..5", "[1, 2, 3, 4, 5]", false},
+		{"Descending range", "3..1", "[3, 2, 1]", false},
+		{"Range binds looser than arithmetic", "n = 3; 1..n+1", "[1, 2, 3, 4]", false},
+		{"Range with spaces", "-1 .. 1", "[-1, 0, 1]", false},
+		{"Equal lists", "[1, 2, 3] == 1..3", "true", false},
+		{"Unequal lists", "[1, 2] == [1, 2, 3]", "false", false},
+		{"Non-integer bound", "1..2.5", "", true},
+		{"Huge range", "1..1e9", "", true},
+		{"Arithmetic on a list", "[1, 2] + 1", "", true},
+		{"List condition", "[1] ? 1 : 2", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestStatistics tests the statistical functions in each numeric mode
+func TestStatistics(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected string
+		hasError bool
+	}{
+		{"Variadic sum", "sum(1, 2, 3)", Options{}, "6", false},
+		{"Sum of a range", "sum(1..100)", Options{}, "5050", false},
+		{"Lists and numbers mix", "sum([1, 2], 3, 4..5)", Options{}, "15", false},
+		{"Mean", "mean([1, 2, 3, 4])", Options{}, "2.5", false},
+		{"Exact mean", "mean(1, 2, 2)", Options{Exact: true}, "5/3", false},
+		{"Median of an odd count", "median(5, 1, 3)", Options{}, "3", false},
+		{"Median of an even count", "median(4, 1, 3, 2)", Options{}, "2.5", false},
+		{"Mode", "mode(1, 2, 2, 3, 3, 3)", Options{}, "3", false},
+		{"Mode on a tie", "mode(3, 3, 1, 1, 2)", Options{}, "1", false},
+		{"Sample variance", "var(2, 4, 4, 4, 5, 5, 7, 9)", Options{}, "4.571428571428571", false},
+		{"Exact variance", "var(1, 2, 4)", Options{Exact: true}, "7/3", false},
+		{"Standard deviation", "stdev(1, 3)", Options{}, "1.4142135623730951", false},
+		{"Percentile", "percentile(1..5, 90)", Options{}, "4.6", false},
+		{"Percentile at the ends", "[percentile(1..5, 0), percentile(1..5, 100)]", Options{}, "[1, 5]", false},
+		{"Exact quantile", "quantile(1..4, 1/2)", Options{Exact: true}, "5/2", false},
+		{"Covariance", "cov([1, 2, 3], [2, 4, 7])", Options{}, "2.5", false},
+		{"Correlation", "corr([1, 2, 3], [2, 4, 6])", Options{}, "1", false},
+		{"Linear regression", "linreg([1, 2, 3], [3, 5, 7])", Options{}, "[2, 1]", false},
+		{"Exact regression", "linreg([1, 2, 3], [1, 3, 4])", Options{Exact: true}, "[3/2, -1/3]", false},
+		{"Variadic max", "max(3, [7, 1], 5)", Options{}, "7", false},
+		{"Min of a list", "min(4..9)", Options{}, "4", false},
+		{"Mean of quantities", "mean(1 km, 500 m)", Options{}, "0.75 km", false},
+		{"Variance of quantities", "var(1 m, 3 m)", Options{}, "2 m^2", false},
+		{"Big float mean", "mean(1, 2)", Options{Precision: 20}, "1.5", false},
+		{"No values", "sum([])", Options{}, "", true},
+		{"Variance of one value", "var(5)", Options{}, "", true},
+		{"Percentile out of range", "percentile(1..5, 101)", Options{}, "", true},
+		{"Percentile without data", "percentile(50)", Options{}, "", true},
+		{"Lists of different lengths", "cov([1, 2], [1, 2, 3])", Options{}, "", true},
+		{"Correlation of constant data", "corr([1, 1, 1], [1, 2, 3])", Options{}, "", true},
+		{"Regression on equal x values", "linreg([2, 2], [1, 3])", Options{}, "", true},
+		{"Complex data", "sum(1, 2i)", Options{}, "", true},
+		{"Booleans are not data", "mean(true, 1)", Options{}, "", true},
+		{"Incompatible quantities", "sum(1 m, 1 s)", Options{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(tt.opts).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", FormatValue(result))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	var domainErr *DomainError
+	if _, err := Evaluate("median([])"); !errors.As(err, &domainErr) || domainErr.Func != "median" {
+		t.Errorf("Expected a median domain error, got %v", err)
+	}
+}
@@ -0,0 +1,383 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"strings"
+)
+
+// The built-in trigonometric functions take and return radians. Sessions
+// with another Options.Angle wrap the functions taking an angle, which
+// convert it to radians, and those returning one, which convert their
+// result back. The conversions are computed with a big.Float holding more
+// bits than the argument, so sin(30) is exactly 0.5 in degrees, and whole
+// right angles are exact: cos(90) is 0 and tan(90) is an error. The
+// hyperbolic functions do not take angles and are the same in every mode.
+
+// AngleUnit is the unit of the angles taken and returned by the
+// trigonometric functions of a session.
+type AngleUnit int
+
+const (
+	// Radians is the default angle unit.
+	Radians AngleUnit = iota
+	// Degrees divide a turn into 360.
+	Degrees
+	// Gradians divide a turn into 400, so a right angle is 100 grad.
+	Gradians
+)
+
+// angleUnits maps the names accepted by ParseAngleUnit to angle units,
+// with the number of units in half a turn. The first name of each unit is
+// the one String returns.
+var angleUnits = []struct {
+	names    []string
+	unit     AngleUnit
+	halfTurn int64
+}{
+	{[]string{"rad", "radians"}, Radians, 0},
+	{[]string{"deg", "degrees"}, Degrees, 180},
+	{[]string{"grad", "gradians", "gon"}, Gradians, 200},
+}
+
+// ParseAngleUnit returns the angle unit named s: rad, deg or grad.
+func ParseAngleUnit(s string) (AngleUnit, error) {
+	for _, u := range angleUnits {
+		for _, name := range u.names {
+			if strings.EqualFold(s, name) {
+				return u.unit, nil
+			}
+		}
+	}
+	return Radians, fmt.Errorf("unknown angle unit %q (want deg, rad or grad)", s)
+}
+
+func (u AngleUnit) String() string {
+	for _, entry := range angleUnits {
+		if entry.unit == u {
+			return entry.names[0]
+		}
+	}
+	return fmt.Sprintf("AngleUnit(%d)", int(u))
+}
+
+// halfTurn returns the number of units in half a turn, or 0 for radians.
+func (u AngleUnit) halfTurn() int64 {
+	for _, entry := range angleUnits {
+		if entry.unit == u {
+			return entry.halfTurn
+		}
+	}
+	return 0
+}
+
+// toRadians converts x from u to radians at the precision of x.
+func (u AngleUnit) toRadians(x *big.Float) *big.Float {
+	prec := x.Prec()
+	r := newFloat(prec).Mul(x, bigPi(prec))
+	return r.Quo(r, bigInt(u.halfTurn(), prec))
+}
+
+// fromRadians converts x from radians to u at the precision of x.
+func (u AngleUnit) fromRadians(x *big.Float) *big.Float {
+	prec := x.Prec()
+	r := newFloat(prec).Mul(x, bigInt(u.halfTurn(), prec))
+	return r.Quo(r, bigPi(prec))
+}
+
+// registerTrig adds the inverse trigonometric and hyperbolic functions and
+// the angle conversions to r.
+func registerTrig(r *Registry) {
+	mustRegister(r, "asin", 1, unary{
+		name: "asin",
+		big: func(x *big.Float) (interface{}, error) {
+			return bigAsin(x)
+		},
+		float: func(x float64) (interface{}, error) {
+			if x < -1 || x > 1 {
+				return nil, &DomainError{Func: "asin", Msg: "argument outside [-1, 1]"}
+			}
+			return math.Asin(x), nil
+		},
+		complex: complexTotal(cmplx.Asin),
+	}.function(), "Inverse sine of x, as an angle.")
+	mustRegister(r, "acos", 1, unary{
+		name: "acos",
+		big: func(x *big.Float) (interface{}, error) {
+			return bigAcos(x)
+		},
+		float: func(x float64) (interface{}, error) {
+			if x < -1 || x > 1 {
+				return nil, &DomainError{Func: "acos", Msg: "argument outside [-1, 1]"}
+			}
+			return math.Acos(x), nil
+		},
+		complex: complexTotal(cmplx.Acos),
+	}.function(), "Inverse cosine of x, as an angle.")
+	mustRegister(r, "atan", 1, unary{
+		name:    "atan",
+		big:     bigTotal(bigAtan),
+		float:   total(math.Atan),
+		complex: complexTotal(cmplx.Atan),
+	}.function(), "Inverse tangent of x, as an angle.")
+	mustRegister(r, "atan2", 2, func(args ...interface{}) (interface{}, error) {
+		if isComplex(args...) {
+			return nil, &TypeError{Msg: "atan2 expects real arguments"}
+		}
+		if prec := bigPrec(args...); prec > 0 {
+			y, ok1 := toBigFloat(args[0], prec)
+			x, ok2 := toBigFloat(args[1], prec)
+			if ok1 && ok2 {
+				return bigAtan2(y, x), nil
+			}
+		}
+		y, x, err := floatArgs("atan2", args)
+		if err != nil {
+			return nil, err
+		}
+		return math.Atan2(y, x), nil
+	}, "Angle of the point (x, y) from the positive x axis, given as atan2(y, x).")
+	mustRegister(r, "sinh", 1, unary{
+		name:    "sinh",
+		big:     bigTotal(bigSinh),
+		float:   total(math.Sinh),
+		complex: complexTotal(cmplx.Sinh),
+	}.function(), "Hyperbolic sine of x.")
+	mustRegister(r, "cosh", 1, unary{
+		name:    "cosh",
+		big:     bigTotal(bigCosh),
+		float:   total(math.Cosh),
+		complex: complexTotal(cmplx.Cosh),
+	}.function(), "Hyperbolic cosine of x.")
+	mustRegister(r, "tanh", 1, unary{
+		name:    "tanh",
+		big:     bigTotal(bigTanh),
+		float:   total(math.Tanh),
+		complex: complexTotal(cmplx.Tanh),
+	}.function(), "Hyperbolic tangent of x.")
+	mustRegister(r, "asinh", 1, unary{
+		name:    "asinh",
+		big:     bigTotal(bigAsinh),
+		float:   total(math.Asinh),
+		complex: complexTotal(cmplx.Asinh),
+	}.function(), "Inverse hyperbolic sine of x.")
+	mustRegister(r, "acosh", 1, unary{
+		name: "acosh",
+		big: func(x *big.Float) (interface{}, error) {
+			return bigAcosh(x)
+		},
+		float: func(x float64) (interface{}, error) {
+			if x < 1 {
+				return nil, &DomainError{Func: "acosh", Msg: "argument less than 1"}
+			}
+			return math.Acosh(x), nil
+		},
+		complex: complexTotal(cmplx.Acosh),
+	}.function(), "Inverse hyperbolic cosine of x >= 1.")
+	mustRegister(r, "atanh", 1, unary{
+		name: "atanh",
+		big: func(x *big.Float) (interface{}, error) {
+			return bigAtanh(x)
+		},
+		float: func(x float64) (interface{}, error) {
+			if x < -1 || x > 1 {
+				return nil, &DomainError{Func: "atanh", Msg: "argument outside [-1, 1]"}
+			}
+			return math.Atanh(x), nil
+		},
+		complex: complexTotal(cmplx.Atanh),
+	}.function(), "Inverse hyperbolic tangent of x.")
+	mustRegister(r, "deg", 1, func(args ...interface{}) (interface{}, error) {
+		return convertAngle("deg", args[0], Degrees.fromRadians)
+	}, "x radians in degrees, whatever the angle mode.")
+	mustRegister(r, "rad", 1, func(args ...interface{}) (interface{}, error) {
+		return convertAngle("rad", args[0], Degrees.toRadians)
+	}, "x degrees in radians, whatever the angle mode.")
+}
+
+// angleArgs and angleResults list the built-in functions taking an angle
+// and those returning one.
+var (
+	angleArgs    = []string{"sin", "cos", "tan"}
+	angleResults = []string{"asin", "acos", "atan", "atan2", "arg"}
+)
+
+// promoteAngle replaces the functions in r taking or returning an angle
+// with versions using u instead of radians. Only the built-in functions,
+// and those in promoted, are replaced; functions registered in place of a
+// built-in are left alone.
+func promoteAngle(r *Registry, u AngleUnit, promoted map[string]bool) {
+	replace := func(name string, wrap func(en *Entry) Function) {
+		en, ok := r.Lookup(name)
+		if builtin, _ := builtins.Lookup(name); !ok || (en != builtin && !promoted[name]) {
+			return
+		}
+		mustRegister(r, name, en.MaxArgs, wrap(en), en.Doc)
+	}
+	for _, name := range angleArgs {
+		replace(name, func(en *Entry) Function {
+			return u.argument(name, en.Fn)
+		})
+	}
+	for _, name := range angleResults {
+		replace(name, func(en *Entry) Function {
+			return u.result(en.Fn)
+		})
+	}
+}
+
+// argument returns fn, a function of an angle in radians, as a function of
+// an angle in u.
+func (u AngleUnit) argument(name string, fn Function) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		if k, ok := u.rightAngles(args[0]); ok {
+			return rightAngle(name, k, args[0])
+		}
+		switch x := args[0].(type) {
+		case complex128:
+			return fn(x * complex(math.Pi/float64(u.halfTurn()), 0))
+		case float64:
+			if math.IsInf(x, 0) || math.IsNaN(x) {
+				return fn(x)
+			}
+		}
+		wide, prec, ok := widen(args[0])
+		if !ok {
+			return fn(args...)
+		}
+		result, err := fn(u.toRadians(wide))
+		if err != nil {
+			return nil, err
+		}
+		return narrow(result, prec), nil
+	}
+}
+
+// result returns fn, a function returning an angle in radians, as a
+// function returning an angle in u.
+func (u AngleUnit) result(fn Function) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		wide := make([]interface{}, len(args))
+		prec := uint(0)
+		for i, arg := range args {
+			x, p, ok := widen(arg)
+			if !ok {
+				wide = nil
+				break
+			}
+			wide[i], prec = x, max(prec, p)
+		}
+		if wide == nil {
+			wide = args
+		}
+		result, err := fn(wide...)
+		if err != nil {
+			return nil, err
+		}
+		switch v := result.(type) {
+		case *big.Float:
+			return narrow(u.fromRadians(v), prec), nil
+		case complex128:
+			return fromComplex(v * complex(float64(u.halfTurn())/math.Pi, 0)), nil
+		}
+		return convertAngle(u.String(), result, u.fromRadians)
+	}
+}
+
+// rightAngles returns k when x is a real number of exactly k right angles
+// in u.
+func (u AngleUnit) rightAngles(x interface{}) (int64, bool) {
+	quarter := u.halfTurn() / 2
+	switch v := x.(type) {
+	case float64:
+		k := v / float64(quarter)
+		return int64(k), k == math.Trunc(k) && math.Abs(k) < 1<<53
+	case *big.Rat:
+		k := new(big.Rat).Quo(v, big.NewRat(quarter, 1))
+		return k.Num().Int64(), k.IsInt() && k.Num().IsInt64()
+	case *big.Float:
+		if v.IsInf() {
+			return 0, false
+		}
+		k := newFloat(v.Prec()).Quo(v, bigInt(quarter, v.Prec()))
+		n, acc := k.Int64()
+		return n, acc == big.Exact && newFloat(v.Prec()+8).Mul(k, bigInt(quarter, v.Prec())).Cmp(v) == 0
+	}
+	return 0, false
+}
+
+// rightAngle returns the exact value of the named function at k right
+// angles, in the representation of x.
+func rightAngle(name string, k int64, x interface{}) (interface{}, error) {
+	// Sine and cosine at 0, 1, 2 and 3 right angles
+	sin := [4]int64{0, 1, 0, -1}
+	cos := [4]int64{1, 0, -1, 0}
+	i := (k%4 + 4) % 4
+	switch name {
+	case "sin":
+		return intLike(sin[i], x), nil
+	case "cos":
+		return intLike(cos[i], x), nil
+	}
+	if cos[i] == 0 {
+		return nil, &DomainError{Func: name, Msg: "tangent is undefined at odd multiples of a right angle"}
+	}
+	return intLike(0, x), nil
+}
+
+// convertAngle applies an angle conversion to a number, keeping its
+// representation except that rationals become float64.
+func convertAngle(name string, x interface{}, convert func(x *big.Float) *big.Float) (interface{}, error) {
+	if z, ok := x.(complex128); ok {
+		re, _ := convertAngle(name, real(z), convert)
+		im, _ := convertAngle(name, imag(z), convert)
+		return fromComplex(complex(re.(float64), im.(float64))), nil
+	}
+	if f, ok := x.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return f, nil
+	}
+	wide, prec, ok := widen(x)
+	if !ok {
+		return nil, &TypeError{Msg: fmt.Sprintf("%s expects a numeric argument", name)}
+	}
+	return narrow(convert(wide), prec), nil
+}
+
+// widen returns a finite real number as a *big.Float with guardBits more
+// than its precision, and the precision to narrow results to: that of a
+// *big.Float, or 0 for float64 and rationals.
+func widen(x interface{}) (*big.Float, uint, bool) {
+	prec := uint(0)
+	switch v := x.(type) {
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, 0, false
+		}
+	case *big.Float:
+		prec = v.Prec()
+	case *big.Rat:
+	default:
+		return nil, 0, false
+	}
+	wp := max(prec, 53) + guardBits
+	wide, _ := toBigFloat(x, wp)
+	// Keep the bits a conversion by pi loses for large arguments
+	wp += uint(max(exponent(wide), 0))
+	return round(wide, wp), prec, true
+}
+
+// narrow rounds a *big.Float result computed from a widened argument back
+// to prec bits, or to a float64 when prec is 0.
+func narrow(x interface{}, prec uint) interface{} {
+	v, ok := x.(*big.Float)
+	if !ok {
+		return x
+	}
+	if prec == 0 {
+		f, _ := v.Float64()
+		return f
+	}
+	return round(v, prec)
+}
@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestTrigFunctions tests the inverse trigonometric and hyperbolic
+// functions and the angle conversions
+func TestTrigFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"Inverse sine", "asin(1)", "1.5707963267948966", false},
+		{"Inverse cosine", "acos(-1)", "3.141592653589793", false},
+		{"Inverse tangent", "atan(1)", "0.7853981633974483", false},
+		{"Angle of a point", "atan2(1, -1)", "2.356194490192345", false},
+		{"Angle on the negative y axis", "atan2(-2, 0)", "-1.5707963267948966", false},
+		{"Hyperbolic sine", "sinh(0)", "0", false},
+		{"Hyperbolic cosine", "cosh(0)", "1", false},
+		{"Hyperbolic tangent", "tanh(1000)", "1", false},
+		{"Inverse hyperbolic sine", "asinh(sinh(2)) == 2", "true", false},
+		{"Inverse hyperbolic cosine", "acosh(1)", "0", false},
+		{"Inverse hyperbolic tangent", "atanh(0)", "0", false},
+		{"Radians to degrees", "deg(pi)", "180", false},
+		{"Degrees to radians", "rad(90)", "1.5707963267948966", false},
+		{"Complex hyperbolic sine", "sinh(pi * 1i / 2) == 1i", "true", false},
+		{"Inverse sine out of range", "asin(1.5)", "", true},
+		{"Inverse cosine out of range", "acos(-2)", "", true},
+		{"Inverse hyperbolic cosine below 1", "acosh(0.5)", "", true},
+		{"Inverse hyperbolic tangent out of range", "atanh(2)", "", true},
+		{"Complex atan2", "atan2(1i, 1)", "", true},
+		{"Conversion of a boolean", "deg(true)", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{}).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", FormatValue(result))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestAngleMode tests that Options.Angle changes the unit of the angles
+// taken and returned by the trigonometric functions
+func TestAngleMode(t *testing.T) {
+	deg := Options{Angle: Degrees}
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"Sine", deg, "sin(30)", "0.5", false},
+		{"Cosine", deg, "cos(60)", "0.5", false},
+		{"Tangent", deg, "tan(45)", "1", false},
+		{"Half turn", deg, "sin(180)", "0", false},
+		{"Right angle", deg, "cos(-90)", "0", false},
+		{"Large angle", deg, "sin(360 * 1e6 + 30)", "0.5", false},
+		{"Inverse sine", deg, "asin(0.5)", "30", false},
+		{"Inverse cosine", deg, "acos(-1)", "180", false},
+		{"Inverse tangent", deg, "atan(1)", "45", false},
+		{"Angle of a point", deg, "atan2(1, -1)", "135", false},
+		{"Argument", deg, "arg(-1)", "180", false},
+		{"Hyperbolic functions take no angle", deg, "sinh(1) == 1.1752011936438014", "true", false},
+		{"Conversions ignore the mode", deg, "deg(pi / 2)", "90", false},
+		{"Gradians", Options{Angle: Gradians}, "[sin(100), asin(1), cos(200)]", "[1, 100, -1]", false},
+		{"Exact right angles", Options{Angle: Degrees, Exact: true}, "cos(180)", "-1", false},
+		{"Exact session", Options{Angle: Degrees, Exact: true}, "sin(30)", "0.5", false},
+		{"Precision session", Options{Angle: Degrees, Precision: 30}, "asin(1/2)", "30", false},
+		{"Precision tangent", Options{Angle: Degrees, Precision: 30}, "tan(60) == sqrt(3)", "true", false},
+		{"Complex inverse sine", Options{Angle: Degrees, Complex: true}, "re(asin(2))", "90", false},
+		{"Radians by default", Options{}, "sin(30)", "-0.9880316240928618", false},
+		{"Tangent of a right angle", deg, "tan(90)", "", true},
+		{"Tangent of three right angles", deg, "tan(270)", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(tt.opts).Evaluate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", FormatValue(result))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := FormatValue(result); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	var domainErr *DomainError
+	if _, err := New(deg).Evaluate("tan(90)"); !errors.As(err, &domainErr) || domainErr.Func != "tan" {
+		t.Errorf("Expected a tan domain error, got %v", err)
+	}
+
+	// Functions registered in place of a built-in are not replaced
+	registry := Builtins()
+	if err := registry.Register("sin", 1, func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	}, "Identity."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := New(Options{Registry: registry, Angle: Degrees}).Evaluate("sin(30)")
+	if err != nil || FormatValue(result) != "30" {
+		t.Errorf("Expected the registered sin to be used, got %v, %v", result, err)
+	}
+}
+
+// TestParseAngleUnit tests the names of the angle units
+func TestParseAngleUnit(t *testing.T) {
+	for name, expected := range map[string]AngleUnit{"rad": Radians, "DEG": Degrees, "degrees": Degrees, "grad": Gradians, "gon": Gradians} {
+		u, err := ParseAngleUnit(name)
+		if err != nil || u != expected {
+			t.Errorf("ParseAngleUnit(%q) = %v, %v, want %v", name, u, err, expected)
+		}
+	}
+	if _, err := ParseAngleUnit("turn"); err == nil {
+		t.Error("Expected an error for an unknown unit")
+	}
+	if got := Degrees.String(); got != "deg" {
+		t.Errorf("Expected deg, got %s", got)
+	}
+}
@@ -0,0 +1,334 @@
+// Package format renders numbers for display: with a chosen number of
+// significant digits, in fixed, scientific or engineering notation, with
+// thousands separators and with trailing zeros trimmed. It handles every
+// kind of number the evaluator produces.
+package format
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// Notation selects how numbers are written.
+type Notation int
+
+const (
+	// Auto writes numbers in fixed notation unless the exponent is large or
+	// small enough for scientific notation to be shorter, like %g.
+	Auto Notation = iota
+	// Fixed writes numbers without an exponent: 1234.5.
+	Fixed
+	// Scientific writes numbers with one digit before the point and an
+	// exponent: 1.2345e+03.
+	Scientific
+	// Engineering writes numbers with one to three digits before the point
+	// and an exponent that is a multiple of three: 1.2345e+03, 12.345e+03.
+	Engineering
+)
+
+// notations maps the names accepted by ParseNotation to notations. The
+// first name of each notation is the one String returns.
+var notations = []struct {
+	names    []string
+	notation Notation
+}{
+	{[]string{"auto"}, Auto},
+	{[]string{"fixed", "fix"}, Fixed},
+	{[]string{"sci", "scientific"}, Scientific},
+	{[]string{"eng", "engineering"}, Engineering},
+}
+
+// ParseNotation returns the notation named s: auto, fixed, sci or eng.
+func ParseNotation(s string) (Notation, error) {
+	for _, n := range notations {
+		for _, name := range n.names {
+			if strings.EqualFold(s, name) {
+				return n.notation, nil
+			}
+		}
+	}
+	return Auto, fmt.Errorf("unknown notation %q (want auto, fixed, sci or eng)", s)
+}
+
+func (n Notation) String() string {
+	for _, entry := range notations {
+		if entry.notation == n {
+			return entry.names[0]
+		}
+	}
+	return fmt.Sprintf("Notation(%d)", int(n))
+}
+
+// Options controls how numbers are written. The zero Options writes
+// float64 values in the shortest form that reads back exactly, rationals
+// as fractions and big floats to the precision they hold.
+type Options struct {
+	Notation Notation
+	// Digits is the number of significant digits, or in Fixed notation the
+	// number of digits after the decimal point. Zero means as many as
+	// needed to represent the value exactly.
+	Digits int
+	// Grouping separates the digits before the decimal point into groups
+	// of three with commas: 1,234,567.
+	Grouping bool
+	// TrimZeros drops trailing zeros after the decimal point, and the point
+	// itself when no digits follow it.
+	TrimZeros bool
+	// Precision is the number of significant digits big floats were
+	// computed to. Without Digits they are written to that many digits
+	// rather than to as many as their binary precision holds.
+	Precision int
+}
+
+// Value formats a number produced by the evaluator: a float64, *big.Rat,
+// *big.Float, complex128 or units.Quantity. Other values are formatted with
+// fmt.Sprint.
+func Value(v interface{}, opts Options) string {
+	switch x := v.(type) {
+	case float64:
+		return Float(x, opts)
+	case *big.Rat:
+		return Rat(x, opts)
+	case *big.Float:
+		return BigFloat(x, opts)
+	case complex128:
+		return Complex(x, opts)
+	case units.Quantity:
+		return Quantity(x, opts)
+	case []interface{}:
+		return List(x, opts)
+	}
+	return fmt.Sprint(v)
+}
+
+// List formats a list as its formatted elements in brackets: [1, 2.5, 3].
+func List(values []interface{}, opts Options) string {
+	elems := make([]string, len(values))
+	for i, v := range values {
+		elems[i] = Value(v, opts)
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// Float formats a float64. In Auto notation without Options.Digits,
+// integers that float64 holds exactly are written without an exponent.
+func Float(x float64, opts Options) string {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	if opts.Notation == Auto && opts.Digits == 0 && x == math.Trunc(x) && math.Abs(x) <= 1<<53 {
+		opts.Notation = Fixed
+	}
+	return render(func(verb byte, prec int) string {
+		return strconv.FormatFloat(x, verb, prec, 64)
+	}, opts)
+}
+
+// BigFloat formats a *big.Float. Without Options.Digits it is written with
+// Options.Precision significant digits, or as many as its precision holds,
+// keeping the trailing zeros of a rounded value so that every digit shows.
+func BigFloat(x *big.Float, opts Options) string {
+	if x.IsInf() {
+		return x.Text('g', 0)
+	}
+	if opts.Digits > 0 || opts.Notation == Fixed {
+		return render(x.Text, opts)
+	}
+	opts.Digits = opts.Precision
+	if opts.Digits <= 0 {
+		opts.Digits = max(int(float64(x.Prec())*math.Log10(2)), 1)
+	}
+	return render(func(verb byte, prec int) string {
+		return padDigits(x, x.Text(verb, prec), opts.Digits)
+	}, opts)
+}
+
+// padDigits appends zeros to s, the decimal text of x, until it shows the
+// given number of significant digits. Text drops the trailing zeros of the
+// 'g' verb, so 1.5 rounded to 3 digits reads 1.5; an exactly written value
+// is left as it is, while a rounded one is padded to show its precision.
+func padDigits(x *big.Float, s string, digits int) string {
+	mantissa, exp := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exp = s[:i], s[i:]
+	}
+	shown := len(strings.TrimLeft(strings.NewReplacer("-", "", ".", "").Replace(mantissa), "0"))
+	if shown >= digits {
+		return s
+	}
+	if r, ok := new(big.Rat).SetString(s); ok {
+		if exact, _ := x.Rat(nil); exact.Cmp(r) == 0 {
+			return s
+		}
+	}
+	if !strings.Contains(mantissa, ".") {
+		mantissa += "."
+	}
+	return mantissa + strings.Repeat("0", digits-shown) + exp
+}
+
+// Rat formats a *big.Rat. In Auto notation without Options.Digits it is
+// written exactly, as an integer or a fraction such as 1/3; otherwise it is
+// written as a decimal.
+func Rat(x *big.Rat, opts Options) string {
+	if opts.Notation == Auto && opts.Digits == 0 {
+		if !opts.Grouping {
+			return x.RatString()
+		}
+		if x.IsInt() {
+			return group(x.Num().String())
+		}
+		return group(x.Num().String()) + "/" + group(x.Denom().String())
+	}
+
+	// Without Digits, write the shortest form at float64 precision;
+	// otherwise carry enough bits for the integer part and the digits
+	prec := uint(53)
+	if opts.Digits > 0 {
+		intBits := max(x.Num().BitLen()-x.Denom().BitLen(), 0)
+		prec = 64 + uint(intBits) + uint(math.Ceil(float64(opts.Digits)*math.Log2(10)))
+	}
+	return render(new(big.Float).SetPrec(prec).SetRat(x).Text, opts)
+}
+
+// Complex formats a complex128 as a real part followed by a signed
+// imaginary part, as the evaluator reads it: 1+2i, 3-i, 2i. A zero real
+// part is omitted, as is an imaginary coefficient of 1.
+func Complex(z complex128, opts Options) string {
+	re, im := real(z), imag(z)
+	coeff := strings.TrimPrefix(Float(math.Abs(im), opts), "+")
+	if coeff == "1" {
+		coeff = ""
+	}
+	sign := "+"
+	if im < 0 {
+		sign = "-"
+	}
+	if re == 0 {
+		return strings.TrimPrefix(sign, "+") + coeff + "i"
+	}
+	return Float(re, opts) + sign + coeff + "i"
+}
+
+// Quantity formats a quantity as its value followed by its unit: 5.3 km.
+func Quantity(q units.Quantity, opts Options) string {
+	if q.Unit.Dimensionless() {
+		return Float(q.Value, opts)
+	}
+	return Float(q.Value, opts) + " " + q.Unit.String()
+}
+
+// render writes a finite number using text, which formats it with a verb
+// and precision as strconv.FormatFloat and big.Float.Text do.
+func render(text func(verb byte, prec int) string, opts Options) string {
+	prec := opts.Digits
+	var s string
+	switch opts.Notation {
+	case Fixed:
+		if prec == 0 {
+			prec = -1
+		}
+		s = text('f', prec)
+	case Scientific:
+		s = text('e', sigPrec(prec))
+	case Engineering:
+		s = engineering(text('e', sigPrec(prec)))
+	default:
+		if prec == 0 {
+			prec = -1
+		}
+		s = text('g', prec)
+	}
+
+	mantissa, exp := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exp = s[:i], s[i:]
+	}
+	if opts.TrimZeros && strings.Contains(mantissa, ".") {
+		mantissa = strings.TrimRight(strings.TrimRight(mantissa, "0"), ".")
+	}
+	if opts.Grouping {
+		mantissa = group(mantissa)
+	}
+	return mantissa + exp
+}
+
+// sigPrec converts a number of significant digits to the precision of the
+// 'e' verb, which counts the digits after the point.
+func sigPrec(digits int) int {
+	if digits == 0 {
+		return -1
+	}
+	return digits - 1
+}
+
+// engineering rewrites a number in scientific notation, such as
+// "-1.2345e+04", so that its exponent is a multiple of three:
+// "-12.345e+03".
+func engineering(s string) string {
+	i := strings.IndexByte(s, 'e')
+	if i < 0 {
+		return s
+	}
+	mantissa, expText := s[:i], s[i+1:]
+	exp, err := strconv.Atoi(expText)
+	if err != nil {
+		return s
+	}
+	shift := ((exp % 3) + 3) % 3
+	if shift == 0 {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(mantissa, "-") {
+		sign, mantissa = "-", mantissa[1:]
+	}
+	digits := strings.Replace(mantissa, ".", "", 1)
+	// Move the point right by shift places, padding with zeros if needed
+	for len(digits) < 1+shift {
+		digits += "0"
+	}
+	mantissa = digits[:1+shift]
+	if rest := digits[1+shift:]; rest != "" {
+		mantissa += "." + rest
+	}
+
+	exp -= shift
+	expSign := "+"
+	if exp < 0 {
+		expSign, exp = "-", -exp
+	}
+	return fmt.Sprintf("%s%se%s%02d", sign, mantissa, expSign, exp)
+}
+
+// group inserts commas between groups of three digits in the integer part
+// of a decimal number.
+func group(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	end := strings.IndexByte(s, '.')
+	if end < 0 {
+		end = len(s)
+	}
+	intPart, rest := s[:end], s[end:]
+	if len(intPart) <= 3 || strings.Trim(intPart, "0123456789") != "" {
+		return sign + s
+	}
+
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String() + rest
+}
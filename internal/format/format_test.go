@@ -0,0 +1,215 @@
+package format
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// TestFloat tests each notation with and without digits
+func TestFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		x        float64
+		opts     Options
+		expected string
+	}{
+		{"Shortest", 0.1, Options{}, "0.1"},
+		{"Large exponent", 1e21, Options{}, "1e+21"},
+		{"Integer", 976371285, Options{}, "976371285"},
+		{"Largest exact integer", -(1 << 53), Options{}, "-9007199254740992"},
+		{"Integer past float64 precision", 1e16, Options{}, "1e+16"},
+		{"Grouped integer", 1234567, Options{Grouping: true}, "1,234,567"},
+		{"Significant digits", 2.0 / 3, Options{Digits: 4}, "0.6667"},
+		{"Significant digits of a large number", 123456, Options{Digits: 2}, "1.2e+05"},
+		{"Fixed", 1234.5, Options{Notation: Fixed}, "1234.5"},
+		{"Fixed with decimals", 1234.5, Options{Notation: Fixed, Digits: 3}, "1234.500"},
+		{"Fixed rounds", 2.0 / 3, Options{Notation: Fixed, Digits: 2}, "0.67"},
+		{"Scientific", 1234.5, Options{Notation: Scientific}, "1.2345e+03"},
+		{"Scientific with digits", 1234.5, Options{Notation: Scientific, Digits: 2}, "1.2e+03"},
+		{"Engineering", 12345, Options{Notation: Engineering}, "12.345e+03"},
+		{"Engineering pads", 100000, Options{Notation: Engineering, Digits: 1}, "100e+03"},
+		{"Engineering small", -0.00012, Options{Notation: Engineering}, "-120e-06"},
+		{"Engineering unshifted", 1500, Options{Notation: Engineering}, "1.5e+03"},
+		{"Grouping", -1234567.25, Options{Notation: Fixed, Grouping: true}, "-1,234,567.25"},
+		{"Grouping with exponent", 1234.5, Options{Notation: Engineering, Grouping: true}, "1.2345e+03"},
+		{"Trim zeros", 1.5, Options{Notation: Fixed, Digits: 4, TrimZeros: true}, "1.5"},
+		{"Trim point", 2, Options{Notation: Fixed, Digits: 2, TrimZeros: true}, "2"},
+		{"Trim zeros keeps exponent", 1000, Options{Notation: Scientific, Digits: 3, TrimZeros: true}, "1e+03"},
+		{"Infinity", math.Inf(-1), Options{Notation: Fixed, Digits: 2}, "-Inf"},
+		{"NaN", math.NaN(), Options{Grouping: true}, "NaN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Float(tt.x, tt.opts); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestRat tests exact and decimal forms of rationals
+func TestRat(t *testing.T) {
+	tests := []struct {
+		name     string
+		x        *big.Rat
+		opts     Options
+		expected string
+	}{
+		{"Integer", big.NewRat(42, 1), Options{}, "42"},
+		{"Fraction", big.NewRat(-1, 3), Options{}, "-1/3"},
+		{"Grouped fraction", big.NewRat(1234567, 1000), Options{Grouping: true}, "1,234,567/1,000"},
+		{"Digits", big.NewRat(1, 3), Options{Digits: 5}, "0.33333"},
+		{"Fixed", big.NewRat(1, 8), Options{Notation: Fixed}, "0.125"},
+		{"Fixed with decimals", big.NewRat(2, 3), Options{Notation: Fixed, Digits: 3}, "0.667"},
+		{"Scientific", big.NewRat(1234, 1), Options{Notation: Scientific}, "1.234e+03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rat(tt.x, tt.opts); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	// Digits beyond float64 precision come from the exact value
+	huge := new(big.Rat).SetFrac(new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil), big.NewInt(3))
+	expected := "333333333333333333333333333333.33"
+	if got := Rat(huge, Options{Notation: Fixed, Digits: 2}); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestBigFloat tests that big floats default to the digits they hold
+func TestBigFloat(t *testing.T) {
+	third := new(big.Float).SetPrec(100).Quo(big.NewFloat(1), big.NewFloat(3))
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"Precision", Options{}, "0.333333333333333333333333333333"},
+		{"Digits", Options{Digits: 5}, "0.33333"},
+		{"Scientific", Options{Notation: Scientific, Digits: 3}, "3.33e-01"},
+		{"Engineering", Options{Notation: Engineering, Digits: 3}, "333e-03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BigFloat(third, tt.opts); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestBigFloatPrecision tests that big floats are written with the
+// requested number of significant digits, including trailing zeros
+func TestBigFloatPrecision(t *testing.T) {
+	tests := []struct {
+		precision int
+		expected  string
+	}{
+		{10, "1.414213562"},
+		{20, "1.4142135623730950488"},
+		{30, "1.41421356237309504880168872421"},
+		{50, "1.4142135623730950488016887242096980785696718753770"},
+		{100, "1.414213562373095048801688724209698078569671875376948073176679737990732478462107038850387534327641573"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.precision), func(t *testing.T) {
+			prec := uint(math.Ceil(float64(tt.precision) * math.Log2(10)))
+			sqrt2 := new(big.Float).SetPrec(prec).Sqrt(big.NewFloat(2))
+			if got := BigFloat(sqrt2, Options{Precision: tt.precision}); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			two := new(big.Float).SetPrec(prec).SetInt64(2)
+			if got := BigFloat(two, Options{Precision: tt.precision}); got != "2" {
+				t.Errorf("Expected exact 2 to be written as \"2\", got %q", got)
+			}
+		})
+	}
+
+	tenth := new(big.Float).SetPrec(100).SetFloat64(0.1)
+	if got, expected := BigFloat(tenth, Options{Precision: 5, TrimZeros: true}), "0.1"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestComplex tests the parts of complex numbers
+func TestComplex(t *testing.T) {
+	tests := []struct {
+		name     string
+		z        complex128
+		opts     Options
+		expected string
+	}{
+		{"Both parts", complex(1, 2), Options{}, "1+2i"},
+		{"Negative imaginary", complex(3, -1), Options{}, "3-i"},
+		{"Imaginary", complex(0, 2), Options{}, "2i"},
+		{"Negative unit", complex(0, -1), Options{}, "-i"},
+		{"Digits", complex(1.0/3, 2.0/3), Options{Digits: 3}, "0.333+0.667i"},
+		{"Scientific", complex(1500, -2500), Options{Notation: Scientific}, "1.5e+03-2.5e+03i"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Complex(tt.z, tt.opts); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestValue tests dispatch on the kind of value
+func TestValue(t *testing.T) {
+	km, _ := units.Lookup("km")
+	opts := Options{Digits: 3}
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{2.0 / 3, "0.667"},
+		{big.NewRat(2, 3), "0.667"},
+		{complex(0, 2.0/3), "0.667i"},
+		{units.New(2.0/3, km), "0.667 km"},
+		{true, "true"},
+		{[]interface{}{1.0, big.NewRat(1, 3), []interface{}{}}, "[1, 0.333, []]"},
+	}
+
+	for _, tt := range tests {
+		if got := Value(tt.value, opts); got != tt.expected {
+			t.Errorf("Value(%v): expected %q, got %q", tt.value, tt.expected, got)
+		}
+	}
+}
+
+// TestParseNotation tests notation names and aliases
+func TestParseNotation(t *testing.T) {
+	for name, expected := range map[string]Notation{
+		"auto":        Auto,
+		"fixed":       Fixed,
+		"FIX":         Fixed,
+		"sci":         Scientific,
+		"scientific":  Scientific,
+		"eng":         Engineering,
+		"Engineering": Engineering,
+	} {
+		n, err := ParseNotation(name)
+		if err != nil || n != expected {
+			t.Errorf("ParseNotation(%q) = %v, %v; expected %v", name, n, err, expected)
+		}
+	}
+	if _, err := ParseNotation("roman"); err == nil {
+		t.Error("Expected an error for an unknown notation")
+	}
+	if Scientific.String() != "sci" {
+		t.Errorf("Expected sci, got %s", Scientific)
+	}
+}
@@ -0,0 +1,112 @@
+// Package output writes command results as structured documents for
+// scripts: JSON, YAML or CSV. Commands print the text format themselves.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format.
+type Format string
+
+// Supported output formats.
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+	CSV  Format = "csv"
+)
+
+// Formats lists the supported output formats.
+var Formats = []Format{Text, JSON, YAML, CSV}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format %q (want %s)", s, strings.Join(names, ", "))
+}
+
+// Float is a number in a document. Unlike float64 it can be NaN or
+// infinite in every format: JSON, which has no such numbers, gets the
+// strings "NaN", "+Inf" and "-Inf", and YAML gets .nan, .inf and -.inf.
+type Float float64
+
+// MarshalJSON encodes a finite f as a JSON number and NaN and the
+// infinities as strings.
+func (f Float) MarshalJSON() ([]byte, error) {
+	x := float64(f)
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return json.Marshal(f.String())
+	}
+	return json.Marshal(x)
+}
+
+// String writes f in the shortest form that reads back exactly, as in CSV.
+func (f Float) String() string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 64)
+}
+
+// Floats converts xs to Floats.
+func Floats(xs []float64) []Float {
+	fs := make([]Float, len(xs))
+	for i, x := range xs {
+		fs[i] = Float(x)
+	}
+	return fs
+}
+
+// Table is implemented by documents with a tabular form, which is how they
+// are written as CSV.
+type Table interface {
+	// Table returns the column names and the rows of the table.
+	Table() (header []string, rows [][]string)
+}
+
+// Write encodes doc to w in a structured format. JSON and YAML encode doc
+// with its json and yaml field tags; CSV requires doc to implement Table.
+func Write(w io.Writer, format Format, doc interface{}) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	case CSV:
+		table, ok := doc.(Table)
+		if !ok {
+			return fmt.Errorf("csv output is not supported for %T", doc)
+		}
+		header, rows := table.Table()
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+	return fmt.Errorf("%s is not a structured output format", format)
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// point is a test document with a tabular form
+type point struct {
+	X    float64 `json:"x" yaml:"x"`
+	Y    float64 `json:"y" yaml:"y"`
+	Note string  `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+func (p point) Table() ([]string, [][]string) {
+	return []string{"x", "y", "note"}, [][]string{{"1", "2", p.Note}}
+}
+
+// TestParseFormat tests parsing format names
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Format
+		hasError bool
+	}{
+		{"text", Text, false},
+		{"json", JSON, false},
+		{"YAML", YAML, false},
+		{"csv", CSV, false},
+		{"xml", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if (err != nil) != tt.hasError || got != tt.expected {
+			t.Errorf("ParseFormat(%q): expected %q (error %v), got %q (%v)", tt.name, tt.expected, tt.hasError, got, err)
+		}
+	}
+}
+
+// TestWrite tests encoding a document in each structured format
+func TestWrite(t *testing.T) {
+	doc := point{X: 1, Y: 2, Note: "a, b"}
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{JSON, "{\n  \"x\": 1,\n  \"y\": 2,\n  \"note\": \"a, b\"\n}\n"},
+		{YAML, "x: 1\n\"y\": 2\nnote: a, b\n"},
+		{CSV, "x,y,note\n1,2,\"a, b\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, tt.format, doc); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := b.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestWriteErrors tests documents and formats that cannot be written
+func TestWriteErrors(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, CSV, map[string]int{"x": 1}); err == nil {
+		t.Errorf("Expected an error for CSV without a table, but got none")
+	}
+	if err := Write(&b, Text, point{}); err == nil {
+		t.Errorf("Expected an error for the text format, but got none")
+	}
+}
+
+// reading is a test document with a number that may not be finite
+type reading struct {
+	Value Float `json:"value" yaml:"value"`
+}
+
+func (r reading) Table() ([]string, [][]string) {
+	return []string{"value"}, [][]string{{r.Value.String()}}
+}
+
+// TestWriteNonFinite tests that NaN and the infinities are written in each
+// structured format
+func TestWriteNonFinite(t *testing.T) {
+	tests := []struct {
+		format   Format
+		value    float64
+		expected string
+	}{
+		{JSON, 1.5, "{\n  \"value\": 1.5\n}\n"},
+		{JSON, math.NaN(), "{\n  \"value\": \"NaN\"\n}\n"},
+		{JSON, math.Inf(1), "{\n  \"value\": \"+Inf\"\n}\n"},
+		{JSON, math.Inf(-1), "{\n  \"value\": \"-Inf\"\n}\n"},
+		{YAML, 1.5, "value: 1.5\n"},
+		{YAML, math.NaN(), "value: .nan\n"},
+		{YAML, math.Inf(1), "value: .inf\n"},
+		{YAML, math.Inf(-1), "value: -.inf\n"},
+		{CSV, math.NaN(), "value\nNaN\n"},
+		{CSV, math.Inf(1), "value\n+Inf\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format)+"/"+Float(tt.value).String(), func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, tt.format, reading{Value: Float(tt.value)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := b.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
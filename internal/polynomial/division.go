@@ -0,0 +1,146 @@
+package polynomial
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrZeroDivisor is returned when dividing by the zero polynomial.
+var ErrZeroDivisor = errors.New("division by the zero polynomial")
+
+// DivMod returns the quotient and remainder of the long division of p by q,
+// such that p = quo q + rem and the degree of rem is less than that of q.
+func (p Polynomial) DivMod(q Polynomial) (quo, rem Polynomial, err error) {
+	q = q.Normalize()
+	if len(q) == 0 {
+		return nil, nil, ErrZeroDivisor
+	}
+	rem = append(Polynomial{}, p.Normalize()...)
+	if len(rem) < len(q) {
+		return Polynomial{}, rem, nil
+	}
+	n := len(q) - 1
+	quo = make(Polynomial, len(rem)-n)
+	for i := len(quo) - 1; i >= 0; i-- {
+		c := rem[i+n] / q[n]
+		quo[i] = c
+		for j, b := range q[:n] {
+			rem[i+j] -= c * b
+		}
+		rem[i+n] = 0
+	}
+	return quo.Normalize(), rem[:n].Normalize(), nil
+}
+
+// SyntheticDiv divides p by x - r with synthetic division, returning the
+// quotient and the remainder, which is p(r).
+func (p Polynomial) SyntheticDiv(r float64) (quo Polynomial, rem float64) {
+	p = p.Normalize()
+	if len(p) == 0 {
+		return Polynomial{}, 0
+	}
+	quo = make(Polynomial, len(p)-1)
+	rem = p[len(p)-1]
+	for i := len(p) - 2; i >= 0; i-- {
+		quo[i] = rem
+		rem = rem*r + p[i]
+	}
+	return quo, rem
+}
+
+// Monic returns p divided by its leading coefficient, or the zero
+// polynomial if p is zero.
+func (p Polynomial) Monic() Polynomial {
+	lead := p.LeadingCoeff()
+	if lead == 0 {
+		return Polynomial{}
+	}
+	monic := p.Scale(1 / lead)
+	monic[len(monic)-1] = 1
+	return monic
+}
+
+// GCD returns the monic greatest common divisor of p and q, computed with
+// the Euclidean algorithm on monic remainders. With a tolerance tol > 0,
+// remainder coefficients no larger than tol times the largest coefficient
+// of the polynomials being divided are treated as zero, which finds the
+// common factors of polynomials whose coefficients carry rounding errors.
+// With tol = 0 only exact zeros count, and such polynomials are usually
+// found to be coprime. The GCD of two zero polynomials is zero.
+func GCD(p, q Polynomial, tol float64) Polynomial {
+	p, q = p.Monic(), q.Monic()
+	for len(q) > 0 {
+		// q is monic, so the division cannot fail
+		_, rem, _ := p.DivMod(q)
+		p, q = q, rem.chop(tol*math.Max(p.maxNorm(), q.maxNorm())).Monic()
+	}
+	return p
+}
+
+// LCM returns the monic least common multiple of p and q, with GCD's
+// tolerance tol, or zero if either is zero.
+func LCM(p, q Polynomial, tol float64) Polynomial {
+	g := GCD(p, q, tol)
+	if len(g) == 0 {
+		return Polynomial{}
+	}
+	quo, _, _ := p.Monic().DivMod(g)
+	return quo.Mul(q.Monic())
+}
+
+// SquareFree returns the square-free decomposition of p with Yun's
+// algorithm: monic polynomials a[0], a[1], ... without repeated roots or
+// common factors such that p = c a[0] a[1]^2 a[2]^3 ..., where c is the
+// leading coefficient of p. a[i] is 1 when p has no roots of multiplicity
+// i + 1. The GCDs are computed with the tolerance tol. A constant p has an
+// empty decomposition.
+func SquareFree(p Polynomial, tol float64) []Polynomial {
+	var factors []Polynomial
+	if p.Degree() < 1 {
+		return factors
+	}
+	p = p.Monic()
+	dp := p.Derivative()
+	g := GCD(p, dp, tol)
+	b, _, _ := p.DivMod(g)
+	c, _, _ := dp.DivMod(g)
+	d := yunDifference(c, b, tol)
+	for b.Degree() > 0 {
+		a := GCD(b, d, tol)
+		factors = append(factors, a)
+		b, _, _ = b.DivMod(a)
+		c, _, _ = d.DivMod(a)
+		d = yunDifference(c, b, tol)
+	}
+	return factors
+}
+
+// yunDifference returns c - b', which vanishes at the last step of Yun's
+// algorithm. Coefficients no larger than tol times those of c and b' are
+// set to zero, so that rounding errors left by the cancellation are not
+// mistaken for a factor.
+func yunDifference(c, b Polynomial, tol float64) Polynomial {
+	db := b.Derivative()
+	return c.Sub(db).chop(tol * math.Max(c.maxNorm(), db.maxNorm()))
+}
+
+// maxNorm returns the largest absolute value of the coefficients of p.
+func (p Polynomial) maxNorm() float64 {
+	norm := 0.0
+	for _, c := range p {
+		norm = math.Max(norm, math.Abs(c))
+	}
+	return norm
+}
+
+// chop returns p with the coefficients no larger than tol in absolute
+// value set to zero.
+func (p Polynomial) chop(tol float64) Polynomial {
+	chopped := make(Polynomial, len(p))
+	for i, c := range p {
+		if math.Abs(c) > tol {
+			chopped[i] = c
+		}
+	}
+	return chopped.Normalize()
+}
@@ -0,0 +1,125 @@
+package polynomial_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/polynomial"
+)
+
+// TestDivMod tests long division, checking p = quo q + rem
+func TestDivMod(t *testing.T) {
+	tests := []struct {
+		name     string
+		p, q     polynomial.Polynomial
+		quo, rem polynomial.Polynomial
+	}{
+		{"x^3 - 1 by x - 1", polynomial.Polynomial{-1, 0, 0, 1}, polynomial.Polynomial{-1, 1}, polynomial.Polynomial{1, 1, 1}, polynomial.Polynomial{}},
+		{"With a remainder", polynomial.Polynomial{1, 2, 3}, polynomial.Polynomial{1, 1}, polynomial.Polynomial{-1, 3}, polynomial.Polynomial{2}},
+		{"Non-monic divisor", polynomial.Polynomial{-1, 0, 4}, polynomial.Polynomial{1, 2}, polynomial.Polynomial{-1, 2}, polynomial.Polynomial{}},
+		{"Divisor of higher degree", polynomial.Polynomial{1, 1}, polynomial.Polynomial{0, 0, 1}, polynomial.Polynomial{}, polynomial.Polynomial{1, 1}},
+		{"By a constant", polynomial.Polynomial{2, 4}, polynomial.Polynomial{2}, polynomial.Polynomial{1, 2}, polynomial.Polynomial{}},
+		{"Trailing zeros", polynomial.Polynomial{-1, 0, 1, 0}, polynomial.Polynomial{1, 1, 0}, polynomial.Polynomial{-1, 1}, polynomial.Polynomial{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			quo, rem, err := tc.p.DivMod(tc.q)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !quo.Equal(tc.quo, epsilon) || !rem.Equal(tc.rem, epsilon) {
+				t.Errorf("DivMod = %v, %v; want %v, %v", quo, rem, tc.quo, tc.rem)
+			}
+			if got := quo.Mul(tc.q).Add(rem); !got.Equal(tc.p, epsilon) {
+				t.Errorf("quo q + rem = %v, want %v", got, tc.p)
+			}
+		})
+	}
+
+	if _, _, err := (polynomial.Polynomial{1, 1}).DivMod(polynomial.Polynomial{0}); !errors.Is(err, polynomial.ErrZeroDivisor) {
+		t.Errorf("Expected ErrZeroDivisor, got %v", err)
+	}
+}
+
+// TestSyntheticDiv tests dividing by x - r
+func TestSyntheticDiv(t *testing.T) {
+	p := polynomial.Polynomial{-6, 11, -6, 1}
+	quo, rem := p.SyntheticDiv(1)
+	if !quo.Equal(polynomial.Polynomial{6, -5, 1}, 0) || rem != 0 {
+		t.Errorf("SyntheticDiv(1) = %v, %v; want x^2 - 5x + 6, 0", quo, rem)
+	}
+	quo, rem = p.SyntheticDiv(4)
+	if !quo.Equal(polynomial.Polynomial{3, -2, 1}, 0) || rem != 6 {
+		t.Errorf("SyntheticDiv(4) = %v, %v; want x^2 - 2x + 3, 6", quo, rem)
+	}
+	quo, rem = polynomial.Polynomial{5}.SyntheticDiv(2)
+	if len(quo) != 0 || rem != 5 {
+		t.Errorf("SyntheticDiv of a constant = %v, %v; want 0, 5", quo, rem)
+	}
+}
+
+// TestGCD tests greatest common divisors and least common multiples
+func TestGCD(t *testing.T) {
+	tests := []struct {
+		name     string
+		p, q     polynomial.Polynomial
+		tol      float64
+		gcd, lcm polynomial.Polynomial
+	}{
+		{"Common root", polynomial.Polynomial{-1, 0, 0, 1}, polynomial.Polynomial{-1, 0, 1}, 0, polynomial.Polynomial{-1, 1}, polynomial.Polynomial{-1, -1, 0, 1, 1}},
+		{"Coprime", polynomial.Polynomial{1, 1}, polynomial.Polynomial{2, 1}, 0, polynomial.Polynomial{1}, polynomial.Polynomial{2, 3, 1}},
+		{"Divisor", polynomial.Polynomial{6, -5, 1}, polynomial.Polynomial{-4, 2}, 0, polynomial.Polynomial{-2, 1}, polynomial.Polynomial{6, -5, 1}},
+		{"Repeated root", polynomial.Polynomial{-2, 3, 0, -1}, polynomial.Polynomial{3, -4, 1}, 0, polynomial.Polynomial{-1, 1}, polynomial.Polynomial{6, -11, 3, 3, -1}.Scale(-1)},
+		// (x - 0.1)(x - 0.2) and (x - 0.1)(x - 0.3) with rounded coefficients
+		{"Inexact coefficients", polynomial.Polynomial{0.02, -0.3, 1}, polynomial.Polynomial{0.03, -0.4, 1}, 1e-9, polynomial.Polynomial{-0.1, 1}, polynomial.Polynomial{-0.006, 0.11, -0.6, 1}},
+		{"With zero", polynomial.Polynomial{2, 2}, polynomial.Polynomial{}, 0, polynomial.Polynomial{1, 1}, polynomial.Polynomial{}},
+		{"Both zero", polynomial.Polynomial{}, polynomial.Polynomial{0}, 0, polynomial.Polynomial{}, polynomial.Polynomial{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := polynomial.GCD(tc.p, tc.q, tc.tol); !got.Equal(tc.gcd, 1e-6) {
+				t.Errorf("GCD(%v, %v) = %v, want %v", tc.p, tc.q, got, tc.gcd)
+			}
+			if got := polynomial.LCM(tc.p, tc.q, tc.tol); !got.Equal(tc.lcm, 1e-6) {
+				t.Errorf("LCM(%v, %v) = %v, want %v", tc.p, tc.q, got, tc.lcm)
+			}
+		})
+	}
+}
+
+// TestSquareFree tests square-free decomposition
+func TestSquareFree(t *testing.T) {
+	x1 := polynomial.Polynomial{-1, 1}  // x - 1
+	x2 := polynomial.Polynomial{2, 1}   // x + 2
+	x3 := polynomial.Polynomial{-3, 1}  // x - 3
+	xh := polynomial.Polynomial{0.5, 1} // x + 0.5
+
+	tests := []struct {
+		name string
+		p    polynomial.Polynomial
+		want []polynomial.Polynomial
+	}{
+		{"Square-free", x1.Mul(x2), []polynomial.Polynomial{x1.Mul(x2)}},
+		{"Square", x1.Pow(2), []polynomial.Polynomial{{1}, x1}},
+		{"Mixed multiplicities", x1.Mul(x2.Pow(2)).Mul(x3.Pow(3)).Scale(4), []polynomial.Polynomial{x1, x2, x3}},
+		{"Missing multiplicity", x2.Mul(xh.Pow(3)), []polynomial.Polynomial{x2, {1}, xh}},
+		{"Irreducible quadratic", polynomial.Polynomial{1, 0, 1}.Pow(2), []polynomial.Polynomial{{1}, {1, 0, 1}}},
+		{"Constant", polynomial.Polynomial{3}, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := polynomial.SquareFree(tc.p, 1e-9)
+			if len(got) != len(tc.want) {
+				t.Fatalf("SquareFree(%v) = %v, want %v", tc.p, got, tc.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tc.want[i], 1e-6) {
+					t.Errorf("SquareFree(%v) = %v, want %v", tc.p, got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,409 @@
+package polynomial
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+)
+
+// factorTolerance is the relative tolerance of the GCDs of the square-free
+// decomposition, and below which the imaginary part of a root is taken to
+// be rounding error.
+const factorTolerance = 1e-9
+
+// A Power is an irreducible factor of a polynomial raised to its
+// multiplicity.
+type Power struct {
+	// Factor is the monic factor with real coefficients: x - Root for a real
+	// Root, or the quadratic (x - Root)(x - conj(Root)) for a complex Root.
+	// It is nil for the complex linear factor x - Root.
+	Factor       Polynomial
+	Root         complex128
+	Multiplicity int
+}
+
+// Quadratic reports whether the factor is a real quadratic.
+func (f Power) Quadratic() bool {
+	return f.Factor.Degree() == 2
+}
+
+// String writes the factor as (x - 2)^2, (x^2 + 1) or (x + 1 - 2i).
+func (f Power) String() string {
+	return f.Format(format.Options{})
+}
+
+// Format writes the factor as String does, formatting the numbers with
+// opts.
+func (f Power) Format(opts format.Options) string {
+	return f.render(opts, false)
+}
+
+// LaTeX writes the factor as LaTeX math, formatting the numbers with opts.
+func (f Power) LaTeX(opts format.Options) string {
+	return f.render(opts, true)
+}
+
+// render writes the factor as text or as LaTeX. A factor of x is written
+// without parentheses.
+func (f Power) render(opts format.Options, latex bool) string {
+	base := "x" + signedTerm(-real(f.Root), "", opts, latex) + signedTerm(-imag(f.Root), "i", opts, latex)
+	if f.Factor != nil {
+		base = f.Factor.render(opts, latex)
+	}
+	return powerString(base, f.Multiplicity, latex)
+}
+
+// powerString writes the factor base raised to the multiplicity m, in
+// parentheses unless it is x.
+func powerString(base string, m int, latex bool) string {
+	if base != "x" {
+		base = "(" + base + ")"
+	}
+	switch {
+	case m <= 1:
+		return base
+	case latex:
+		return base + "^{" + strconv.Itoa(m) + "}"
+	}
+	return base + "^" + strconv.Itoa(m)
+}
+
+// signedTerm writes the term c unit after another term, as " + 2i" or
+// " - i", or nothing when c is zero.
+func signedTerm(c float64, unit string, opts format.Options, latex bool) string {
+	if c == 0 {
+		return ""
+	}
+	sign := " + "
+	if c < 0 {
+		sign = " - "
+	}
+	c = math.Abs(c)
+	if c == 1 && unit != "" {
+		return sign + unit
+	}
+	return sign + formatCoeff(c, opts, latex) + unit
+}
+
+// A Factorization is a polynomial written as its leading coefficient times
+// a product of powers of monic irreducible factors.
+type Factorization struct {
+	Leading float64
+	Factors []Power
+}
+
+// String writes the factorization as 2(x - 1)^2(x^2 + 1).
+func (f Factorization) String() string {
+	return f.Format(format.Options{})
+}
+
+// Format writes the factorization as String does, formatting the numbers
+// with opts.
+func (f Factorization) Format(opts format.Options) string {
+	return f.render(opts, false)
+}
+
+// LaTeX writes the factorization as LaTeX math, formatting the numbers
+// with opts.
+func (f Factorization) LaTeX(opts format.Options) string {
+	return f.render(opts, true)
+}
+
+// render writes the factorization as text or as LaTeX, omitting a leading
+// coefficient of 1.
+func (f Factorization) render(opts format.Options, latex bool) string {
+	factors := make([]string, len(f.Factors))
+	for i, factor := range f.Factors {
+		factors[i] = factor.render(opts, latex)
+	}
+	return productString(formatCoeff(f.Leading, opts, latex), f.Leading == 1, f.Leading == -1, factors)
+}
+
+// productString writes the product of a constant and factors. A constant
+// of 1 is omitted and one of -1 is written as a sign, unless there are no
+// factors.
+func productString(constant string, one, minusOne bool, factors []string) string {
+	switch {
+	case len(factors) == 0:
+		return constant
+	case one:
+		constant = ""
+	case minusOne:
+		constant = "-"
+	}
+	return constant + strings.Join(factors, "")
+}
+
+// Expand returns the product of the factorization. The imaginary parts of
+// the product of complex linear factors that are not in conjugate pairs
+// are dropped.
+func (f Factorization) Expand() Polynomial {
+	product := []complex128{complex(f.Leading, 0)}
+	for _, factor := range f.Factors {
+		q := []complex128{-factor.Root, 1}
+		if factor.Factor != nil {
+			q = make([]complex128, len(factor.Factor))
+			for i, c := range factor.Factor {
+				q[i] = complex(c, 0)
+			}
+		}
+		for i := 0; i < factor.Multiplicity; i++ {
+			product = mulComplex(product, q)
+		}
+	}
+	p := make(Polynomial, len(product))
+	for i, c := range product {
+		p[i] = real(c)
+	}
+	return p.Normalize()
+}
+
+// mulComplex returns the product of polynomials with complex coefficients.
+func mulComplex(p, q []complex128) []complex128 {
+	product := make([]complex128, len(p)+len(q)-1)
+	for i, a := range p {
+		for j, b := range q {
+			product[i+j] += a * b
+		}
+	}
+	return product
+}
+
+// Factorize factorizes a polynomial over the reals, into its leading
+// coefficient and powers of monic linear factors and irreducible quadratic
+// factors. Repeated factors are grouped with their multiplicities, found
+// from the square-free decomposition, and the roots of each square-free
+// part are found with FindRoots. Coefficients are first read as the exact
+// rationals Polynomial.Rat gives, so that FactorizeRational finds the
+// rational roots, rounded once to float64, and the multiplicities exactly,
+// and only the factors without rational roots are factorized numerically.
+// Otherwise, as the coefficients are floating point, distinct roots closer
+// than about the square root of the tolerance of the decomposition, 1e-9,
+// are taken as a repeated root. Linear factors come first, in increasing
+// order of their roots, followed by the quadratics. FactorizeRational
+// returns the exact factorization itself.
+func Factorize(coefficients Polynomial) (Factorization, error) {
+	return factorize(coefficients, false)
+}
+
+// FactorizeComplex factorizes a polynomial over the complex numbers, into
+// its leading coefficient and powers of monic linear factors, in
+// increasing order of the real and then imaginary parts of their roots.
+func FactorizeComplex(coefficients Polynomial) (Factorization, error) {
+	return factorize(coefficients, true)
+}
+
+// factorize factorizes p over the reals, or with complexRoots over the
+// complex numbers.
+func factorize(p Polynomial, complexRoots bool) (Factorization, error) {
+	p = p.Normalize()
+	if len(p) == 0 {
+		return Factorization{}, fmt.Errorf("cannot factorize the zero polynomial")
+	}
+	if r, err := p.Rat(); err == nil {
+		exact, err := FactorizeRational(r)
+		if err != nil {
+			return Factorization{}, err
+		}
+		return factorizeExact(p, exact, complexRoots)
+	}
+	f := Factorization{Leading: p.LeadingCoeff()}
+
+	// A root at zero is exact: x^k divides p when its k lowest coefficients
+	// are zero
+	k := 0
+	for p[k] == 0 {
+		k++
+	}
+	if k > 0 {
+		f.Factors = append(f.Factors, Power{Factor: Polynomial{0, 1}, Multiplicity: k})
+	}
+	p = p[k:].Monic()
+
+	parts := SquareFree(p, factorTolerance)
+	degree := 0
+	for i, part := range parts {
+		degree += (i + 1) * part.Degree()
+	}
+	if degree != p.Degree() {
+		// The decomposition was spoilt by rounding errors
+		parts = []Polynomial{p}
+	}
+
+	for i, part := range parts {
+		if part.Degree() < 1 {
+			continue
+		}
+		roots, err := FindRoots(part)
+		if err != nil {
+			return Factorization{}, err
+		}
+		factors, err := rootFactors(p, roots, i+1, complexRoots)
+		if err != nil {
+			return Factorization{}, err
+		}
+		f.Factors = append(f.Factors, factors...)
+	}
+
+	sortFactors(f.Factors)
+	return f, nil
+}
+
+// factorizeExact factorizes p over the reals, or with complexRoots over the
+// complex numbers, from its exact factorization over the rationals. The
+// linear factors are rounded from their exact roots and the others are
+// factorized numerically.
+func factorizeExact(p Polynomial, exact RatFactorization, complexRoots bool) (Factorization, error) {
+	f := Factorization{Leading: p.LeadingCoeff()}
+	for _, power := range exact.Factors {
+		q := power.Factor
+		if q.Degree() == 1 {
+			c, _ := new(big.Rat).Quo(q[0], q[1]).Float64()
+			f.Factors = append(f.Factors, Power{Factor: Polynomial{c, 1}, Root: complex(-c, 0), Multiplicity: power.Multiplicity})
+			continue
+		}
+		monic := q.Float().Monic()
+		roots, err := FindRoots(monic)
+		if err != nil {
+			return Factorization{}, err
+		}
+		factors, err := rootFactors(monic, roots, 1, complexRoots)
+		if err != nil {
+			return Factorization{}, err
+		}
+		for _, factor := range factors {
+			factor.Multiplicity = power.Multiplicity
+			f.Factors = append(f.Factors, factor)
+		}
+	}
+	sortFactors(f.Factors)
+	return f, nil
+}
+
+// sortFactors puts the linear factors first, in increasing order of the
+// real and then imaginary parts of their roots, followed by the quadratics
+// in the same order.
+func sortFactors(factors []Power) {
+	sort.SliceStable(factors, func(i, j int) bool {
+		a, b := factors[i], factors[j]
+		switch {
+		case a.Quadratic() != b.Quadratic():
+			return !a.Quadratic()
+		case real(a.Root) != real(b.Root):
+			return real(a.Root) < real(b.Root)
+		}
+		return imag(a.Root) < imag(b.Root)
+	})
+}
+
+// rootFactors returns the factors of multiplicity m of p for roots of a
+// square-free part of p: the linear factors of the roots, or without
+// complexRoots the linear factors of the real roots and a quadratic factor
+// for each pair of complex conjugate roots. The roots are first refined
+// against p, as the square-free parts carry rounding errors.
+func rootFactors(p Polynomial, roots []complex128, m int, complexRoots bool) ([]Power, error) {
+	var factors []Power
+	pairs := 0
+	for _, z := range roots {
+		z = polish(p, z, m)
+		if math.Abs(imag(z)) <= factorTolerance*math.Max(1, cmplx.Abs(z)) {
+			z = complex(real(z), 0)
+		}
+		switch {
+		case imag(z) == 0:
+			r := snap(p, Polynomial{-real(z), 1})
+			factors = append(factors, Power{Factor: r, Root: complex(-r[0], 0), Multiplicity: m})
+		case complexRoots:
+			factors = append(factors, Power{Root: z, Multiplicity: m})
+		case imag(z) > 0:
+			q := snap(p, Polynomial{real(z)*real(z) + imag(z)*imag(z), -2 * real(z), 1})
+			re := -q[1] / 2
+			factors = append(factors, Power{Factor: q, Root: complex(re, math.Sqrt(q[0]-re*re)), Multiplicity: m})
+			pairs++
+		default:
+			pairs--
+		}
+	}
+	if pairs != 0 {
+		return nil, fmt.Errorf("the complex roots of %v are not in conjugate pairs", p)
+	}
+	return factors, nil
+}
+
+// polish refines a root z of multiplicity m of p with Newton's method
+// modified for multiple roots, z - m p(z)/p'(z), while the steps reduce
+// |p(z)|.
+func polish(p Polynomial, z complex128, m int) complex128 {
+	dp := p.Derivative()
+	pz := cmplx.Abs(evaluatePolynomial(p, z))
+	for i := 0; i < 10 && pz > 0; i++ {
+		d := evaluatePolynomial(dp, z)
+		if d == 0 {
+			break
+		}
+		next := z - complex(float64(m), 0)*evaluatePolynomial(p, z)/d
+		pn := cmplx.Abs(evaluatePolynomial(p, next))
+		if !(pn < pz) {
+			break
+		}
+		z, pz = next, pn
+	}
+	return z
+}
+
+// snap returns the monic linear or quadratic factor q of p with its
+// coefficients rounded to integers when they are within rounding error of
+// them, and when p vanishes at a root of the rounded factor to within the
+// rounding error of evaluating it, so that a factor found as
+// x - 0.9999999999999998 is written as x - 1.
+func snap(p Polynomial, q Polynomial) Polynomial {
+	snapped := make(Polynomial, len(q))
+	for i, c := range q {
+		snapped[i] = c
+		if r := math.Round(c); math.Abs(c-r) <= factorTolerance*math.Max(1, math.Abs(c)) {
+			snapped[i] = r
+		}
+	}
+	if snapped.Equal(q, 0) {
+		return q
+	}
+	z, ok := factorRoot(snapped)
+	if !ok {
+		return q
+	}
+	if pz := cmplx.Abs(evaluatePolynomial(p, z)); pz <= roundingError(p, z) || pz <= residual(p, q) {
+		return snapped
+	}
+	return q
+}
+
+// residual returns |p(z)| at a root z of the monic linear or quadratic
+// factor q, or +Inf if q is a quadratic with real roots.
+func residual(p, q Polynomial) float64 {
+	z, ok := factorRoot(q)
+	if !ok {
+		return math.Inf(1)
+	}
+	return cmplx.Abs(evaluatePolynomial(p, z))
+}
+
+// factorRoot returns the root of the monic linear factor q, or the root
+// with a positive imaginary part of the monic quadratic factor q. It
+// reports false if q is a quadratic with real roots.
+func factorRoot(q Polynomial) (complex128, bool) {
+	if len(q) != 3 {
+		return complex(-q[0], 0), true
+	}
+	re := -q[1] / 2
+	d := q[0] - re*re
+	if d <= 0 {
+		return 0, false
+	}
+	return complex(re, math.Sqrt(d)), true
+}
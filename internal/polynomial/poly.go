@@ -0,0 +1,245 @@
+package polynomial
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+)
+
+// Polynomial is a polynomial in x with real coefficients, in increasing
+// order of degree: p[i] is the coefficient of x^i. Trailing zero
+// coefficients are allowed; Normalize removes them. The methods returning
+// a Polynomial never modify their operands and return normalized results.
+type Polynomial []float64
+
+// Degree returns the degree of p, the highest power of x with a non-zero
+// coefficient, or -1 for the zero polynomial.
+func (p Polynomial) Degree() int {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i] != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// LeadingCoeff returns the coefficient of the highest power of x in p, or 0
+// for the zero polynomial.
+func (p Polynomial) LeadingCoeff() float64 {
+	if d := p.Degree(); d >= 0 {
+		return p[d]
+	}
+	return 0
+}
+
+// Normalize returns p without its trailing zero coefficients, which is
+// empty for the zero polynomial. The result shares p's storage.
+func (p Polynomial) Normalize() Polynomial {
+	return p[:p.Degree()+1]
+}
+
+// Add returns p + q.
+func (p Polynomial) Add(q Polynomial) Polynomial {
+	sum := make(Polynomial, max(len(p), len(q)))
+	copy(sum, p)
+	for i, c := range q {
+		sum[i] += c
+	}
+	return sum.Normalize()
+}
+
+// Sub returns p - q.
+func (p Polynomial) Sub(q Polynomial) Polynomial {
+	return p.Add(q.Scale(-1))
+}
+
+// Mul returns p q.
+func (p Polynomial) Mul(q Polynomial) Polynomial {
+	p, q = p.Normalize(), q.Normalize()
+	if len(p) == 0 || len(q) == 0 {
+		return Polynomial{}
+	}
+	product := make(Polynomial, len(p)+len(q)-1)
+	for i, a := range p {
+		for j, b := range q {
+			product[i+j] += a * b
+		}
+	}
+	return product.Normalize()
+}
+
+// Scale returns c p.
+func (p Polynomial) Scale(c float64) Polynomial {
+	scaled := make(Polynomial, len(p))
+	for i, a := range p {
+		scaled[i] = c * a
+	}
+	return scaled.Normalize()
+}
+
+// Compose returns p(q(x)), evaluated with Horner's scheme.
+func (p Polynomial) Compose(q Polynomial) Polynomial {
+	result := Polynomial{}
+	for i := p.Degree(); i >= 0; i-- {
+		result = result.Mul(q).Add(Polynomial{p[i]})
+	}
+	return result
+}
+
+// Pow returns p^n by repeated squaring. It panics if n is negative.
+func (p Polynomial) Pow(n int) Polynomial {
+	if n < 0 {
+		panic("polynomial: negative exponent")
+	}
+	result, base := Polynomial{1}, p
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result = result.Mul(base)
+		}
+		if n > 1 {
+			base = base.Mul(base)
+		}
+	}
+	return result
+}
+
+// Derivative returns the derivative of p.
+func (p Polynomial) Derivative() Polynomial {
+	if len(p) < 2 {
+		return Polynomial{}
+	}
+	d := make(Polynomial, len(p)-1)
+	for i := range d {
+		d[i] = float64(i+1) * p[i+1]
+	}
+	return d.Normalize()
+}
+
+// Equal reports whether every coefficient of p is within tol of the
+// coefficient of the same power of x in q. Missing coefficients are zero.
+func (p Polynomial) Equal(q Polynomial, tol float64) bool {
+	for i := 0; i < max(len(p), len(q)); i++ {
+		var a, b float64
+		if i < len(p) {
+			a = p[i]
+		}
+		if i < len(q) {
+			b = q[i]
+		}
+		if !(math.Abs(a-b) <= tol) {
+			return false
+		}
+	}
+	return true
+}
+
+// String writes p in the form ParsePolynomial reads, from the highest
+// power down: x^3 - 6x^2 + 11x - 6.
+func (p Polynomial) String() string {
+	return p.Format(format.Options{})
+}
+
+// Format writes p as String does, formatting the coefficients with opts.
+func (p Polynomial) Format(opts format.Options) string {
+	return p.render(opts, false)
+}
+
+// LaTeX writes p as LaTeX math, formatting the coefficients with opts:
+// 1.5 \times 10^{-3}x^{2} - x + 4.
+func (p Polynomial) LaTeX(opts format.Options) string {
+	return p.render(opts, true)
+}
+
+// render writes the non-zero terms of p from the highest power down, as
+// text or as LaTeX.
+func (p Polynomial) render(opts format.Options, latex bool) string {
+	return renderTerms(p.Degree(), func(i int) (int, bool, string) {
+		switch c := p[i]; {
+		case c == 0:
+			return 0, false, ""
+		case c < 0:
+			return -1, c == -1, formatCoeff(-c, opts, latex)
+		default:
+			return 1, c == 1, formatCoeff(c, opts, latex)
+		}
+	}, formatCoeff(0, opts, latex), latex)
+}
+
+// renderTerms writes the terms of a polynomial of degree d from the highest
+// power down, as text or as LaTeX, or zero for the zero polynomial. term
+// returns the sign of the coefficient of x^i, which is 0 to omit the term,
+// whether its magnitude is 1, which is then omitted except in the constant
+// term, and its magnitude written out.
+func renderTerms(d int, term func(i int) (sign int, one bool, magnitude string), zero string, latex bool) string {
+	if d < 0 {
+		return zero
+	}
+	var b strings.Builder
+	for i := d; i >= 0; i-- {
+		sign, one, magnitude := term(i)
+		switch {
+		case sign == 0:
+			continue
+		case i == d && sign < 0:
+			b.WriteString("-")
+		case i < d && sign < 0:
+			b.WriteString(" - ")
+		case i < d:
+			b.WriteString(" + ")
+		}
+		if !one || i == 0 {
+			b.WriteString(magnitude)
+		}
+		switch {
+		case i == 0:
+		case i == 1:
+			b.WriteString("x")
+		case latex:
+			b.WriteString("x^{" + strconv.Itoa(i) + "}")
+		default:
+			b.WriteString("x^" + strconv.Itoa(i))
+		}
+	}
+	return b.String()
+}
+
+// formatCoeff formats the number c with opts, as LaTeX with latex.
+func formatCoeff(c float64, opts format.Options, latex bool) string {
+	s := format.Float(c, opts)
+	if latex {
+		return latexNumber(s)
+	}
+	return s
+}
+
+// latexNumber rewrites a number formatted by the format package as LaTeX,
+// with an exponent as a power of ten, a fraction with \frac, and braces
+// around the thousands separators so that LaTeX does not space them as
+// punctuation.
+func latexNumber(s string) string {
+	s = strings.ReplaceAll(s, ",", "{,}")
+	switch s {
+	case "+Inf":
+		return `\infty`
+	case "NaN":
+		return `\mathrm{NaN}`
+	}
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		sign := ""
+		if strings.HasPrefix(num, "-") {
+			sign, num = "-", num[1:]
+		}
+		return sign + `\frac{` + num + `}{` + den + `}`
+	}
+	mantissa, exponent, ok := strings.Cut(s, "e")
+	if !ok {
+		return s
+	}
+	n, err := strconv.Atoi(exponent)
+	if err != nil {
+		return s
+	}
+	return mantissa + ` \times 10^{` + strconv.Itoa(n) + `}`
+}
@@ -0,0 +1,130 @@
+package polynomial_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/polynomial"
+)
+
+// TestArithmetic tests the arithmetic methods of Polynomial
+func TestArithmetic(t *testing.T) {
+	p := polynomial.Polynomial{-1, 0, 1} // x^2 - 1
+	q := polynomial.Polynomial{1, 1}     // x + 1
+
+	tests := []struct {
+		name string
+		got  polynomial.Polynomial
+		want polynomial.Polynomial
+	}{
+		{"Add", p.Add(q), polynomial.Polynomial{0, 1, 1}},
+		{"Add cancels the leading term", p.Add(polynomial.Polynomial{0, 0, -1}), polynomial.Polynomial{-1}},
+		{"Sub", p.Sub(q), polynomial.Polynomial{-2, -1, 1}},
+		{"Sub of itself", p.Sub(p), polynomial.Polynomial{}},
+		{"Mul", p.Mul(q), polynomial.Polynomial{-1, -1, 1, 1}},
+		{"Mul by zero", p.Mul(polynomial.Polynomial{0}), polynomial.Polynomial{}},
+		{"Scale", p.Scale(2), polynomial.Polynomial{-2, 0, 2}},
+		{"Scale by zero", p.Scale(0), polynomial.Polynomial{}},
+		{"Compose", p.Compose(q), polynomial.Polynomial{0, 2, 1}},
+		{"Compose with a constant", q.Compose(polynomial.Polynomial{3}), polynomial.Polynomial{4}},
+		{"Pow", q.Pow(3), polynomial.Polynomial{1, 3, 3, 1}},
+		{"Pow of zero degree", p.Pow(0), polynomial.Polynomial{1}},
+		{"Normalize", polynomial.Polynomial{1, 2, 0, 0}.Normalize(), polynomial.Polynomial{1, 2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.got, tc.want) {
+				t.Errorf("got %v, want %v", []float64(tc.got), []float64(tc.want))
+			}
+		})
+	}
+
+	if got := p; !reflect.DeepEqual(got, polynomial.Polynomial{-1, 0, 1}) {
+		t.Errorf("Operands were modified: %v", []float64(got))
+	}
+}
+
+// TestDegree tests Degree and LeadingCoeff, including the zero polynomial
+func TestDegree(t *testing.T) {
+	tests := []struct {
+		p       polynomial.Polynomial
+		degree  int
+		leading float64
+	}{
+		{polynomial.Polynomial{-6, 11, -6, 1}, 3, 1},
+		{polynomial.Polynomial{1, -2, 0, 0}, 1, -2},
+		{polynomial.Polynomial{5}, 0, 5},
+		{polynomial.Polynomial{0, 0}, -1, 0},
+		{nil, -1, 0},
+	}
+
+	for _, tc := range tests {
+		if got := tc.p.Degree(); got != tc.degree {
+			t.Errorf("Degree(%v) = %d, want %d", []float64(tc.p), got, tc.degree)
+		}
+		if got := tc.p.LeadingCoeff(); got != tc.leading {
+			t.Errorf("LeadingCoeff(%v) = %v, want %v", []float64(tc.p), got, tc.leading)
+		}
+	}
+}
+
+// TestEqual tests comparing polynomials within a tolerance
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		p, q polynomial.Polynomial
+		tol  float64
+		want bool
+	}{
+		{"Identical", polynomial.Polynomial{1, 2}, polynomial.Polynomial{1, 2}, 0, true},
+		{"Within tolerance", polynomial.Polynomial{1, 2}, polynomial.Polynomial{1 + 1e-12, 2}, 1e-9, true},
+		{"Outside tolerance", polynomial.Polynomial{1, 2}, polynomial.Polynomial{1.1, 2}, 1e-9, false},
+		{"Trailing zeros", polynomial.Polynomial{1, 2, 0}, polynomial.Polynomial{1, 2}, 0, true},
+		{"Different degrees", polynomial.Polynomial{1, 2, 3}, polynomial.Polynomial{1, 2}, 1e-9, false},
+		{"NaN", polynomial.Polynomial{math.NaN()}, polynomial.Polynomial{math.NaN()}, 1, false},
+	}
+
+	for _, tc := range tests {
+		if got := tc.p.Equal(tc.q, tc.tol); got != tc.want {
+			t.Errorf("%s: Equal = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestString tests writing polynomials as text and as LaTeX
+func TestString(t *testing.T) {
+	tests := []struct {
+		p     polynomial.Polynomial
+		opts  format.Options
+		text  string
+		latex string
+	}{
+		{polynomial.Polynomial{-6, 11, -6, 1}, format.Options{}, "x^3 - 6x^2 + 11x - 6", "x^{3} - 6x^{2} + 11x - 6"},
+		{polynomial.Polynomial{0, -1}, format.Options{}, "-x", "-x"},
+		{polynomial.Polynomial{1, 0, -2.5}, format.Options{}, "-2.5x^2 + 1", "-2.5x^{2} + 1"},
+		{polynomial.Polynomial{-1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, format.Options{}, "x^11 - 1", "x^{11} - 1"},
+		{polynomial.Polynomial{4, -1, 0.0015}, format.Options{Notation: format.Scientific, Digits: 2}, "1.5e-03x^2 - x + 4.0e+00", `1.5 \times 10^{-3}x^{2} - x + 4.0 \times 10^{0}`},
+		{polynomial.Polynomial{1234.5, 1}, format.Options{Grouping: true}, "x + 1,234.5", "x + 1{,}234.5"},
+		{polynomial.Polynomial{math.Sqrt2, 0, 0}, format.Options{Digits: 3}, "1.41", "1.41"},
+		{polynomial.Polynomial{}, format.Options{}, "0", "0"},
+	}
+
+	for _, tc := range tests {
+		if got := tc.p.Format(tc.opts); got != tc.text {
+			t.Errorf("Format(%v) = %q, want %q", []float64(tc.p), got, tc.text)
+		}
+		if got := tc.p.LaTeX(tc.opts); got != tc.latex {
+			t.Errorf("LaTeX(%v) = %q, want %q", []float64(tc.p), got, tc.latex)
+		}
+	}
+
+	// String writes the form ParsePolynomial reads
+	p := polynomial.Polynomial{6, -5, 1}
+	parsed, err := polynomial.ParsePolynomial(p.String())
+	if err != nil || !parsed.Equal(p, 0) {
+		t.Errorf("ParsePolynomial(%q) = %v, %v; want %v", p.String(), parsed, err, []float64(p))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
 	"github.com/trenchesdeveloper/gomathpro/internal/polynomial"
 )
 
@@ -44,23 +45,23 @@ func complexesAlmostEqual(a, b []complex128, tol float64) bool {
 func TestParsePolynomial(t *testing.T) {
 	tests := []struct {
 		input   string
-		want    []float64 // c0, c1, c2, ...
+		want    polynomial.Polynomial // c0, c1, c2, ...
 		wantErr bool
 	}{
 		// Constant only
-		{"5", []float64{5}, false},
-		{"-3", []float64{-3}, false},
+		{"5", polynomial.Polynomial{5}, false},
+		{"-3", polynomial.Polynomial{-3}, false},
 		// Simple linear
-		{"x", []float64{0, 1}, false},
-		{"-x", []float64{0, -1}, false},
-		{"2x", []float64{0, 2}, false},
-		{"2x+1", []float64{1, 2}, false},
+		{"x", polynomial.Polynomial{0, 1}, false},
+		{"-x", polynomial.Polynomial{0, -1}, false},
+		{"2x", polynomial.Polynomial{0, 2}, false},
+		{"2x+1", polynomial.Polynomial{1, 2}, false},
 		// Simple quadratic
-		{"x^2", []float64{0, 0, 1}, false},
-		{"-3x^2", []float64{0, 0, -3}, false},
-		{"x^2 - 5x + 6", []float64{6, -5, 1}, false},
+		{"x^2", polynomial.Polynomial{0, 0, 1}, false},
+		{"-3x^2", polynomial.Polynomial{0, 0, -3}, false},
+		{"x^2 - 5x + 6", polynomial.Polynomial{6, -5, 1}, false},
 		// Mixed degrees (cubic example)
-		{"x^3 - 6x^2 + 11x - 6", []float64{-6, 11, -6, 1}, false},
+		{"x^3 - 6x^2 + 11x - 6", polynomial.Polynomial{-6, 11, -6, 1}, false},
 		// Invalid
 		{"", nil, true},
 		{"abc", nil, true},
@@ -190,32 +191,92 @@ func TestFactorize(t *testing.T) {
 	tests := []struct {
 		name    string
 		coeffs  []float64
-		want    []string
+		want    string
 		wantErr bool
 	}{
 		{
-			name:   "Linear: 2x - 4 => factor (x - 2)",
+			name:   "Linear: 2x - 4 => 2(x - 2)",
 			coeffs: []float64{-4, 2},
-			want:   []string{"(x - 2.00)"},
+			want:   "2(x - 2)",
 		},
 		{
-			name:   "Linear: x + 1 => factor (x - -1.00)",
+			name:   "Linear: x + 1 => factor (x + 1)",
 			coeffs: []float64{1, 1},
-			want:   []string{"(x - -1.00)"},
+			want:   "(x + 1)",
 		},
 		{
-			name:   "Quadratic: x^2 - 5x + 6 => (x - 2.00)(x - 3.00)",
+			name:   "Quadratic: x^2 - 5x + 6 => (x - 2)(x - 3)",
 			coeffs: []float64{6, -5, 1},
-			want:   []string{"(x - 2.00)", "(x - 3.00)"},
+			want:   "(x - 2)(x - 3)",
 		},
 		{
-			name:    "Quadratic complex => error",
-			coeffs:  []float64{1, 0, 1}, // x^2+1=0 => i, -i => not factorable over reals
-			wantErr: true,
+			name:   "Quadratic complex => irreducible",
+			coeffs: []float64{1, 0, 1}, // x^2+1=0 => i, -i => not factorable over reals
+			want:   "(x^2 + 1)",
+		},
+		{
+			name:   "Cubic: x^3 - 6x^2 + 11x - 6 => (x - 1)(x - 2)(x - 3)",
+			coeffs: []float64{-6, 11, -6, 1},
+			want:   "(x - 1)(x - 2)(x - 3)",
+		},
+		{
+			name:   "Repeated root: x^3 - 3x + 2 => (x - 1)^2(x + 2)",
+			coeffs: []float64{2, -3, 0, 1},
+			want:   "(x + 2)(x - 1)^2",
+		},
+		{
+			name:   "Root at zero: x^4 - x^3 => x^3(x - 1)",
+			coeffs: []float64{0, 0, 0, -1, 1},
+			want:   "x^3(x - 1)",
+		},
+		{
+			name:   "Mixed: 2x^4 - 2 => 2(x + 1)(x - 1)(x^2 + 1)",
+			coeffs: []float64{-2, 0, 0, 0, 2},
+			want:   "2(x + 1)(x - 1)(x^2 + 1)",
+		},
+		{
+			name:   "Repeated quadratic: (x^2 + 2x + 5)^2",
+			coeffs: []float64{25, 20, 14, 4, 1},
+			want:   "(x^2 + 2x + 5)^2",
+		},
+		{
+			name:   "Negative leading coefficient: -x^2 + 1",
+			coeffs: []float64{1, 0, -1},
+			want:   "-(x + 1)(x - 1)",
+		},
+		{
+			name:   "Degree 6: (x + 3)^3 (x - 2)^2 (x - 0.5)",
+			coeffs: polynomial.Polynomial{3, 1}.Pow(3).Mul(polynomial.Polynomial{-2, 1}.Pow(2)).Mul(polynomial.Polynomial{-0.5, 1}),
+			want:   "(x + 3)^3(x - 0.5)(x - 2)^2",
+		},
+		{
+			name:   "Rational roots: 6x^3 - 11x^2 + 6x - 1",
+			coeffs: []float64{-1, 6, -11, 6},
+			want:   "6(x - 0.3333333333333333)(x - 0.5)(x - 1)",
+		},
+		{
+			name:   "Close rational roots: (x - 1)(x - 1.000001)",
+			coeffs: []float64{1.000001, -2.000001, 1},
+			want:   "(x - 1)(x - 1.000001)",
+		},
+		{
+			name:   "Irreducible quartic: x^4 + 1",
+			coeffs: []float64{1, 0, 0, 0, 1},
+			want:   "(x^2 + 1.414213562373095x + 1)(x^2 - 1.4142135623730951x + 1)",
 		},
 		{
-			name:    "Cubic => error not supported",
-			coeffs:  []float64{-6, 11, -6, 1}, // x^3 - 6x^2 + ...
+			name:   "Trailing zero coefficient",
+			coeffs: []float64{-1, 1, 0},
+			want:   "(x - 1)",
+		},
+		{
+			name:   "Constant",
+			coeffs: []float64{5},
+			want:   "5",
+		},
+		{
+			name:    "Zero => error",
+			coeffs:  []float64{0, 0},
 			wantErr: true,
 		},
 	}
@@ -226,13 +287,92 @@ func TestFactorize(t *testing.T) {
 			if (err != nil) != tc.wantErr {
 				t.Fatalf("Factorize(%v) error = %v, wantErr = %v", tc.coeffs, err, tc.wantErr)
 			}
-			if err == nil && !reflect.DeepEqual(factors, tc.want) {
-				t.Errorf("Factorize(%v) = %v, want %v", tc.coeffs, factors, tc.want)
+			if err != nil {
+				return
+			}
+			if got := factors.String(); got != tc.want {
+				t.Errorf("Factorize(%v) = %s, want %s", tc.coeffs, got, tc.want)
+			}
+			if got := factors.Expand(); !got.Equal(tc.coeffs, 1e-9) {
+				t.Errorf("Expand(Factorize(%v)) = %v", tc.coeffs, []float64(got))
 			}
 		})
 	}
 }
 
+// TestFactorizeComplex tests factorization into complex linear factors
+func TestFactorizeComplex(t *testing.T) {
+	tests := []struct {
+		coeffs []float64
+		want   string
+	}{
+		{[]float64{1, 0, 1}, "(x + i)(x - i)"},
+		{[]float64{5, 2, 1}, "(x + 1 + 2i)(x + 1 - 2i)"},
+		{[]float64{-2, 0, 0, 0, 2}, "2(x + 1)(x + i)(x - i)(x - 1)"},
+		{[]float64{25, 20, 14, 4, 1}, "(x + 1 + 2i)^2(x + 1 - 2i)^2"},
+		{[]float64{6, -5, 1}, "(x - 2)(x - 3)"},
+	}
+
+	for _, tc := range tests {
+		factors, err := polynomial.FactorizeComplex(tc.coeffs)
+		if err != nil {
+			t.Fatalf("FactorizeComplex(%v) error = %v", tc.coeffs, err)
+		}
+		if got := factors.String(); got != tc.want {
+			t.Errorf("FactorizeComplex(%v) = %s, want %s", tc.coeffs, got, tc.want)
+		}
+		if got := factors.Expand(); !got.Equal(tc.coeffs, 1e-9) {
+			t.Errorf("Expand(FactorizeComplex(%v)) = %v", tc.coeffs, []float64(got))
+		}
+	}
+}
+
+// TestFactorizationFormat tests writing factorizations with a number format
+// and as LaTeX
+func TestFactorizationFormat(t *testing.T) {
+	factors, err := polynomial.Factorize(polynomial.Polynomial{-6, 0, 3})
+	if err != nil {
+		t.Fatalf("Factorize error: %v", err)
+	}
+	if got, want := factors.Format(format.Options{Digits: 4}), "3(x + 1.414)(x - 1.414)"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+
+	f := polynomial.Factorization{Leading: -0.5, Factors: []polynomial.Power{
+		{Factor: polynomial.Polynomial{0, 1}, Multiplicity: 2},
+		{Factor: polynomial.Polynomial{-2, 1}, Root: 2, Multiplicity: 10},
+		{Factor: polynomial.Polynomial{2, 2, 1}, Root: complex(-1, 1), Multiplicity: 1},
+		{Root: complex(0.5, -1), Multiplicity: 1},
+	}}
+	if got, want := f.String(), "-0.5x^2(x - 2)^10(x^2 + 2x + 2)(x - 0.5 + i)"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	if got, want := f.LaTeX(format.Options{}), "-0.5x^{2}(x - 2)^{10}(x^{2} + 2x + 2)(x - 0.5 + i)"; got != want {
+		t.Errorf("LaTeX = %q, want %q", got, want)
+	}
+}
+
+// TestPowerFormat tests writing factors with a number format
+func TestPowerFormat(t *testing.T) {
+	tests := []struct {
+		factor polynomial.Power
+		opts   format.Options
+		want   string
+	}{
+		{polynomial.Power{Factor: polynomial.Polynomial{-2, 1}, Root: 2, Multiplicity: 1}, format.Options{}, "(x - 2)"},
+		{polynomial.Power{Factor: polynomial.Polynomial{0.5, 1}, Root: -0.5, Multiplicity: 3}, format.Options{}, "(x + 0.5)^3"},
+		{polynomial.Power{Factor: polynomial.Polynomial{-math.Sqrt2, 1}, Root: math.Sqrt2, Multiplicity: 1}, format.Options{Digits: 4}, "(x - 1.414)"},
+		{polynomial.Power{Factor: polynomial.Polynomial{1234.5, 1}, Root: -1234.5, Multiplicity: 1}, format.Options{Notation: format.Fixed, Digits: 2, Grouping: true}, "(x + 1,234.50)"},
+		{polynomial.Power{Root: complex(1, -2), Multiplicity: 2}, format.Options{}, "(x - 1 + 2i)^2"},
+	}
+
+	for _, tc := range tests {
+		if got := tc.factor.Format(tc.opts); got != tc.want {
+			t.Errorf("Format(%v) = %q, want %q", tc.factor.Root, got, tc.want)
+		}
+	}
+}
+
 // ------------------------------------------------------------
 // 4) TEST Interpolate
 // ------------------------------------------------------------
@@ -0,0 +1,542 @@
+package polynomial
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+)
+
+// maxRootTheoremCoeff limits the leading and constant coefficients whose
+// divisors are tried as rational roots, as they are found by trial
+// division.
+const maxRootTheoremCoeff = 1 << 40
+
+// maxQuadraticCandidates limits the combinations of divisors tried when
+// searching for quadratic factors, beyond which none are looked for.
+const maxQuadraticCandidates = 1 << 20
+
+// RatPolynomial is a polynomial in x with exact rational coefficients, in
+// increasing order of degree like Polynomial. The coefficients must not be
+// nil. The methods returning a RatPolynomial never modify their operands
+// and return normalized results.
+type RatPolynomial []*big.Rat
+
+// Rat returns p with exact rational coefficients. Each coefficient is
+// converted from the shortest decimal that reads back as it, so 0.1 is
+// 1/10 rather than the binary fraction nearest to it.
+func (p Polynomial) Rat() (RatPolynomial, error) {
+	r := make(RatPolynomial, len(p))
+	for i, c := range p {
+		x, ok := new(big.Rat).SetString(strconv.FormatFloat(c, 'g', -1, 64))
+		if !ok {
+			return nil, fmt.Errorf("coefficient %v is not a rational number", c)
+		}
+		r[i] = x
+	}
+	return r.Normalize(), nil
+}
+
+// Float returns p with float64 coefficients, rounded to the nearest.
+func (p RatPolynomial) Float() Polynomial {
+	f := make(Polynomial, len(p))
+	for i, c := range p {
+		f[i], _ = c.Float64()
+	}
+	return f
+}
+
+// Degree returns the degree of p, or -1 for the zero polynomial.
+func (p RatPolynomial) Degree() int {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i].Sign() != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// LeadingCoeff returns the coefficient of the highest power of x in p, or 0
+// for the zero polynomial.
+func (p RatPolynomial) LeadingCoeff() *big.Rat {
+	if d := p.Degree(); d >= 0 {
+		return p[d]
+	}
+	return new(big.Rat)
+}
+
+// Normalize returns p without its trailing zero coefficients. The result
+// shares p's storage.
+func (p RatPolynomial) Normalize() RatPolynomial {
+	return p[:p.Degree()+1]
+}
+
+// Mul returns p q.
+func (p RatPolynomial) Mul(q RatPolynomial) RatPolynomial {
+	p, q = p.Normalize(), q.Normalize()
+	if len(p) == 0 || len(q) == 0 {
+		return RatPolynomial{}
+	}
+	product := zeroRat(len(p) + len(q) - 1)
+	var t big.Rat
+	for i, a := range p {
+		for j, b := range q {
+			product[i+j].Add(product[i+j], t.Mul(a, b))
+		}
+	}
+	return product.Normalize()
+}
+
+// DivMod returns the quotient and remainder of the long division of p by
+// q, such that p = quo q + rem and the degree of rem is less than that of
+// q.
+func (p RatPolynomial) DivMod(q RatPolynomial) (quo, rem RatPolynomial, err error) {
+	q = q.Normalize()
+	if len(q) == 0 {
+		return nil, nil, ErrZeroDivisor
+	}
+	rem = p.Normalize().scale(big.NewRat(1, 1))
+	if len(rem) < len(q) {
+		return RatPolynomial{}, rem, nil
+	}
+	n := len(q) - 1
+	quo = zeroRat(len(rem) - n)
+	var t big.Rat
+	for i := len(quo) - 1; i >= 0; i-- {
+		c := quo[i].Quo(rem[i+n], q[n])
+		for j, b := range q[:n] {
+			rem[i+j].Sub(rem[i+j], t.Mul(c, b))
+		}
+		rem[i+n].SetInt64(0)
+	}
+	return quo.Normalize(), rem[:n].Normalize(), nil
+}
+
+// Derivative returns the derivative of p.
+func (p RatPolynomial) Derivative() RatPolynomial {
+	if len(p) < 2 {
+		return RatPolynomial{}
+	}
+	d := make(RatPolynomial, len(p)-1)
+	for i := range d {
+		d[i] = new(big.Rat).Mul(p[i+1], big.NewRat(int64(i+1), 1))
+	}
+	return d.Normalize()
+}
+
+// Content returns the content of p: the rational c with the sign of the
+// leading coefficient of p such that p/c has integer coefficients without
+// a common factor. The content of the zero polynomial is zero.
+func (p RatPolynomial) Content() *big.Rat {
+	num, den := new(big.Int), big.NewInt(1)
+	for _, c := range p {
+		if c.Sign() == 0 {
+			continue
+		}
+		num.GCD(nil, nil, num, new(big.Int).Abs(c.Num()))
+		g := new(big.Int).GCD(nil, nil, den, c.Denom())
+		den.Mul(den, new(big.Int).Quo(c.Denom(), g))
+	}
+	content := new(big.Rat).SetFrac(num, den)
+	if p.LeadingCoeff().Sign() < 0 {
+		content.Neg(content)
+	}
+	return content
+}
+
+// PrimitivePart returns p divided by its content, a polynomial with
+// integer coefficients without a common factor and a positive leading
+// coefficient, or zero for the zero polynomial.
+func (p RatPolynomial) PrimitivePart() RatPolynomial {
+	c := p.Content()
+	if c.Sign() == 0 {
+		return RatPolynomial{}
+	}
+	return p.scale(c.Inv(c))
+}
+
+// String writes p as Polynomial.String does, with fractions such as
+// (1/2)x^2 - 3.
+func (p RatPolynomial) String() string {
+	return p.Format(format.Options{})
+}
+
+// Format writes p as String does, formatting the coefficients with opts.
+func (p RatPolynomial) Format(opts format.Options) string {
+	return p.render(opts, false)
+}
+
+// LaTeX writes p as LaTeX math, formatting the coefficients with opts.
+func (p RatPolynomial) LaTeX(opts format.Options) string {
+	return p.render(opts, true)
+}
+
+// render writes p as text or as LaTeX. In text, fractions multiplying a
+// power of x are put in parentheses.
+func (p RatPolynomial) render(opts format.Options, latex bool) string {
+	return renderTerms(p.Degree(), func(i int) (int, bool, string) {
+		c := p[i]
+		a := new(big.Rat).Abs(c)
+		s := formatRat(a, opts, latex)
+		if i > 0 && !latex && strings.Contains(s, "/") {
+			s = "(" + s + ")"
+		}
+		return c.Sign(), a.Cmp(big.NewRat(1, 1)) == 0, s
+	}, "0", latex)
+}
+
+// formatRat formats the number c with opts, as LaTeX with latex.
+func formatRat(c *big.Rat, opts format.Options, latex bool) string {
+	s := format.Rat(c, opts)
+	if latex {
+		return latexNumber(s)
+	}
+	return s
+}
+
+// scale returns c p.
+func (p RatPolynomial) scale(c *big.Rat) RatPolynomial {
+	scaled := make(RatPolynomial, len(p))
+	for i, a := range p {
+		scaled[i] = new(big.Rat).Mul(a, c)
+	}
+	return scaled.Normalize()
+}
+
+// sub returns p - q.
+func (p RatPolynomial) sub(q RatPolynomial) RatPolynomial {
+	diff := zeroRat(max(len(p), len(q)))
+	for i, c := range p {
+		diff[i].Set(c)
+	}
+	for i, c := range q {
+		diff[i].Sub(diff[i], c)
+	}
+	return diff.Normalize()
+}
+
+// zeroRat returns a RatPolynomial of n zero coefficients.
+func zeroRat(n int) RatPolynomial {
+	p := make(RatPolynomial, n)
+	for i := range p {
+		p[i] = new(big.Rat)
+	}
+	return p
+}
+
+// ratGCD returns the primitive greatest common divisor of p and q, computed
+// exactly with the Euclidean algorithm on primitive remainders, which keeps
+// the coefficients small.
+func ratGCD(p, q RatPolynomial) RatPolynomial {
+	p, q = p.PrimitivePart(), q.PrimitivePart()
+	for len(q) > 0 {
+		_, rem, _ := p.DivMod(q)
+		p, q = q, rem.PrimitivePart()
+	}
+	return p
+}
+
+// ratSquareFree returns the exact square-free decomposition of p as
+// SquareFree does, with primitive parts.
+func ratSquareFree(p RatPolynomial) []RatPolynomial {
+	var factors []RatPolynomial
+	if p.Degree() < 1 {
+		return factors
+	}
+	dp := p.Derivative()
+	g := ratGCD(p, dp)
+	b, _, _ := p.DivMod(g)
+	c, _, _ := dp.DivMod(g)
+	d := c.sub(b.Derivative())
+	for b.Degree() > 0 {
+		a := ratGCD(b, d)
+		factors = append(factors, a)
+		b, _, _ = b.DivMod(a)
+		c, _, _ = d.DivMod(a)
+		d = c.sub(b.Derivative())
+	}
+	return factors
+}
+
+// A RatPower is a factor of a polynomial with rational coefficients raised
+// to its multiplicity. The factor is primitive: its coefficients are
+// integers without a common factor and its leading coefficient is
+// positive.
+type RatPower struct {
+	Factor       RatPolynomial
+	Multiplicity int
+}
+
+// String writes the factor as (2x - 1)^2.
+func (f RatPower) String() string {
+	return f.Format(format.Options{})
+}
+
+// Format writes the factor as String does, formatting the coefficients
+// with opts.
+func (f RatPower) Format(opts format.Options) string {
+	return powerString(f.Factor.render(opts, false), f.Multiplicity, false)
+}
+
+// LaTeX writes the factor as LaTeX math, formatting the coefficients with
+// opts.
+func (f RatPower) LaTeX(opts format.Options) string {
+	return powerString(f.Factor.render(opts, true), f.Multiplicity, true)
+}
+
+// A RatFactorization is a polynomial with rational coefficients written
+// exactly as its content times a product of powers of primitive factors.
+type RatFactorization struct {
+	Content *big.Rat
+	Factors []RatPower
+}
+
+// String writes the factorization as (1/2)(x - 1)(2x - 1)^2.
+func (f RatFactorization) String() string {
+	return f.Format(format.Options{})
+}
+
+// Format writes the factorization as String does, formatting the numbers
+// with opts.
+func (f RatFactorization) Format(opts format.Options) string {
+	return f.render(opts, false)
+}
+
+// LaTeX writes the factorization as LaTeX math, formatting the numbers
+// with opts.
+func (f RatFactorization) LaTeX(opts format.Options) string {
+	return f.render(opts, true)
+}
+
+// render writes the factorization as text or as LaTeX, omitting a content
+// of 1 and putting a fractional content in parentheses in text.
+func (f RatFactorization) render(opts format.Options, latex bool) string {
+	factors := make([]string, len(f.Factors))
+	for i, factor := range f.Factors {
+		factors[i] = powerString(factor.Factor.render(opts, latex), factor.Multiplicity, latex)
+	}
+	content := formatRat(f.Content, opts, latex)
+	if len(factors) > 0 && !latex && strings.Contains(content, "/") {
+		content = "(" + content + ")"
+	}
+	return productString(content, f.Content.Cmp(big.NewRat(1, 1)) == 0, f.Content.Cmp(big.NewRat(-1, 1)) == 0, factors)
+}
+
+// Expand returns the product of the factorization.
+func (f RatFactorization) Expand() RatPolynomial {
+	product := RatPolynomial{f.Content}
+	for _, factor := range f.Factors {
+		for i := 0; i < factor.Multiplicity; i++ {
+			product = product.Mul(factor.Factor)
+		}
+	}
+	return product
+}
+
+// FactorizeRational factorizes a polynomial with rational coefficients
+// exactly, into its content and powers of primitive factors with integer
+// coefficients. Repeated factors are grouped with their multiplicities,
+// found from the exact square-free decomposition, and the linear factors
+// qx - p of the rational roots p/q are found with the rational root
+// theorem, by trying the divisors p of the constant term and q of the
+// leading coefficient of each square-free part; parts with coefficients
+// beyond 2^40 are not searched. The quadratic factors of what remains are
+// then found with Kronecker's method. The last factor of each part has no
+// linear or quadratic factors, so it is irreducible over the rationals when
+// its degree is at most 5, while one of degree 6 or more may still be a
+// product of factors of degree 3 or more. Linear factors come first, in
+// increasing order of their roots, followed by the others in increasing
+// order of degree.
+func FactorizeRational(p RatPolynomial) (RatFactorization, error) {
+	p = p.Normalize()
+	if len(p) == 0 {
+		return RatFactorization{}, fmt.Errorf("cannot factorize the zero polynomial")
+	}
+	f := RatFactorization{Content: p.Content()}
+	p = p.PrimitivePart()
+
+	// x^k divides p when its k lowest coefficients are zero
+	k := 0
+	for p[k].Sign() == 0 {
+		k++
+	}
+	if k > 0 {
+		f.Factors = append(f.Factors, RatPower{Factor: RatPolynomial{new(big.Rat), big.NewRat(1, 1)}, Multiplicity: k})
+	}
+	p = p[k:]
+
+	for i, part := range ratSquareFree(p) {
+		if part.Degree() < 1 {
+			continue
+		}
+		linear, rest := rationalRoots(part)
+		quadratic, rest := quadraticFactors(rest)
+		for _, factor := range append(linear, quadratic...) {
+			f.Factors = append(f.Factors, RatPower{Factor: factor, Multiplicity: i + 1})
+		}
+		if rest.Degree() > 0 {
+			f.Factors = append(f.Factors, RatPower{Factor: rest, Multiplicity: i + 1})
+		}
+	}
+
+	sort.SliceStable(f.Factors, func(i, j int) bool {
+		a, b := f.Factors[i].Factor, f.Factors[j].Factor
+		if a.Degree() != b.Degree() || a.Degree() != 1 {
+			return a.Degree() < b.Degree()
+		}
+		// The roots -a[0]/a[1] in increasing order
+		ra := new(big.Rat).Quo(a[0], a[1])
+		rb := new(big.Rat).Quo(b[0], b[1])
+		return ra.Cmp(rb) > 0
+	})
+	return f, nil
+}
+
+// rationalRoots divides the linear factors qx - p of the rational roots
+// p/q out of the primitive square-free polynomial p with a non-zero
+// constant term. It returns the primitive linear factors and the primitive
+// cofactor.
+func rationalRoots(p RatPolynomial) ([]RatPolynomial, RatPolynomial) {
+	numerators := divisors(p[0].Num())
+	denominators := divisors(p.LeadingCoeff().Num())
+	if numerators == nil || denominators == nil {
+		return nil, p
+	}
+
+	var factors []RatPolynomial
+	var g big.Int
+	for _, den := range denominators {
+		for _, num := range numerators {
+			if g.GCD(nil, nil, num, den).Cmp(big.NewInt(1)) != 0 {
+				continue
+			}
+			for _, n := range []*big.Int{num, new(big.Int).Neg(num)} {
+				if p.Degree() < 1 {
+					return factors, p
+				}
+				if !isRoot(p, n, den) {
+					continue
+				}
+				factor := RatPolynomial{new(big.Rat).SetInt(new(big.Int).Neg(n)), new(big.Rat).SetInt(den)}
+				factors = append(factors, factor)
+				p, _, _ = p.DivMod(factor)
+			}
+		}
+	}
+	return factors, p
+}
+
+// quadraticFactors divides the primitive quadratic factors ax^2 + bx + c
+// out of the primitive polynomial p without rational roots. It returns the
+// factors and the primitive cofactor.
+func quadraticFactors(p RatPolynomial) ([]RatPolynomial, RatPolynomial) {
+	var factors []RatPolynomial
+	for p.Degree() >= 4 {
+		q := findQuadratic(p)
+		if q == nil {
+			break
+		}
+		factors = append(factors, q)
+		p, _, _ = p.DivMod(q)
+	}
+	return factors, p
+}
+
+// findQuadratic returns a primitive quadratic factor of the primitive
+// polynomial p without rational roots, or nil if it has none or is too
+// large to search. Following Kronecker, a factor ax^2 + bx + c has a
+// dividing the leading coefficient of p, c dividing its constant term, and
+// values a + b + c and a - b + c dividing p(1) and p(-1), which are not
+// zero; every candidate is then tried by division.
+func findQuadratic(p RatPolynomial) RatPolynomial {
+	leading := divisors(p.LeadingCoeff().Num())
+	constant := divisors(p[0].Num())
+	atOne := divisors(valueAt(p, 1))
+	atMinusOne := valueAt(p, -1)
+	if leading == nil || constant == nil || atOne == nil || atMinusOne.Sign() == 0 {
+		return nil
+	}
+	if len(leading)*len(constant)*len(atOne) > maxQuadraticCandidates {
+		return nil
+	}
+
+	var b, v, g, r big.Int
+	for _, a := range leading {
+		for _, cAbs := range constant {
+			for _, c := range []*big.Int{new(big.Int).Neg(cAbs), cAbs} {
+				for _, dAbs := range atOne {
+					for _, d := range []*big.Int{dAbs, new(big.Int).Neg(dAbs)} {
+						// b = d - a - c, and a - b + c must divide p(-1)
+						b.Sub(d, a)
+						b.Sub(&b, c)
+						v.Sub(a, &b)
+						v.Add(&v, c)
+						if v.Sign() == 0 || r.Rem(atMinusOne, &v).Sign() != 0 {
+							continue
+						}
+						if g.GCD(nil, nil, a, c).GCD(nil, nil, &g, new(big.Int).Abs(&b)).Cmp(big.NewInt(1)) != 0 {
+							continue
+						}
+						q := RatPolynomial{new(big.Rat).SetInt(c), new(big.Rat).SetInt(&b), new(big.Rat).SetInt(a)}
+						if _, rem, _ := p.DivMod(q); len(rem) == 0 {
+							return q
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// valueAt returns p(x) for the polynomial p with integer coefficients.
+func valueAt(p RatPolynomial, x int64) *big.Int {
+	acc := new(big.Int)
+	n := big.NewInt(x)
+	for i := len(p) - 1; i >= 0; i-- {
+		acc.Mul(acc, n)
+		acc.Add(acc, p[i].Num())
+	}
+	return acc
+}
+
+// isRoot reports whether n/d is a root of the polynomial p with integer
+// coefficients, evaluating d^deg p(n/d) in integers with Horner's scheme.
+func isRoot(p RatPolynomial, n, d *big.Int) bool {
+	acc := new(big.Int).Set(p[len(p)-1].Num())
+	dpow := big.NewInt(1)
+	var t big.Int
+	for i := len(p) - 2; i >= 0; i-- {
+		dpow.Mul(dpow, d)
+		acc.Mul(acc, n)
+		acc.Add(acc, t.Mul(p[i].Num(), dpow))
+	}
+	return acc.Sign() == 0
+}
+
+// divisors returns the positive divisors of n in increasing order, or nil
+// if |n| is zero or larger than maxRootTheoremCoeff.
+func divisors(n *big.Int) []*big.Int {
+	a := new(big.Int).Abs(n)
+	if a.Sign() == 0 || a.Cmp(big.NewInt(maxRootTheoremCoeff)) > 0 {
+		return nil
+	}
+	v := a.Int64()
+	var small, large []*big.Int
+	for i := int64(1); i*i <= v; i++ {
+		if v%i != 0 {
+			continue
+		}
+		small = append(small, big.NewInt(i))
+		if j := v / i; j != i {
+			large = append(large, big.NewInt(j))
+		}
+	}
+	for i := len(large) - 1; i >= 0; i-- {
+		small = append(small, large[i])
+	}
+	return small
+}
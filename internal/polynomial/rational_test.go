@@ -0,0 +1,146 @@
+package polynomial_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/polynomial"
+)
+
+// ratPoly parses s as a polynomial with exact coefficients
+func ratPoly(t *testing.T, s string) polynomial.RatPolynomial {
+	t.Helper()
+	p, err := polynomial.ParsePolynomial(s)
+	if err != nil {
+		t.Fatalf("ParsePolynomial(%q) error = %v", s, err)
+	}
+	r, err := p.Rat()
+	if err != nil {
+		t.Fatalf("Rat(%q) error = %v", s, err)
+	}
+	return r
+}
+
+// TestRat tests converting to exact coefficients and back
+func TestRat(t *testing.T) {
+	r, err := polynomial.Polynomial{0.1, -2, 0.5, 0}.Rat()
+	if err != nil {
+		t.Fatalf("Rat error: %v", err)
+	}
+	if got, want := r.String(), "(1/2)x^2 - 2x + 1/10"; got != want {
+		t.Errorf("Rat = %q, want %q", got, want)
+	}
+	if got, want := r.LaTeX(format.Options{}), `\frac{1}{2}x^{2} - 2x + \frac{1}{10}`; got != want {
+		t.Errorf("LaTeX = %q, want %q", got, want)
+	}
+	if got := r.Float(); !got.Equal(polynomial.Polynomial{0.1, -2, 0.5}, 0) {
+		t.Errorf("Float = %v", got)
+	}
+	if _, err := (polynomial.Polynomial{math.NaN()}).Rat(); err == nil {
+		t.Errorf("Expected an error for a NaN coefficient")
+	}
+}
+
+// TestContent tests content and primitive-part extraction
+func TestContent(t *testing.T) {
+	tests := []struct {
+		input     string
+		content   string
+		primitive string
+	}{
+		{"6x^2 + 4x - 2", "2", "3x^2 + 2x - 1"},
+		{"0.5x^2 - 1.5", "1/2", "x^2 - 3"},
+		{"-4x + 6", "-2", "2x - 3"},
+		{"7", "7", "1"},
+	}
+
+	for _, tc := range tests {
+		p := ratPoly(t, tc.input)
+		if got := p.Content().RatString(); got != tc.content {
+			t.Errorf("Content(%s) = %s, want %s", tc.input, got, tc.content)
+		}
+		if got := p.PrimitivePart().String(); got != tc.primitive {
+			t.Errorf("PrimitivePart(%s) = %s, want %s", tc.input, got, tc.primitive)
+		}
+	}
+
+	// Coefficients that are not decimals: (2/3)x^2 + (1/4)x
+	p := polynomial.RatPolynomial{new(big.Rat), big.NewRat(1, 4), big.NewRat(2, 3)}
+	if got, want := p.Content().RatString(), "1/12"; got != want {
+		t.Errorf("Content(%v) = %s, want %s", p, got, want)
+	}
+	if got, want := p.PrimitivePart().String(), "8x^2 + 3x"; got != want {
+		t.Errorf("PrimitivePart(%v) = %s, want %s", p, got, want)
+	}
+	if got := (polynomial.RatPolynomial{}).Content(); got.Sign() != 0 {
+		t.Errorf("Content of zero = %v, want 0", got)
+	}
+}
+
+// TestFactorizeRational tests exact factorization over the rationals
+func TestFactorizeRational(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"6x^3 - 11x^2 + 6x - 1", "(3x - 1)(2x - 1)(x - 1)", false},
+		{"x^2 - 5x + 6", "(x - 2)(x - 3)", false},
+		{"2x^2 - 8", "2(x + 2)(x - 2)", false},
+		{"0.5x^2 - 0.125", "(1/8)(2x + 1)(2x - 1)", false},
+		{"-x^2 + 1", "-(x + 1)(x - 1)", false},
+		{"4x^3 - 12x^2 + 9x", "x(2x - 3)^2", false},
+		{"x^5 - x^4", "x^4(x - 1)", false},
+		{"12x^4 - 28x^3 - x^2 + 30x - 9", "(x + 1)(3x - 1)(2x - 3)^2", false},
+		{"x^2 - 2", "(x^2 - 2)", false},
+		{"2x^3 - x^2 - 4x + 2", "(2x - 1)(x^2 - 2)", false},
+		{"x^4 + 2x^2 + 1", "(x^2 + 1)^2", false},
+		{"x^3 - 2", "(x^3 - 2)", false},
+		{"x^4 - 4", "(x^2 - 2)(x^2 + 2)", false},
+		{"4x^4 - 1", "(2x^2 - 1)(2x^2 + 1)", false},
+		{"x^4 + 4", "(x^2 - 2x + 2)(x^2 + 2x + 2)", false},
+		{"x^5 - x^4 - 4x + 4", "(x - 1)(x^2 - 2)(x^2 + 2)", false},
+		{"x^6 - 2x^4 + x^2 - 2", "(x^2 - 2)(x^4 + 1)", false},
+		{"x^4 + 1", "(x^4 + 1)", false},
+		{"3", "3", false},
+		{"0", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			p := ratPoly(t, tc.input)
+			f, err := polynomial.FactorizeRational(p)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FactorizeRational(%s) error = %v, wantErr = %v", tc.input, err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := f.String(); got != tc.want {
+				t.Errorf("FactorizeRational(%s) = %s, want %s", tc.input, got, tc.want)
+			}
+			if got := f.Expand(); got.String() != p.String() {
+				t.Errorf("Expand(FactorizeRational(%s)) = %s", tc.input, got)
+			}
+		})
+	}
+}
+
+// TestRatFactorizationFormat tests writing exact factorizations as LaTeX
+func TestRatFactorizationFormat(t *testing.T) {
+	f, err := polynomial.FactorizeRational(ratPoly(t, "1.5x^3 - 1.5x"))
+	if err != nil {
+		t.Fatalf("FactorizeRational error: %v", err)
+	}
+	if got, want := f.String(), "(3/2)(x + 1)x(x - 1)"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	if got, want := f.LaTeX(format.Options{}), `\frac{3}{2}(x + 1)x(x - 1)`; got != want {
+		t.Errorf("LaTeX = %q, want %q", got, want)
+	}
+	if got, want := f.Format(format.Options{Digits: 2}), "1.5(x + 1)x(x - 1)"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+}
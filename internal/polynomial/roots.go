@@ -0,0 +1,397 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ErrNotConverged is returned by FindRoots when a root has not converged
+// within the iteration limit.
+var ErrNotConverged = errors.New("root finding did not converge")
+
+// The options FindRoots uses.
+const (
+	DefaultRootTolerance = 1e-12
+	DefaultMaxIter       = 500
+)
+
+// unitRoundoff is the relative rounding error of float64 arithmetic.
+const unitRoundoff = 0x1p-53
+
+// aberthAngle rotates the initial guesses of the Aberth iteration off the
+// real axis, where guesses for real polynomials could never become complex.
+const aberthAngle = 0.4
+
+// RootMethod selects the algorithm that finds the roots of polynomials of
+// degree 3 and above.
+type RootMethod int
+
+const (
+	// Aberth refines all the roots together with the Aberth-Ehrlich
+	// iteration, starting from points on a circle of the radius of the
+	// Cauchy bound.
+	Aberth RootMethod = iota
+	// Companion finds the roots as the eigenvalues of the companion matrix.
+	Companion
+	// ClosedForm solves linear and quadratic polynomials with formulas,
+	// whichever method is selected. It cannot be selected for higher
+	// degrees.
+	ClosedForm
+)
+
+// rootMethods maps the names accepted by ParseRootMethod to methods.
+var rootMethods = []struct {
+	name   string
+	method RootMethod
+}{
+	{"aberth", Aberth},
+	{"companion", Companion},
+	{"closed-form", ClosedForm},
+}
+
+// ParseRootMethod returns the root-finding method named s: aberth or
+// companion.
+func ParseRootMethod(s string) (RootMethod, error) {
+	for _, m := range rootMethods {
+		if m.method != ClosedForm && strings.EqualFold(s, m.name) {
+			return m.method, nil
+		}
+	}
+	return Aberth, fmt.Errorf("unknown root-finding method %q (want aberth or companion)", s)
+}
+
+func (m RootMethod) String() string {
+	for _, entry := range rootMethods {
+		if entry.method == m {
+			return entry.name
+		}
+	}
+	return fmt.Sprintf("RootMethod(%d)", int(m))
+}
+
+// RootOptions controls how FindRootsWith finds roots.
+type RootOptions struct {
+	Method RootMethod
+	// Tolerance is the change in a root, relative to its magnitude, below
+	// which the iteration has converged. A root has also converged once the
+	// polynomial's value there is within the rounding error of evaluating
+	// it, which is as close as the iteration can get.
+	Tolerance float64
+	// MaxIter is the maximum number of iterations.
+	MaxIter int
+}
+
+// MethodFor returns the method FindRootsWith uses for p with opts:
+// ClosedForm when p has degree at most 2 once its roots at zero are
+// removed, and otherwise opts.Method.
+func (opts RootOptions) MethodFor(p Polynomial) RootMethod {
+	p = p.Normalize()
+	zeros := 0
+	for zeros < len(p) && p[zeros] == 0 {
+		zeros++
+	}
+	if p.Degree()-zeros < 3 {
+		return ClosedForm
+	}
+	return opts.Method
+}
+
+// A Root is a root of a polynomial found numerically.
+type Root struct {
+	Value complex128
+	// ErrorBound is the radius of a disk around Value that contains a root
+	// of the polynomial, allowing for the rounding error of evaluating it.
+	ErrorBound float64
+	// Iterations is the number of steps that refined Value, which is zero
+	// for roots found in closed form or as eigenvalues.
+	Iterations int
+	Converged  bool
+}
+
+// FindRoots finds the roots of a polynomial, repeated by their
+// multiplicities and in the order of FindRootsWith, with the default
+// options. It returns an error wrapping ErrNotConverged rather than roots
+// that have not converged.
+func FindRoots(coefficients Polynomial) ([]complex128, error) {
+	roots, err := FindRootsWith(coefficients, RootOptions{Tolerance: DefaultRootTolerance, MaxIter: DefaultMaxIter})
+	if err != nil {
+		return nil, err
+	}
+	values := make([]complex128, len(roots))
+	for i, r := range roots {
+		if !r.Converged {
+			return nil, fmt.Errorf("%w within %d iterations for %v", ErrNotConverged, DefaultMaxIter, coefficients)
+		}
+		values[i] = r.Value
+	}
+	return values, nil
+}
+
+// FindRootsWith finds the roots of a polynomial, repeated by their
+// multiplicities, reporting an error bound, iteration count and
+// convergence for each, in increasing order of their real and then
+// imaginary parts. Zero leading coefficients are trimmed, roots at
+// zero are found exactly, linear and quadratic polynomials are solved in
+// closed form and higher degrees with opts.Method.
+func FindRootsWith(coefficients Polynomial, opts RootOptions) ([]Root, error) {
+	if len(coefficients) == 0 {
+		return nil, fmt.Errorf("no coefficients provided")
+	}
+	if !(opts.Tolerance >= 0) {
+		return nil, fmt.Errorf("invalid tolerance %v (must not be negative)", opts.Tolerance)
+	}
+	if opts.MaxIter < 1 {
+		return nil, fmt.Errorf("invalid iteration limit %d (must be at least 1)", opts.MaxIter)
+	}
+	for _, c := range coefficients {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return nil, fmt.Errorf("invalid coefficient %v (must be finite)", c)
+		}
+	}
+	p := coefficients.Normalize()
+	if len(p) == 0 {
+		return nil, fmt.Errorf("every number is a root of the zero polynomial")
+	}
+
+	var roots []Root
+	for p[0] == 0 {
+		roots = append(roots, Root{Converged: true})
+		p = p[1:]
+	}
+
+	var found []Root
+	switch opts.MethodFor(p) {
+	case ClosedForm:
+		found = closedFormRoots(p)
+	case Companion:
+		var err error
+		if found, err = companionRoots(p); err != nil {
+			return nil, err
+		}
+		conjugatePairs(found)
+	default:
+		found = aberthRoots(p, opts)
+		conjugatePairs(found)
+	}
+
+	if len(found) > 0 {
+		dp := p.Derivative()
+		bound := cauchyBound(p)
+		for i := range found {
+			found[i].ErrorBound = errorBound(p, dp, bound, found[i].Value)
+		}
+	}
+	roots = append(roots, found...)
+	sort.SliceStable(roots, func(i, j int) bool {
+		a, b := roots[i].Value, roots[j].Value
+		if real(a) != real(b) {
+			return real(a) < real(b)
+		}
+		return imag(a) < imag(b)
+	})
+	return roots, nil
+}
+
+// conjugatePairs makes the roots of a polynomial with real coefficients
+// real or exact conjugate pairs, as its roots are. Imaginary parts within
+// the relative tolerance factorTolerance are rounding errors, and are
+// dropped. The other roots are paired, each with the root nearest to its
+// conjugate, and set to the mean of the root and the partner's conjugate.
+// A root left without a partner, near a cluster or multiple root, is made
+// real.
+func conjugatePairs(roots []Root) {
+	var upper, lower []int
+	for i, r := range roots {
+		z := r.Value
+		switch {
+		case math.Abs(imag(z)) <= factorTolerance*math.Max(1, cmplx.Abs(z)):
+			roots[i].Value = complex(real(z), 0)
+		case imag(z) > 0:
+			upper = append(upper, i)
+		default:
+			lower = append(lower, i)
+		}
+	}
+
+	paired := make([]bool, len(roots))
+	for _, i := range upper {
+		partner := -1
+		for _, j := range lower {
+			if !paired[j] && (partner < 0 || cmplx.Abs(roots[i].Value-cmplx.Conj(roots[j].Value)) < cmplx.Abs(roots[i].Value-cmplx.Conj(roots[partner].Value))) {
+				partner = j
+			}
+		}
+		if partner < 0 {
+			continue
+		}
+		z := (roots[i].Value + cmplx.Conj(roots[partner].Value)) / 2
+		roots[i].Value, roots[partner].Value = z, cmplx.Conj(z)
+		paired[i], paired[partner] = true, true
+	}
+	for _, i := range append(upper, lower...) {
+		if !paired[i] {
+			roots[i].Value = complex(real(roots[i].Value), 0)
+		}
+	}
+}
+
+// closedFormRoots returns the roots of a polynomial of degree at most 2
+// with a nonzero constant term, using the quadratic formula in the form
+// that avoids cancellation.
+func closedFormRoots(p Polynomial) []Root {
+	switch p.Degree() {
+	case 1:
+		return []Root{{Value: complex(-p[0]/p[1], 0), Converged: true}}
+	case 2:
+		a, b, c := p[2], p[1], p[0]
+		discriminant := b*b - 4*a*c
+		if discriminant < 0 {
+			re, im := -b/(2*a), math.Sqrt(-discriminant)/(2*math.Abs(a))
+			return []Root{{Value: complex(re, im), Converged: true}, {Value: complex(re, -im), Converged: true}}
+		}
+		q := -(b + math.Copysign(math.Sqrt(discriminant), b)) / 2
+		return []Root{{Value: complex(q/a, 0), Converged: true}, {Value: complex(c/q, 0), Converged: true}}
+	}
+	return nil
+}
+
+// companionRoots returns the roots of p as the eigenvalues of its
+// companion matrix, whose characteristic polynomial is p made monic.
+func companionRoots(p Polynomial) ([]Root, error) {
+	monic := p.Monic()
+	n := monic.Degree()
+	companion := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			companion.Set(i, i-1, 1)
+		}
+		companion.Set(i, n-1, -monic[i])
+	}
+
+	var eigen mat.Eigen
+	if !eigen.Factorize(companion, mat.EigenNone) {
+		return nil, fmt.Errorf("%w: the eigenvalues of the companion matrix of %v", ErrNotConverged, p)
+	}
+	values := eigen.Values(nil)
+	roots := make([]Root, len(values))
+	for i, z := range values {
+		roots[i] = Root{Value: z, Converged: true}
+	}
+	return roots, nil
+}
+
+// aberthRoots returns the roots of p found with the Aberth-Ehrlich
+// iteration, which moves each guess z by the Newton step p(z)/p'(z)
+// corrected for the other guesses, as if their roots were divided out of
+// p. Guesses are updated in place as they are computed, and stop once
+// they have converged.
+func aberthRoots(p Polynomial, opts RootOptions) []Root {
+	n := p.Degree()
+	dp := p.Derivative()
+	radius := cauchyBound(p)
+	roots := make([]Root, n)
+	for k := range roots {
+		roots[k].Value = cmplx.Rect(radius, 2*math.Pi*float64(k)/float64(n)+aberthAngle)
+	}
+
+	remaining := n
+	for iter := 0; iter < opts.MaxIter && remaining > 0; iter++ {
+		for i := range roots {
+			if roots[i].Converged {
+				continue
+			}
+			z := roots[i].Value
+			pz := evaluatePolynomial(p, z)
+			if cmplx.Abs(pz) <= roundingError(p, z) {
+				roots[i].Converged = true
+				remaining--
+				continue
+			}
+			var s complex128
+			for j := range roots {
+				if j != i {
+					s += 1 / (z - roots[j].Value)
+				}
+			}
+			d := evaluatePolynomial(dp, z) - pz*s
+			if d == 0 || cmplx.IsNaN(d) || cmplx.IsInf(d) {
+				continue
+			}
+			step := pz / d
+			roots[i].Value = z - step
+			roots[i].Iterations++
+			if cmplx.Abs(step) <= opts.Tolerance*cmplx.Abs(roots[i].Value) {
+				roots[i].Converged = true
+				remaining--
+			}
+		}
+	}
+
+	// The last steps may have reached the rounding error
+	for i := range roots {
+		if z := roots[i].Value; !roots[i].Converged {
+			roots[i].Converged = cmplx.Abs(evaluatePolynomial(p, z)) <= roundingError(p, z)
+		}
+	}
+	return roots
+}
+
+// cauchyBound returns the Cauchy bound of p, the positive root of
+// |a_n|x^n - |a_(n-1)|x^(n-1) - ... - |a_0|, which no root of p exceeds
+// in magnitude. It is found by bisection to within a thousandth, rounding
+// up.
+func cauchyBound(p Polynomial) float64 {
+	n := p.Degree()
+	lead := math.Abs(p.LeadingCoeff())
+	hi := 1.0
+	for _, c := range p[:n] {
+		hi = math.Max(hi, 1+math.Abs(c)/lead)
+	}
+	// below reports whether x is below the bound, where the lower terms
+	// outweigh the leading one
+	below := func(x float64) bool {
+		sum := 0.0
+		for i, c := range p[:n] {
+			sum += math.Abs(c) / lead * math.Pow(x, float64(i-n))
+		}
+		return sum > 1
+	}
+	lo := 0.0
+	for hi-lo > 1e-3*hi {
+		mid := (lo + hi) / 2
+		if below(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// roundingError bounds the rounding error of evaluating p at z with
+// Horner's method, which is proportional to p with the magnitudes of its
+// coefficients evaluated at |z|.
+func roundingError(p Polynomial, z complex128) float64 {
+	x := cmplx.Abs(z)
+	sum := 0.0
+	for i := len(p) - 1; i >= 0; i-- {
+		sum = sum*x + math.Abs(p[i])
+	}
+	return 4 * float64(len(p)) * unitRoundoff * sum
+}
+
+// errorBound returns the radius of a disk around z that contains a root
+// of p: n(|p(z)| + e)/|p'(z)|, where n is the degree of p and e the
+// rounding error of p(z), or |z| plus the Cauchy bound of p if that is
+// larger.
+func errorBound(p, dp Polynomial, cauchy float64, z complex128) float64 {
+	n := float64(p.Degree())
+	v := cmplx.Abs(evaluatePolynomial(p, z)) + roundingError(p, z)
+	return math.Min(n*v/cmplx.Abs(evaluatePolynomial(dp, z)), cmplx.Abs(z)+cauchy)
+}
@@ -0,0 +1,191 @@
+package polynomial_test
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/polynomial"
+)
+
+// TestFindRootsWith tests both root-finding methods, checking that each
+// root is within its error bound of a true root
+func TestFindRootsWith(t *testing.T) {
+	// (x - 1)(x - 2)...(x - 8)
+	product := polynomial.Polynomial{1}
+	for i := 1; i <= 8; i++ {
+		product = product.Mul(polynomial.Polynomial{-float64(i), 1})
+	}
+
+	tests := []struct {
+		name      string
+		p         polynomial.Polynomial
+		wantRoots []complex128
+	}{
+		{"Cubic", polynomial.Polynomial{-6, 11, -6, 1}, []complex128{1, 2, 3}},
+		{"Complex roots", polynomial.Polynomial{-1, 1, -1, 1}, []complex128{1, 1i, -1i}},
+		{"Roots of unity", polynomial.Polynomial{-1, 0, 0, 0, 1}, []complex128{1, -1, 1i, -1i}},
+		{"Product of 8 linear factors", product, []complex128{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"Leading zeros", polynomial.Polynomial{-1, 0, 1, 0, 0}, []complex128{-1, 1}},
+		{"Roots at zero", polynomial.Polynomial{0, 0, 2, 0, 2}, []complex128{0, 0, 1i, -1i}},
+		{"Small coefficients", polynomial.Polynomial{-8e-9, 0, 0, 1e-9}, []complex128{2, cmplx.Rect(2, 2.0943951023931957), cmplx.Rect(2, -2.0943951023931957)}},
+		{"Constant", polynomial.Polynomial{5}, nil},
+	}
+
+	for _, tc := range tests {
+		for _, method := range []polynomial.RootMethod{polynomial.Aberth, polynomial.Companion} {
+			t.Run(tc.name+"/"+method.String(), func(t *testing.T) {
+				opts := polynomial.RootOptions{Method: method, Tolerance: polynomial.DefaultRootTolerance, MaxIter: polynomial.DefaultMaxIter}
+				roots, err := polynomial.FindRootsWith(tc.p, opts)
+				if err != nil {
+					t.Fatalf("FindRootsWith(%v) error = %v", tc.p, err)
+				}
+				values := make([]complex128, len(roots))
+				for i, r := range roots {
+					values[i] = r.Value
+					if !r.Converged {
+						t.Errorf("Root %v did not converge", r.Value)
+					}
+					if r.ErrorBound > 1e-6 {
+						t.Errorf("Root %v has error bound %g", r.Value, r.ErrorBound)
+					}
+					if !matchRootsWithTolerance([]complex128{r.Value}, []complex128{nearest(r.Value, tc.wantRoots)}, r.ErrorBound+1e-15) {
+						t.Errorf("Root %v is not within %g of a root", r.Value, r.ErrorBound)
+					}
+				}
+				if !matchRootsWithTolerance(values, tc.wantRoots, 1e-6) {
+					t.Errorf("FindRootsWith(%v) = %v, want approx. %v", tc.p, values, tc.wantRoots)
+				}
+				for i := 1; i < len(values); i++ {
+					if real(values[i]) < real(values[i-1]) {
+						t.Errorf("Roots %v are not in increasing order", values)
+					}
+				}
+			})
+		}
+	}
+}
+
+// nearest returns the element of roots closest to z
+func nearest(z complex128, roots []complex128) complex128 {
+	best := roots[0]
+	for _, r := range roots[1:] {
+		if cmplx.Abs(z-r) < cmplx.Abs(z-best) {
+			best = r
+		}
+	}
+	return best
+}
+
+// TestFindRootsConvergence tests the iteration count and convergence
+// reported by the Aberth iteration
+func TestFindRootsConvergence(t *testing.T) {
+	p := polynomial.Polynomial{-6, 11, -6, 1}
+	roots, err := polynomial.FindRootsWith(p, polynomial.RootOptions{Tolerance: 1e-12, MaxIter: 1})
+	if err != nil {
+		t.Fatalf("FindRootsWith error: %v", err)
+	}
+	for _, r := range roots {
+		if r.Converged || r.Iterations != 1 {
+			t.Errorf("After 1 iteration, root %v has Converged = %v and Iterations = %d", r.Value, r.Converged, r.Iterations)
+		}
+	}
+
+	// A loose tolerance converges in fewer iterations than a tight one
+	q := polynomial.Polynomial{1, 1, 1, 1, 1, 1, 1, 1, 1}
+	loose, _ := polynomial.FindRootsWith(q, polynomial.RootOptions{Tolerance: 1e-2, MaxIter: 100})
+	tight, _ := polynomial.FindRootsWith(q, polynomial.RootOptions{Tolerance: 0, MaxIter: 100})
+	looseIter, tightIter := 0, 0
+	for i := range loose {
+		if !loose[i].Converged || !tight[i].Converged {
+			t.Fatalf("Roots did not converge: %v, %v", loose, tight)
+		}
+		looseIter += loose[i].Iterations
+		tightIter += tight[i].Iterations
+	}
+	if looseIter >= tightIter {
+		t.Errorf("Iterations with tolerance 1e-2 = %d, with 0 = %d", looseIter, tightIter)
+	}
+
+	// A triple root converges to within the rounding error of evaluating p
+	values, err := polynomial.FindRoots(polynomial.Polynomial{-1, 3, -3, 1})
+	if err != nil {
+		t.Fatalf("FindRoots of (x - 1)^3 error: %v", err)
+	}
+	if !matchRootsWithTolerance(values, []complex128{1, 1, 1}, 1e-4) {
+		t.Errorf("FindRoots of (x - 1)^3 = %v", values)
+	}
+}
+
+// TestFindRootsErrors tests invalid polynomials and options
+func TestFindRootsErrors(t *testing.T) {
+	valid := polynomial.RootOptions{Tolerance: 1e-12, MaxIter: 10}
+	tests := []struct {
+		name string
+		p    polynomial.Polynomial
+		opts polynomial.RootOptions
+	}{
+		{"Zero polynomial", polynomial.Polynomial{0, 0}, valid},
+		{"Negative tolerance", polynomial.Polynomial{1, 1}, polynomial.RootOptions{Tolerance: -1, MaxIter: 10}},
+		{"No iterations", polynomial.Polynomial{1, 1}, polynomial.RootOptions{Tolerance: 1e-12}},
+	}
+
+	for _, tc := range tests {
+		if _, err := polynomial.FindRootsWith(tc.p, tc.opts); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+
+	if m, err := polynomial.ParseRootMethod("Companion"); err != nil || m != polynomial.Companion {
+		t.Errorf("ParseRootMethod(Companion) = %v, %v", m, err)
+	}
+	if _, err := polynomial.ParseRootMethod("newton"); err == nil {
+		t.Errorf("Expected an error for an unknown method")
+	}
+}
+
+// TestFindRootsConjugates tests that the roots of multiple roots, which
+// scatter around them, are real or come in exact conjugate pairs
+func TestFindRootsConjugates(t *testing.T) {
+	x1 := polynomial.Polynomial{-1, 1}
+	for _, p := range []polynomial.Polynomial{x1.Pow(4), x1.Pow(5), x1.Pow(3).Mul(polynomial.Polynomial{1, 0, 1}.Pow(2))} {
+		for _, method := range []polynomial.RootMethod{polynomial.Aberth, polynomial.Companion} {
+			roots, err := polynomial.FindRootsWith(p, polynomial.RootOptions{Method: method, Tolerance: polynomial.DefaultRootTolerance, MaxIter: polynomial.DefaultMaxIter})
+			if err != nil {
+				t.Fatalf("FindRootsWith(%v) error = %v", p, err)
+			}
+			for _, r := range roots {
+				if imag(r.Value) == 0 {
+					continue
+				}
+				found := false
+				for _, s := range roots {
+					found = found || s.Value == cmplx.Conj(r.Value)
+				}
+				if !found {
+					t.Errorf("%s roots of %v: %v has no conjugate", method, p, r.Value)
+				}
+			}
+		}
+	}
+}
+
+// TestMethodFor tests which method finds the roots of a polynomial
+func TestMethodFor(t *testing.T) {
+	opts := polynomial.RootOptions{Method: polynomial.Companion}
+	tests := []struct {
+		p    polynomial.Polynomial
+		want polynomial.RootMethod
+	}{
+		{polynomial.Polynomial{-2, 0, 1}, polynomial.ClosedForm},
+		{polynomial.Polynomial{0, 0, -1, 1, 0}, polynomial.ClosedForm},
+		{polynomial.Polynomial{-2, 0, 0, 1}, polynomial.Companion},
+	}
+	for _, tc := range tests {
+		if got := opts.MethodFor(tc.p); got != tc.want {
+			t.Errorf("MethodFor(%v) = %v, want %v", tc.p, got, tc.want)
+		}
+	}
+	if _, err := polynomial.ParseRootMethod("closed-form"); err == nil {
+		t.Errorf("Expected an error selecting the closed-form method")
+	}
+}
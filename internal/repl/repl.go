@@ -0,0 +1,335 @@
+// Package repl implements the interactive read-eval-print loop behind the
+// repl command. It handles one line of input at a time, so the terminal
+// handling stays in the command.
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+	"github.com/trenchesdeveloper/gomathpro/internal/script"
+	"github.com/trenchesdeveloper/gomathpro/internal/units"
+)
+
+// ErrQuit is returned by Line when the user asks to leave the REPL.
+var ErrQuit = errors.New("quit")
+
+// Prompts shown before a new statement and before a continuation line.
+const (
+	Prompt             = "> "
+	ContinuationPrompt = "... "
+)
+
+// commands lists the meta-commands with their descriptions for :help.
+var commands = []struct {
+	name, args, doc string
+}{
+	{":vars", "", "list the session's variables"},
+	{":funcs", "", "list user-defined and built-in functions"},
+	{":clear", "", "remove all variables and user-defined functions"},
+	{":load", "file", "evaluate the statements in a file"},
+	{":save", "file", "write the session's variables and functions to a file"},
+	{":help", "", "show this help"},
+	{":quit", "", "leave the REPL (also :exit or Ctrl-D)"},
+}
+
+// REPL evaluates lines of input in a persistent evaluator session. After
+// each expression its value is stored in the variables ans and _.
+type REPL struct {
+	// Format is how values are displayed. Saved sessions always hold
+	// values in their exact form.
+	Format format.Options
+
+	session *evaluator.Evaluator
+	out     io.Writer
+	// pending holds the lines of a statement that continues on the next
+	// line.
+	pending []string
+}
+
+// New returns a REPL evaluating in session and writing its output to out.
+func New(session *evaluator.Evaluator, out io.Writer) *REPL {
+	return &REPL{session: session, out: out}
+}
+
+// Prompt returns the prompt for the next line of input.
+func (r *REPL) Prompt() string {
+	if len(r.pending) > 0 {
+		return ContinuationPrompt
+	}
+	return Prompt
+}
+
+// Cancel discards a partly entered statement.
+func (r *REPL) Cancel() {
+	r.pending = nil
+}
+
+// Line handles one line of input: a meta-command starting with ":", or a
+// line of statements. A line ending inside a statement, such as "(1 +", is
+// held until the statement is completed by later lines; an empty line
+// evaluates what has been entered so far. Line returns ErrQuit when the
+// user asks to leave, and reports all other errors to the output.
+func (r *REPL) Line(line string) error {
+	trimmed := strings.TrimSpace(line)
+	if len(r.pending) == 0 {
+		if trimmed == "" {
+			return nil
+		}
+		if strings.HasPrefix(trimmed, ":") {
+			return r.command(trimmed)
+		}
+	}
+
+	if trimmed != "" {
+		r.pending = append(r.pending, line)
+	}
+	input := strings.Join(r.pending, "\n")
+	if trimmed != "" && evaluator.Incomplete(input) {
+		return nil
+	}
+	r.pending = nil
+	r.eval(input)
+	return nil
+}
+
+// eval evaluates complete input and prints its values or error.
+func (r *REPL) eval(input string) {
+	results, err := r.session.Exec(input)
+	for _, result := range results {
+		if result.Value != nil {
+			fmt.Fprintln(r.out, format.Value(result.Value, r.Format))
+			r.session.SetVariable("ans", result.Value)
+			r.session.SetVariable("_", result.Value)
+		}
+		for _, w := range result.Warnings {
+			fmt.Fprintf(r.out, "Warning: %s\n", evaluator.RenderError(input, w))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(r.out, "Error: %s\n", evaluator.RenderError(input, err))
+	}
+}
+
+// command runs a meta-command.
+func (r *REPL) command(line string) error {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case ":quit", ":exit", ":q":
+		return ErrQuit
+	case ":help":
+		for _, c := range commands {
+			fmt.Fprintf(r.out, "  %-12s %s\n", strings.TrimSpace(c.name+" "+c.args), c.doc)
+		}
+	case ":vars":
+		r.printVariables()
+	case ":funcs":
+		r.printFunctions()
+	case ":clear":
+		r.session.Reset()
+		fmt.Fprintln(r.out, "Session cleared")
+	case ":load", ":save":
+		if len(args) != 1 {
+			fmt.Fprintf(r.out, "Error: %s expects a file name\n", name)
+			return nil
+		}
+		var err error
+		if name == ":load" {
+			err = r.Load(args[0])
+		} else {
+			err = r.Save(args[0])
+		}
+		var failure *script.Error
+		switch {
+		case errors.As(err, &failure):
+			fmt.Fprintf(r.out, "Error: %s\n", failure.Render())
+		case err != nil:
+			fmt.Fprintf(r.out, "Error: %s\n", err)
+		}
+	default:
+		fmt.Fprintf(r.out, "Error: unknown command %s (type :help for a list)\n", name)
+	}
+	return nil
+}
+
+// printVariables lists the session's variables sorted by name.
+func (r *REPL) printVariables() {
+	vars := r.session.Snapshot()
+	if len(vars) == 0 {
+		fmt.Fprintln(r.out, "No variables defined")
+		return
+	}
+	for _, name := range sortedNames(vars) {
+		fmt.Fprintf(r.out, "%s = %s\n", name, format.Value(vars[name], r.Format))
+	}
+}
+
+// printFunctions lists the user-defined functions with their definitions,
+// followed by the names of the registered functions.
+func (r *REPL) printFunctions() {
+	for _, fn := range r.session.UserFunctions() {
+		fmt.Fprintln(r.out, fn)
+	}
+	var names []string
+	for _, fn := range r.session.Functions() {
+		names = append(names, fn.Name)
+	}
+	fmt.Fprintf(r.out, "Built-in: %s\n", strings.Join(names, ", "))
+}
+
+// Load evaluates the statements in a script file as if they had been
+// typed, without printing their values. See package script for the file
+// format. Loading stops at the first error, which names the line it
+// occurred on.
+func (r *REPL) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	count := 0
+	err = script.Run(r.session, path, f, script.Options{}, func(script.Result) {
+		count++
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "Loaded %d %s from %s\n", count, plural(count, "statement"), path)
+	return nil
+}
+
+// Save writes the session's user-defined functions and variables to a file
+// as statements that Load reads back. Variables holding NaN or an infinity,
+// which cannot be written as a number, are skipped with a warning.
+func (r *REPL) Save(path string) error {
+	var b strings.Builder
+	b.WriteString("# gomathpro session\n")
+	for _, fn := range r.session.UserFunctions() {
+		fmt.Fprintln(&b, fn)
+	}
+	vars := r.session.Snapshot()
+	// Variables named like units come last, so that the quantities saved
+	// before them still read their units as units
+	names := sortedNames(vars)
+	sort.SliceStable(names, func(i, j int) bool {
+		return !isUnit(names[i]) && isUnit(names[j])
+	})
+	var skipped []string
+	for _, name := range names {
+		if !finite(vars[name]) {
+			skipped = append(skipped, name)
+			continue
+		}
+		fmt.Fprintf(&b, "%s = %s\n", name, evaluator.FormatValue(vars[name]))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	for _, name := range skipped {
+		fmt.Fprintf(r.out, "Warning: skipped %s = %s, which cannot be loaded back\n", name, evaluator.FormatValue(vars[name]))
+	}
+	fmt.Fprintf(r.out, "Saved session to %s\n", path)
+	return nil
+}
+
+// isUnit reports whether name is the name of a unit.
+func isUnit(name string) bool {
+	_, ok := units.Lookup(name)
+	return ok
+}
+
+// finite reports whether a value holds no NaN or infinity, in any of its
+// parts or elements.
+func finite(v interface{}) bool {
+	switch x := v.(type) {
+	case float64:
+		return !math.IsNaN(x) && !math.IsInf(x, 0)
+	case *big.Float:
+		return !x.IsInf()
+	case complex128:
+		return finite(real(x)) && finite(imag(x))
+	case units.Quantity:
+		return finite(x.Value)
+	case []interface{}:
+		for _, elem := range x {
+			if !finite(elem) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Complete returns completions for the word being typed at the end of line:
+// meta-commands at the start of a line, otherwise variable, constant and
+// function names.
+func (r *REPL) Complete(line string) []string {
+	start := len(line)
+	for start > 0 && isWordByte(line[start-1]) {
+		start--
+	}
+	prefix, word := line[:start], line[start:]
+
+	var candidates []string
+	if strings.TrimSpace(prefix) == ":" && start > 0 {
+		prefix, word = prefix[:len(prefix)-1], ":"+word
+		for _, c := range commands {
+			candidates = append(candidates, c.name)
+		}
+	} else {
+		if word == "" {
+			return nil
+		}
+		candidates = sortedNames(r.session.Snapshot())
+		for _, c := range evaluator.Constants() {
+			candidates = append(candidates, c.Name)
+		}
+		for _, fn := range r.session.UserFunctions() {
+			candidates = append(candidates, fn.Name+"(")
+		}
+		for _, fn := range r.session.Functions() {
+			candidates = append(candidates, fn.Name+"(")
+		}
+	}
+
+	var completions []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			completions = append(completions, prefix+c)
+		}
+	}
+	return completions
+}
+
+// isWordByte reports whether c can be part of a name.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// sortedNames returns the names of a variable scope in order.
+func sortedNames(vars map[string]interface{}) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,214 @@
+package repl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+	"github.com/trenchesdeveloper/gomathpro/internal/format"
+)
+
+// run feeds lines to a new REPL and returns its output.
+func run(t *testing.T, r *REPL, lines ...string) string {
+	t.Helper()
+	var out strings.Builder
+	r.out = &out
+	for _, line := range lines {
+		if err := r.Line(line); err != nil {
+			t.Fatalf("Line(%q) returned %v", line, err)
+		}
+	}
+	return out.String()
+}
+
+// TestLine tests evaluation, ans and _, and multi-line input
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected string
+	}{
+		{"Expression", []string{"1 + 2"}, "3\n"},
+		{"Variables persist", []string{"A = 5", "A * 2"}, "10\n"},
+		{"Previous result", []string{"6 * 7", "ans + 1", "_ * 2"}, "42\n43\n86\n"},
+		{"Several statements", []string{"1; 2"}, "1\n2\n"},
+		{"Continued parenthesis", []string{"(1 +", "2) * 3"}, "9\n"},
+		{"Trailing operator", []string{"f(x) =", "x^2", "f(4)"}, "16\n"},
+		{"Empty line ends input", []string{"(1 +", ""}, "Error: unexpected end of input\n  (1 +\n      ^\n"},
+		{"Blank lines are ignored", []string{"", "   ", "1"}, "1\n"},
+		{"Errors keep the session", []string{"B = 2", "B / 0", "B"}, "Error: division by zero\n  B / 0\n      ^\n2\n"},
+		{"Warnings follow the value", []string{"exp(1000)"}, "+Inf\nWarning: exp: result is infinite (+Inf)\n  exp(1000)\n  ^~~~~~~~~\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(evaluator.New(evaluator.Options{}), nil)
+			if got := run(t, r, tt.lines...); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestPrompt tests the continuation prompt and cancelling a statement
+func TestPrompt(t *testing.T) {
+	r := New(evaluator.New(evaluator.Options{}), nil)
+	if r.Prompt() != Prompt {
+		t.Errorf("Expected %q, got %q", Prompt, r.Prompt())
+	}
+	run(t, r, "max(1,")
+	if r.Prompt() != ContinuationPrompt {
+		t.Errorf("Expected %q, got %q", ContinuationPrompt, r.Prompt())
+	}
+	r.Cancel()
+	if got := run(t, r, "2"); got != "2\n" || r.Prompt() != Prompt {
+		t.Errorf("Expected the cancelled statement to be dropped, got %q", got)
+	}
+}
+
+// TestFormat tests that values are displayed with the REPL's format
+func TestFormat(t *testing.T) {
+	r := New(evaluator.New(evaluator.Options{}), nil)
+	r.Format = format.Options{Digits: 4}
+	if got := run(t, r, "B = 2/3", "B * 1000", ":vars"); got != "666.7\nB = 0.6667\n_ = 666.7\nans = 666.7\n" {
+		t.Errorf("Unexpected output %q", got)
+	}
+}
+
+// TestCommands tests the meta-commands
+func TestCommands(t *testing.T) {
+	r := New(evaluator.New(evaluator.Options{}), nil)
+	run(t, r, "B = 2", "A = 1/2", "sq(x) = x * x")
+
+	if got := run(t, r, ":vars"); got != "A = 0.5\nB = 2\n" {
+		t.Errorf("Expected variables, got %q", got)
+	}
+	if got := run(t, r, ":funcs"); !strings.HasPrefix(got, "sq(x) = x * x\nBuilt-in: abs, ") {
+		t.Errorf("Expected functions, got %q", got)
+	}
+	if got := run(t, r, ":clear", ":vars"); got != "Session cleared\nNo variables defined\n" {
+		t.Errorf("Expected an empty session, got %q", got)
+	}
+	if got := run(t, r, ":nope"); !strings.Contains(got, "unknown command :nope") {
+		t.Errorf("Expected an unknown command error, got %q", got)
+	}
+	if got := run(t, r, ":load"); got != "Error: :load expects a file name\n" {
+		t.Errorf("Expected a usage error, got %q", got)
+	}
+	for _, quit := range []string{":quit", ":exit", " :q "} {
+		if err := r.Line(quit); !errors.Is(err, ErrQuit) {
+			t.Errorf("%s: expected ErrQuit, got %v", quit, err)
+		}
+	}
+}
+
+// TestSaveLoad tests that a saved session loads back into a new one
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.gm")
+
+	r := New(evaluator.New(evaluator.Options{Exact: true}), nil)
+	r.Format = format.Options{Notation: format.Fixed, Digits: 2}
+	run(t, r, "A = 1/3", "f(x, y) = x * y + A", "f(2, 3)")
+	if got := run(t, r, ":save "+path); got != "Saved session to "+path+"\n" {
+		t.Fatalf("Unexpected output %q", got)
+	}
+
+	loaded := New(evaluator.New(evaluator.Options{Exact: true}), nil)
+	if got := run(t, loaded, ":load "+path); got != "Loaded 4 statements from "+path+"\n" {
+		t.Fatalf("Unexpected output %q", got)
+	}
+	if got := run(t, loaded, "f(2, 3) == ans"); got != "true\n" {
+		t.Errorf("Expected the loaded session to match, got %q", got)
+	}
+}
+
+// TestSaveNonFinite tests that variables holding NaN or an infinity are
+// skipped, so that the rest of the session loads back
+func TestSaveNonFinite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.gm")
+
+	r := New(evaluator.New(evaluator.Options{}), nil)
+	run(t, r, "n = log(0) - log(0)", "x = -log(0)", "l = [1, log(0)]", "z = 2", "y = z + 1")
+	expected := "Warning: skipped l = [1, -Inf], which cannot be loaded back\n" +
+		"Warning: skipped n = NaN, which cannot be loaded back\n" +
+		"Warning: skipped x = +Inf, which cannot be loaded back\n" +
+		"Saved session to " + path + "\n"
+	if got := run(t, r, ":save "+path); got != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, got)
+	}
+
+	loaded := New(evaluator.New(evaluator.Options{}), nil)
+	if got := run(t, loaded, ":load "+path); got != "Loaded 2 statements from "+path+"\n" {
+		t.Fatalf("Unexpected output %q", got)
+	}
+	if got := run(t, loaded, "y * z"); got != "6\n" {
+		t.Errorf("Expected the loaded session to hold y and z, got %q", got)
+	}
+}
+
+// TestSaveUnitNames tests that variables named like units are loaded after
+// the quantities that use those units
+func TestSaveUnitNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.gm")
+
+	r := New(evaluator.New(evaluator.Options{}), nil)
+	run(t, r, "v = 5 m/s", "a = 2 s", "s = 3")
+	run(t, r, ":save "+path)
+
+	loaded := New(evaluator.New(evaluator.Options{}), nil)
+	if got := run(t, loaded, ":load "+path); got != "Loaded 3 statements from "+path+"\n" {
+		t.Fatalf("Unexpected output %q", got)
+	}
+	if got := run(t, loaded, "v * a * s"); got != "30 m\n" {
+		t.Errorf("Expected the loaded session to hold s, v and a, got %q", got)
+	}
+}
+
+// TestLoadErrors tests that load errors name the file and line
+func TestLoadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.gm")
+	content := "# setup\nA = (1 +\n  2)\n\nA / Missing\nB = 1\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(evaluator.New(evaluator.Options{}), nil)
+	err := r.Load(path)
+	var undefined *evaluator.UndefinedVariableError
+	if !errors.As(err, &undefined) || !strings.HasPrefix(err.Error(), path+":5:5: ") {
+		t.Errorf("Expected an undefined variable error on line 5, got %v", err)
+	}
+	if got := run(t, r, "A"); got != "3\n" {
+		t.Errorf("Expected the statements before the error to run, got %q", got)
+	}
+
+	if err := r.Load(filepath.Join(t.TempDir(), "missing.gm")); err == nil {
+		t.Errorf("Expected an error for a missing file, but got none")
+	}
+}
+
+// TestComplete tests completion of names and meta-commands
+func TestComplete(t *testing.T) {
+	r := New(evaluator.New(evaluator.Options{}), nil)
+	run(t, r, "total = 1", "twice(x) = 2 * x")
+
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"1 + t", []string{"1 + total", "1 + tau", "1 + twice(", "1 + tan(", "1 + tanh("}},
+		{"sq", []string{"sqrt("}},
+		{":l", []string{":load"}},
+		{"1 + ", nil},
+	}
+	for _, tt := range tests {
+		got := r.Complete(tt.line)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Complete(%q): expected %q, got %q", tt.line, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package script runs calculation sheets: files of statements for the
+// evaluator, one per line or several separated by ";", with comments from
+// "#" to the end of the line. A statement may continue over several lines
+// while it is incomplete, as in an unclosed parenthesis.
+package script
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+)
+
+// Options configures Run.
+type Options struct {
+	// KeepGoing continues with the next statement after a failure instead of
+	// stopping, so that every failure in the script is reported.
+	KeepGoing bool
+}
+
+// Result is the outcome of one statement of a script.
+type Result struct {
+	// Line is the line of the script the statement starts on, starting at
+	// 1. The embedded Result's Line is relative to the statement's group of
+	// continued lines.
+	Line int
+	evaluator.Result
+}
+
+// Error is a failure located in a script.
+type Error struct {
+	// File is the name of the script.
+	File string
+	// Line and Column locate the failure, starting at 1. Column is 0 when
+	// the failure has no position within the line.
+	Line, Column int
+	// Source is the text of the lines that failed.
+	Source string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, evaluator.Message(e.Err))
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, evaluator.Message(e.Err))
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Render formats the error for display, underlining the offending part of
+// the line it occurred on.
+func (e *Error) Render() string {
+	if underline := evaluator.Underline(e.Source, e.Err); underline != "" {
+		return e.Error() + "\n" + underline
+	}
+	return e.Error()
+}
+
+// Errors is the list of failures of a script run with Options.KeepGoing.
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d %s:\n%s", len(errs), plural(len(errs), "error"), strings.Join(msgs, "\n"))
+}
+
+// Run evaluates the script read from r in session, calling emit with the
+// result of each statement, assignments included, as it runs. name
+// identifies the script in errors.
+//
+// Run returns an *Error for the first failure, or with Options.KeepGoing
+// the Errors for all of them once the whole script has run, including the
+// statements after a failing one on the same line.
+func Run(session *evaluator.Evaluator, name string, r io.Reader, opts Options, emit func(Result)) error {
+	var (
+		failures Errors
+		pending  []string
+		start    int
+	)
+
+	// exec runs the pending statement lines, and reports whether to go on
+	exec := func() bool {
+		source := strings.Join(pending, "\n")
+		pending = nil
+		var (
+			results []evaluator.Result
+			errs    []error
+		)
+		if opts.KeepGoing {
+			results, errs = session.ExecAll(source)
+		} else {
+			var err error
+			if results, err = session.Exec(source); err != nil {
+				errs = []error{err}
+			}
+		}
+		for _, result := range results {
+			emit(Result{Line: start + result.Line - 1, Result: result})
+		}
+		for _, err := range errs {
+			line, column := evaluator.Position(source, err)
+			failures = append(failures, &Error{
+				File:   name,
+				Line:   start + max(line, 1) - 1,
+				Column: column,
+				Source: source,
+				Err:    err,
+			})
+		}
+		return len(errs) == 0 || opts.KeepGoing
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(pending) == 0 {
+			start = lineNo
+		}
+		pending = append(pending, line)
+		if evaluator.Incomplete(strings.Join(pending, "\n")) {
+			continue
+		}
+		if !exec() {
+			return failures[0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// A statement still incomplete at the end of the script fails here
+	if len(pending) > 0 && !exec() {
+		return failures[0]
+	}
+
+	if len(failures) > 0 {
+		return failures
+	}
+	return nil
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,121 @@
+package script
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/trenchesdeveloper/gomathpro/internal/evaluator"
+)
+
+// sheet is a script with comments, continued lines and two failures
+const sheet = `# Loan sheet
+rate = 0.05   # yearly
+years = 10
+
+growth = (1 + rate) ^
+    years
+round(growth * 1000); Missing + 1; 3
+sqrt(-1)
+2 * years
+`
+
+// run runs a script in a new session and returns the values by line.
+func run(t *testing.T, src string, opts Options) ([]string, error) {
+	t.Helper()
+	var values []string
+	err := Run(evaluator.New(evaluator.Options{}), "sheet.gmp", strings.NewReader(src), opts, func(result Result) {
+		if result.Value != nil {
+			values = append(values, evaluator.FormatValue(result.Value)+"@"+strconv.Itoa(result.Line))
+		}
+	})
+	return values, err
+}
+
+// TestRun tests evaluating a script that stops at its first error
+func TestRun(t *testing.T) {
+	values, err := run(t, sheet, Options{})
+	if got := strings.Join(values, " "); got != "1629@7" {
+		t.Errorf("Expected values %q, got %q", "1629@7", got)
+	}
+
+	var failure *Error
+	if !errors.As(err, &failure) {
+		t.Fatalf("Expected an *Error, got %v", err)
+	}
+	if failure.File != "sheet.gmp" || failure.Line != 7 || failure.Column != 23 {
+		t.Errorf("Expected sheet.gmp:7:23, got %s:%d:%d", failure.File, failure.Line, failure.Column)
+	}
+	if got := failure.Error(); got != "sheet.gmp:7:23: undefined variable: Missing" {
+		t.Errorf("Unexpected message %q", got)
+	}
+	expected := "sheet.gmp:7:23: undefined variable: Missing\n" +
+		"  round(growth * 1000); Missing + 1; 3\n" +
+		"                        ^~~~~~~"
+	if got := failure.Render(); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+	var undefined *evaluator.UndefinedVariableError
+	if !errors.As(err, &undefined) {
+		t.Errorf("Expected the evaluator error to be wrapped, got %T", failure.Err)
+	}
+}
+
+// TestRunKeepGoing tests that all failures are collected with KeepGoing
+func TestRunKeepGoing(t *testing.T) {
+	values, err := run(t, sheet, Options{KeepGoing: true})
+	if got := strings.Join(values, " "); got != "1629@7 3@7 20@9" {
+		t.Errorf("Expected values %q, got %q", "1629@7 3@7 20@9", got)
+	}
+
+	var failures Errors
+	if !errors.As(err, &failures) || len(failures) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", err)
+	}
+	if failures[1].Line != 8 || failures[1].Column != 1 {
+		t.Errorf("Expected the second error at 8:1, got %d:%d", failures[1].Line, failures[1].Column)
+	}
+	expected := "2 errors:\n" +
+		"sheet.gmp:7:23: undefined variable: Missing\n" +
+		"sheet.gmp:8:1: sqrt: square root of negative number"
+	if got := err.Error(); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+// TestRunKeepGoingWithinLine tests that with KeepGoing the statements
+// after a failing one on the same line still run
+func TestRunKeepGoingWithinLine(t *testing.T) {
+	values, err := run(t, "x = 1; y = Missing; z = 3; w = Other; x + z\nz * 2", Options{KeepGoing: true})
+	if got := strings.Join(values, " "); got != "4@1 6@2" {
+		t.Errorf("Expected values %q, got %q", "4@1 6@2", got)
+	}
+	var failures Errors
+	if !errors.As(err, &failures) || len(failures) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", err)
+	}
+	if failures[0].Column != 12 || failures[1].Column != 32 {
+		t.Errorf("Expected errors at columns 12 and 32, got %d and %d", failures[0].Column, failures[1].Column)
+	}
+
+	// Without KeepGoing the rest of the line does not run
+	values, err = run(t, "x = 1; y = Missing; z = 3; x", Options{})
+	if err == nil || len(values) != 0 {
+		t.Errorf("Expected only an error, got %v, %v", values, err)
+	}
+}
+
+// TestRunIncompleteAtEnd tests a statement left open at the end of a script
+func TestRunIncompleteAtEnd(t *testing.T) {
+	_, err := run(t, "1 + 2\n(3 *\n  4", Options{})
+	var failure *Error
+	if !errors.As(err, &failure) || failure.Line != 3 || failure.Column != 4 {
+		t.Errorf("Expected an error at line 3, column 4, got %v", err)
+	}
+
+	values, err := run(t, "# only comments\n\n", Options{})
+	if err != nil || len(values) != 0 {
+		t.Errorf("Expected an empty script to succeed, got %v, %v", values, err)
+	}
+}
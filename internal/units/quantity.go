@@ -0,0 +1,182 @@
+package units
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Quantity is a number of units, such as 5.3 km.
+type Quantity struct {
+	Value float64
+	Unit  Unit
+}
+
+// New returns the quantity of value units.
+func New(value float64, unit Unit) Quantity {
+	return Quantity{Value: value, Unit: unit}
+}
+
+// Base returns the value of q in SI base units.
+func (q Quantity) Base() float64 {
+	return q.Value*q.Unit.Factor + q.Unit.Offset
+}
+
+// String writes q as its value followed by its unit.
+func (q Quantity) String() string {
+	if q.Unit.Dimensionless() {
+		return strconv.FormatFloat(q.Value, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(q.Value, 'g', -1, 64) + " " + q.Unit.String()
+}
+
+// Convert expresses q in another unit of the same dimension.
+func Convert(q Quantity, to Unit) (Quantity, error) {
+	if q.Unit.Dim != to.Dim {
+		return Quantity{}, &IncompatibleError{From: q.Unit, To: to}
+	}
+	if math.IsInf(q.Value, 0) || math.IsNaN(q.Value) {
+		return Quantity{Value: q.Value * q.Unit.Factor / to.Factor, Unit: to}, nil
+	}
+	// Convert exactly and round once, so that -40 degC is exactly -40 degF
+	v := new(big.Rat).SetFloat64(q.Value)
+	v.Mul(v, q.Unit.factor())
+	v.Add(v, q.Unit.offset())
+	v.Sub(v, to.offset())
+	v.Quo(v, to.factor())
+	value, _ := v.Float64()
+	return Quantity{Value: value, Unit: to}, nil
+}
+
+// Add returns a + b in the unit of a.
+func Add(a, b Quantity) (Quantity, error) {
+	b, err := sameUnit(a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: a.Value + b.Value, Unit: a.Unit}, nil
+}
+
+// Sub returns a - b in the unit of a.
+func Sub(a, b Quantity) (Quantity, error) {
+	b, err := sameUnit(a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: a.Value - b.Value, Unit: a.Unit}, nil
+}
+
+// sameUnit converts b to the unit of a for addition or subtraction, which
+// are not defined for temperatures on scales with an offset zero.
+func sameUnit(a, b Quantity) (Quantity, error) {
+	if a.Unit.Dim != b.Unit.Dim {
+		return Quantity{}, &IncompatibleError{From: a.Unit, To: b.Unit}
+	}
+	for _, u := range []Unit{a.Unit, b.Unit} {
+		if u.Offset != 0 {
+			return Quantity{}, &OffsetError{Unit: u.String()}
+		}
+	}
+	return Convert(b, a.Unit)
+}
+
+// Compare compares two quantities of the same dimension, returning -1, 0
+// or +1 as a is less than, equal to or greater than b. Quantities within a
+// relative tolerance of the rounding error of a unit conversion compare
+// equal, so that 3 ft == 1 yd.
+func Compare(a, b Quantity) (int, error) {
+	if a.Unit.Dim != b.Unit.Dim {
+		return 0, &IncompatibleError{From: a.Unit, To: b.Unit}
+	}
+	x, y := a.Base(), b.Base()
+	switch {
+	case math.Abs(x-y) <= compareTolerance*math.Max(math.Abs(x), math.Abs(y)):
+		return 0, nil
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// compareTolerance is the relative difference below which Compare treats
+// two quantities as equal.
+const compareTolerance = 1e-12
+
+// Mul returns a * b with its unit simplified: a unit of b with the same
+// dimension as a unit of a is converted to it, so km * m is km^2; units
+// that cancel are dropped, so a dimensionless product has the unit One;
+// and a product of SI units equal to a derived one, such as kg m/s^2, is
+// written as that unit, N.
+func Mul(a, b Quantity) (Quantity, error) {
+	for _, u := range []Unit{a.Unit, b.Unit} {
+		if u.Offset != 0 {
+			return Quantity{}, &OffsetError{Unit: u.String()}
+		}
+	}
+
+	value := a.Value * b.Value
+	terms := append([]Term(nil), a.Unit.Terms...)
+	for _, t := range b.Unit.Terms {
+		i := findTerm(terms, t.Name)
+		if i < 0 {
+			i = findDimension(terms, t.dim)
+		}
+		if i < 0 {
+			terms = append(terms, t)
+			continue
+		}
+		if terms[i].Name != t.Name {
+			ratio, _ := ratPow(new(big.Rat).Quo(t.factor, terms[i].factor), t.Power).Float64()
+			value *= ratio
+		}
+		terms[i].Power += t.Power
+	}
+
+	unit, err := newUnit(terms)
+	if err != nil {
+		return Quantity{}, err
+	}
+	if unit.Dim.IsZero() {
+		return Quantity{Value: value * unit.Factor, Unit: One}, nil
+	}
+	if len(unit.Terms) > 1 && unit.Factor == 1 {
+		for _, name := range derived {
+			if def := byName[name]; def.Dim == unit.Dim {
+				unit, _ = Lookup(name)
+				break
+			}
+		}
+	}
+	return Quantity{Value: value, Unit: unit}, nil
+}
+
+// findDimension returns the index of the term whose named unit has
+// dimension dim, or -1.
+func findDimension(terms []Term, dim Dimension) int {
+	for i, t := range terms {
+		if t.dim == dim {
+			return i
+		}
+	}
+	return -1
+}
+
+// Div returns a / b with its unit simplified as by Mul.
+func Div(a, b Quantity) (Quantity, error) {
+	inv, err := Pow(b, -1)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Mul(a, inv)
+}
+
+// Pow raises q to an integer power.
+func Pow(q Quantity, n int) (Quantity, error) {
+	unit, err := q.Unit.Pow(n)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: math.Pow(q.Value, float64(n)), Unit: unit}, nil
+}
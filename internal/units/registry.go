@@ -0,0 +1,159 @@
+package units
+
+import "math/big"
+
+// Definition is a unit in the registry.
+type Definition struct {
+	Name string
+	// Factor is the size of the unit in SI base units, and Offset the value
+	// in SI base units of its zero.
+	Factor, Offset float64
+	Dim            Dimension
+	// Prefixes is set for units that accept SI prefixes, as in km.
+	Prefixes bool
+	Doc      string
+
+	// factor and offset are Factor and Offset exactly.
+	factor, offset *big.Rat
+}
+
+// Dimensions of the registered units.
+var (
+	length      = Dimension{1, 0, 0, 0, 0, 0, 0}
+	mass        = Dimension{0, 1, 0, 0, 0, 0, 0}
+	duration    = Dimension{0, 0, 1, 0, 0, 0, 0}
+	current     = Dimension{0, 0, 0, 1, 0, 0, 0}
+	temperature = Dimension{0, 0, 0, 0, 1, 0, 0}
+	amount      = Dimension{0, 0, 0, 0, 0, 1, 0}
+	luminosity  = Dimension{0, 0, 0, 0, 0, 0, 1}
+
+	frequency  = Dimension{0, 0, -1, 0, 0, 0, 0}
+	area       = Dimension{2, 0, 0, 0, 0, 0, 0}
+	volume     = Dimension{3, 0, 0, 0, 0, 0, 0}
+	speed      = Dimension{1, 0, -1, 0, 0, 0, 0}
+	force      = Dimension{1, 1, -2, 0, 0, 0, 0}
+	pressure   = Dimension{-1, 1, -2, 0, 0, 0, 0}
+	energy     = Dimension{2, 1, -2, 0, 0, 0, 0}
+	power      = Dimension{2, 1, -3, 0, 0, 0, 0}
+	charge     = Dimension{0, 0, 1, 1, 0, 0, 0}
+	voltage    = Dimension{2, 1, -3, -1, 0, 0, 0}
+	resistance = Dimension{2, 1, -3, -2, 0, 0, 0}
+)
+
+// table lists the registered units, with their sizes and zeros in SI base
+// units written exactly. The SI base units come first, in the order of the
+// dimensions.
+var table = []struct {
+	name           string
+	factor, offset string
+	dim            Dimension
+	prefixes       bool
+	doc            string
+}{
+	{"m", "1", "0", length, true, "metre"},
+	{"kg", "1", "0", mass, false, "kilogram"},
+	{"s", "1", "0", duration, true, "second"},
+	{"A", "1", "0", current, true, "ampere"},
+	{"K", "1", "0", temperature, true, "kelvin"},
+	{"mol", "1", "0", amount, true, "mole"},
+	{"cd", "1", "0", luminosity, true, "candela"},
+
+	{"g", "1e-3", "0", mass, true, "gram"},
+	{"Hz", "1", "0", frequency, true, "hertz"},
+	{"N", "1", "0", force, true, "newton"},
+	{"Pa", "1", "0", pressure, true, "pascal"},
+	{"J", "1", "0", energy, true, "joule"},
+	{"W", "1", "0", power, true, "watt"},
+	{"C", "1", "0", charge, true, "coulomb"},
+	{"V", "1", "0", voltage, true, "volt"},
+	{"ohm", "1", "0", resistance, true, "ohm"},
+	{"L", "1e-3", "0", volume, true, "litre"},
+
+	{"min", "60", "0", duration, false, "minute"},
+	{"hr", "3600", "0", duration, false, "hour"},
+	{"day", "86400", "0", duration, false, "day"},
+	{"week", "604800", "0", duration, false, "week"},
+	{"yr", "31557600", "0", duration, false, "Julian year of 365.25 days"},
+
+	{"degC", "1", "273.15", temperature, false, "degree Celsius"},
+	{"degF", "5/9", "45967/180", temperature, false, "degree Fahrenheit"},
+
+	{"in", "0.0254", "0", length, false, "inch"},
+	{"ft", "0.3048", "0", length, false, "foot"},
+	{"yd", "0.9144", "0", length, false, "yard"},
+	{"mi", "1609.344", "0", length, false, "mile"},
+	{"nmi", "1852", "0", length, false, "nautical mile"},
+	{"ha", "1e4", "0", area, false, "hectare"},
+	{"acre", "4046.8564224", "0", area, false, "acre"},
+	{"gal", "3.785411784e-3", "0", volume, false, "US gallon"},
+	{"qt", "0.946352946e-3", "0", volume, false, "US quart"},
+	{"pt", "0.473176473e-3", "0", volume, false, "US pint"},
+	{"t", "1000", "0", mass, false, "tonne"},
+	{"lb", "0.45359237", "0", mass, false, "pound"},
+	{"oz", "0.028349523125", "0", mass, false, "ounce"},
+	{"mph", "0.44704", "0", speed, false, "mile per hour"},
+	{"knot", "463/900", "0", speed, false, "knot"},
+	{"lbf", "4.4482216152605", "0", force, false, "pound-force"},
+	{"bar", "1e5", "0", pressure, true, "bar"},
+	{"atm", "101325", "0", pressure, false, "standard atmosphere"},
+	{"psi", "44482216152605/6451600000", "0", pressure, false, "pound-force per square inch"},
+	{"mmHg", "133.322387415", "0", pressure, false, "millimetre of mercury"},
+	{"cal", "4.184", "0", energy, true, "thermochemical calorie"},
+	{"Wh", "3600", "0", energy, true, "watt-hour"},
+	{"eV", "1.602176634e-19", "0", energy, true, "electronvolt"},
+	{"BTU", "1055.05585262", "0", energy, false, "British thermal unit"},
+	{"hp", "745.69987158227022", "0", power, false, "mechanical horsepower"},
+}
+
+// derived lists the named units that products and quotients of coherent
+// SI units are simplified to, such as N for kg m/s^2.
+var derived = []string{"N", "J", "W", "Pa", "C", "V", "ohm"}
+
+// prefixes lists the SI prefixes, with "da" before "d" so that the longer
+// one matches first.
+var prefixes = []struct {
+	symbol, factor string
+}{
+	{"Y", "1e24"}, {"Z", "1e21"}, {"E", "1e18"}, {"P", "1e15"}, {"T", "1e12"},
+	{"G", "1e9"}, {"M", "1e6"}, {"k", "1e3"}, {"h", "1e2"}, {"da", "1e1"},
+	{"d", "1e-1"}, {"c", "1e-2"}, {"m", "1e-3"}, {"u", "1e-6"}, {"µ", "1e-6"},
+	{"n", "1e-9"}, {"p", "1e-12"}, {"f", "1e-15"}, {"a", "1e-18"},
+}
+
+var (
+	// definitions holds the units of table, and byName indexes them.
+	definitions []Definition
+	byName      = make(map[string]*Definition, len(table))
+	// prefixFactors holds the exact factors of prefixes.
+	prefixFactors = make(map[string]*big.Rat, len(prefixes))
+)
+
+func init() {
+	definitions = make([]Definition, len(table))
+	for i, u := range table {
+		def := Definition{Name: u.name, Dim: u.dim, Prefixes: u.prefixes, Doc: u.doc}
+		def.factor = mustRat(u.factor)
+		def.offset = mustRat(u.offset)
+		def.Factor, _ = def.factor.Float64()
+		def.Offset, _ = def.offset.Float64()
+		definitions[i] = def
+		byName[u.name] = &definitions[i]
+	}
+	for _, p := range prefixes {
+		prefixFactors[p.symbol] = mustRat(p.factor)
+	}
+}
+
+// mustRat parses an exact number from the unit tables.
+func mustRat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("units: invalid number " + s)
+	}
+	return r
+}
+
+// Definitions returns the registered units in a fixed order.
+func Definitions() []Definition {
+	return append([]Definition(nil), definitions...)
+}
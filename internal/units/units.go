@@ -0,0 +1,365 @@
+// Package units implements physical quantities: numbers with units such as
+// km, m/s^2 or degF. It holds a registry of SI units with their prefixes
+// and common imperial units, checks that quantities combined by addition
+// or comparison have the same dimension, and simplifies the units of
+// products and quotients.
+package units
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Dimension holds the exponents of the SI base dimensions of a unit:
+// length, mass, time, current, temperature, amount of substance and
+// luminous intensity.
+type Dimension [7]int
+
+var dimensionNames = [7]string{"length", "mass", "time", "current", "temperature", "amount", "luminosity"}
+
+// IsZero reports whether d is dimensionless.
+func (d Dimension) IsZero() bool {
+	return d == Dimension{}
+}
+
+func (d Dimension) mul(e Dimension, n int) Dimension {
+	for i := range d {
+		d[i] += e[i] * n
+	}
+	return d
+}
+
+// String writes d in terms of the base dimensions, such as length/time^2.
+func (d Dimension) String() string {
+	var names []string
+	var powers []int
+	for i, n := range d {
+		if n != 0 {
+			names = append(names, dimensionNames[i])
+			powers = append(powers, n)
+		}
+	}
+	if len(names) == 0 {
+		return "dimensionless"
+	}
+	return fraction(names, powers)
+}
+
+// Term is a named unit raised to a power within a compound unit.
+type Term struct {
+	Name  string
+	Power int
+
+	// factor and offset convert the named unit to SI base units exactly,
+	// and dim is its dimension.
+	factor, offset *big.Rat
+	dim            Dimension
+}
+
+// Unit is a product of named units raised to powers, such as kg m/s^2.
+type Unit struct {
+	Terms []Term
+	// Factor is the size of the unit in SI base units.
+	Factor float64
+	// Offset is the value in SI base units of the unit's zero. It is
+	// non-zero only for temperature scales such as degC.
+	Offset float64
+	Dim    Dimension
+
+	// exact and zero are Factor and Offset exactly, or nil for 1 and 0.
+	exact, zero *big.Rat
+}
+
+// One is the unit of dimensionless numbers.
+var One = Unit{Factor: 1}
+
+// newUnit builds a unit from terms, dropping those with a zero power.
+func newUnit(terms []Term) (Unit, error) {
+	u := Unit{exact: big.NewRat(1, 1)}
+	for _, t := range terms {
+		if t.Power == 0 {
+			continue
+		}
+		u.Terms = append(u.Terms, t)
+		u.exact.Mul(u.exact, ratPow(t.factor, t.Power))
+		u.Dim = u.Dim.mul(t.dim, t.Power)
+	}
+	u.Factor, _ = u.exact.Float64()
+	for _, t := range u.Terms {
+		if t.offset.Sign() == 0 {
+			continue
+		}
+		if len(u.Terms) > 1 || t.Power != 1 {
+			return Unit{}, &OffsetError{Unit: t.Name}
+		}
+		u.zero = t.offset
+		u.Offset, _ = t.offset.Float64()
+	}
+	return u, nil
+}
+
+// factor returns u.Factor exactly.
+func (u Unit) factor() *big.Rat {
+	if u.exact == nil {
+		return big.NewRat(1, 1)
+	}
+	return u.exact
+}
+
+// offset returns u.Offset exactly.
+func (u Unit) offset() *big.Rat {
+	if u.zero == nil {
+		return new(big.Rat)
+	}
+	return u.zero
+}
+
+// ratPow raises x to an integer power, returning a new number.
+func ratPow(x *big.Rat, n int) *big.Rat {
+	base := new(big.Rat).Set(x)
+	if n < 0 {
+		base.Inv(base)
+		n = -n
+	}
+	r := big.NewRat(1, 1)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			r.Mul(r, base)
+		}
+		base.Mul(base, base)
+	}
+	return r
+}
+
+// Dimensionless reports whether u is the unit of plain numbers.
+func (u Unit) Dimensionless() bool {
+	return len(u.Terms) == 0
+}
+
+// Mul returns the product of two units. Powers of the same named unit are
+// added, so m * m is m^2 and m/s * s is m.
+func (u Unit) Mul(v Unit) (Unit, error) {
+	terms := append([]Term(nil), u.Terms...)
+	for _, t := range v.Terms {
+		if i := findTerm(terms, t.Name); i >= 0 {
+			terms[i].Power += t.Power
+			continue
+		}
+		terms = append(terms, t)
+	}
+	return newUnit(terms)
+}
+
+// Div returns the quotient of two units.
+func (u Unit) Div(v Unit) (Unit, error) {
+	inv, err := v.Pow(-1)
+	if err != nil {
+		return Unit{}, err
+	}
+	return u.Mul(inv)
+}
+
+// Pow raises a unit to an integer power.
+func (u Unit) Pow(n int) (Unit, error) {
+	terms := make([]Term, len(u.Terms))
+	for i, t := range u.Terms {
+		t.Power *= n
+		terms[i] = t
+	}
+	return newUnit(terms)
+}
+
+// String writes u as its named units with positive powers followed by
+// those with negative powers, each after a "/": kg m^2/s^2, W/m^2/K. A
+// unit with only negative powers is written with them, as in s^-1.
+func (u Unit) String() string {
+	if u.Dimensionless() {
+		return "1"
+	}
+	names := make([]string, len(u.Terms))
+	powers := make([]int, len(u.Terms))
+	for i, t := range u.Terms {
+		names[i], powers[i] = t.Name, t.Power
+	}
+	return fraction(names, powers)
+}
+
+// raise writes name raised to n, omitting a power of 1.
+func raise(name string, n int) string {
+	if n == 1 {
+		return name
+	}
+	return name + "^" + strconv.Itoa(n)
+}
+
+// fraction writes a product of names raised to non-zero powers.
+func fraction(names []string, powers []int) string {
+	var num, den []string
+	for i, name := range names {
+		if powers[i] > 0 {
+			num = append(num, raise(name, powers[i]))
+		} else {
+			den = append(den, raise(name, -powers[i]))
+		}
+	}
+	if len(num) == 0 {
+		for i, name := range names {
+			num = append(num, raise(name, powers[i]))
+		}
+		return strings.Join(num, " ")
+	}
+	s := strings.Join(num, " ")
+	for _, d := range den {
+		s += "/" + d
+	}
+	return s
+}
+
+// findTerm returns the index of the term for the named unit, or -1.
+func findTerm(terms []Term, name string) int {
+	for i, t := range terms {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lookup returns the named unit, which may be a registered unit or a
+// prefixed one such as km or mA.
+func Lookup(name string) (Unit, bool) {
+	t, ok := lookupTerm(name)
+	if !ok {
+		return Unit{}, false
+	}
+	u, err := newUnit([]Term{t})
+	return u, err == nil
+}
+
+// lookupTerm returns the term for the named unit raised to the power 1.
+func lookupTerm(name string) (Term, bool) {
+	if def, ok := byName[name]; ok {
+		return Term{Name: name, Power: 1, factor: def.factor, offset: def.offset, dim: def.Dim}, true
+	}
+	for _, p := range prefixes {
+		rest, ok := strings.CutPrefix(name, p.symbol)
+		if !ok {
+			continue
+		}
+		if def, ok := byName[rest]; ok && def.Prefixes {
+			factor := new(big.Rat).Mul(prefixFactors[p.symbol], def.factor)
+			return Term{Name: name, Power: 1, factor: factor, offset: def.offset, dim: def.Dim}, true
+		}
+	}
+	return Term{}, false
+}
+
+// Parse parses a unit written as named units separated by spaces or "*",
+// each optionally raised to an integer power with "^" and divided into
+// what precedes it with "/": km, m/s^2, kg m^2/s^2, 1/s.
+func Parse(s string) (Unit, error) {
+	u := One
+	rest := strings.TrimSpace(s)
+	if r, ok := strings.CutPrefix(rest, "1/"); ok {
+		rest = "/" + r
+	}
+	if rest == "" {
+		return Unit{}, fmt.Errorf("empty unit")
+	}
+
+	for rest != "" {
+		divide := false
+		switch rest[0] {
+		case '/':
+			divide = true
+			rest = rest[1:]
+		case '*':
+			rest = rest[1:]
+		}
+		rest = strings.TrimLeft(rest, " ")
+
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '_'
+		})
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		rest = strings.TrimLeft(rest[end:], " ")
+		term, ok := lookupTerm(name)
+		if !ok {
+			if name == "" {
+				return Unit{}, fmt.Errorf("invalid unit %q", s)
+			}
+			return Unit{}, &UnknownUnitError{Name: name}
+		}
+
+		if r, ok := strings.CutPrefix(rest, "^"); ok {
+			end := 0
+			if strings.HasPrefix(r, "-") {
+				end = 1
+			}
+			for end < len(r) && r[end] >= '0' && r[end] <= '9' {
+				end++
+			}
+			n, err := strconv.Atoi(r[:end])
+			if err != nil {
+				return Unit{}, fmt.Errorf("invalid power of %s in %q", name, s)
+			}
+			term.Power = n
+			rest = strings.TrimLeft(r[end:], " ")
+		}
+		if divide {
+			term.Power = -term.Power
+		}
+
+		v, err := newUnit([]Term{term})
+		if err == nil {
+			u, err = u.Mul(v)
+		}
+		if err != nil {
+			return Unit{}, err
+		}
+	}
+	return u, nil
+}
+
+// UnknownUnitError reports a name that is not a unit.
+type UnknownUnitError struct {
+	Name string
+}
+
+func (e *UnknownUnitError) Error() string {
+	return "unknown unit: " + e.Name
+}
+
+// OffsetError reports arithmetic on a unit with an offset zero, such as
+// degC, whose result would depend on where the zero lies.
+type OffsetError struct {
+	Unit string
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("cannot combine %s with other units; convert it to K first", e.Unit)
+}
+
+// IncompatibleError reports quantities of different dimensions combined by
+// addition, comparison or conversion.
+type IncompatibleError struct {
+	From, To Unit
+}
+
+func (e *IncompatibleError) Error() string {
+	return fmt.Sprintf("incompatible units: %s and %s", describe(e.From), describe(e.To))
+}
+
+// describe names a unit and its dimension for an error message.
+func describe(u Unit) string {
+	if u.Dimensionless() {
+		return "a number without a unit"
+	}
+	return fmt.Sprintf("%s (%s)", u, u.Dim)
+}
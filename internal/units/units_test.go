@@ -0,0 +1,179 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestLookup tests registered and prefixed unit names
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		factor float64
+		dim    Dimension
+		ok     bool
+	}{
+		{"m", 1, length, true},
+		{"km", 1000, length, true},
+		{"mg", 1e-6, mass, true},
+		{"dam", 10, length, true},
+		{"µs", 1e-6, duration, true},
+		{"kWh", 3.6e6, energy, true},
+		{"min", 60, duration, true},
+		{"mi", 1609.344, length, true},
+		{"Pa", 1, pressure, true},
+		{"kft", 0, Dimension{}, false},
+		{"furlong", 0, Dimension{}, false},
+	}
+
+	for _, tt := range tests {
+		u, ok := Lookup(tt.name)
+		if ok != tt.ok {
+			t.Errorf("Lookup(%q): expected ok=%v, got %v", tt.name, tt.ok, ok)
+			continue
+		}
+		if ok && (math.Abs(u.Factor-tt.factor) > 1e-9*tt.factor || u.Dim != tt.dim) {
+			t.Errorf("Lookup(%q): expected factor %g and %s, got %g and %s", tt.name, tt.factor, tt.dim, u.Factor, u.Dim)
+		}
+	}
+}
+
+// TestParse tests parsing compound units and writing them back
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input, expected string
+		dim             Dimension
+	}{
+		{"m/s^2", "m/s^2", Dimension{1, 0, -2, 0, 0, 0, 0}},
+		{"kg m^2/s^2", "kg m^2/s^2", energy},
+		{"kg*m / s", "kg m/s", Dimension{1, 1, -1, 0, 0, 0, 0}},
+		{"W/m^2/K", "W/m^2/K", Dimension{0, 1, -3, 0, -1, 0, 0}},
+		{"1/s", "s^-1", frequency},
+		{"s^-1", "s^-1", frequency},
+		{"m m", "m^2", area},
+		{"m/m", "1", Dimension{}},
+	}
+
+	for _, tt := range tests {
+		u, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if u.String() != tt.expected || u.Dim != tt.dim {
+			t.Errorf("Parse(%q): expected %s (%s), got %s (%s)", tt.input, tt.expected, tt.dim, u, u.Dim)
+		}
+	}
+
+	for _, input := range []string{"", "m/", "furlong", "m^x", "degC m", "degC^2"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q): expected an error", input)
+		}
+	}
+}
+
+// mustParse parses a unit known to be valid.
+func mustParse(t *testing.T, s string) Unit {
+	t.Helper()
+	u, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return u
+}
+
+// TestConvert tests conversions, including between temperature scales
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		expected float64
+	}{
+		{5, "km", "m", 5000},
+		{1, "mi", "ft", 5280},
+		{60, "mph", "km/hr", 96.56064},
+		{1, "atm", "psi", 14.695948775513449},
+		{72, "degF", "degC", 200.0 / 9},
+		{-40, "degC", "degF", -40},
+		{0, "degC", "K", 273.15},
+		{212, "degF", "K", 373.15},
+		{1, "kWh", "J", 3.6e6},
+	}
+
+	for _, tt := range tests {
+		q, err := Convert(New(tt.value, mustParse(t, tt.from)), mustParse(t, tt.to))
+		if err != nil {
+			t.Errorf("%g %s to %s: unexpected error: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(q.Value-tt.expected) > 1e-9*math.Max(1, math.Abs(tt.expected)) || q.Unit.String() != tt.to {
+			t.Errorf("%g %s to %s: expected %g, got %s", tt.value, tt.from, tt.to, tt.expected, q)
+		}
+	}
+
+	// Conversions are exact up to the final rounding
+	if q, _ := Convert(New(-40, mustParse(t, "degC")), mustParse(t, "degF")); q.Value != -40 {
+		t.Errorf("-40 degC to degF: expected exactly -40, got %v", q.Value)
+	}
+
+	var incompatible *IncompatibleError
+	if _, err := Convert(New(1, mustParse(t, "m")), mustParse(t, "s")); !errors.As(err, &incompatible) {
+		t.Errorf("Expected an incompatible units error, got %v", err)
+	}
+}
+
+// TestArithmetic tests the units of sums, products and quotients
+func TestArithmetic(t *testing.T) {
+	q := func(value float64, unit string) Quantity {
+		return New(value, mustParse(t, unit))
+	}
+	tests := []struct {
+		name     string
+		op       func(a, b Quantity) (Quantity, error)
+		a, b     Quantity
+		expected string
+	}{
+		{"Sum in the left unit", Add, q(5, "km"), q(300, "m"), "5.3 km"},
+		{"Difference", Sub, q(1, "hr"), q(30, "min"), "0.5 hr"},
+		{"Cancelling units", Mul, q(9.81, "m/s^2"), q(3, "s"), "29.43 m/s"},
+		{"Same dimension converts", Mul, q(2, "km"), q(500, "m"), "1 km^2"},
+		{"Derived unit", Mul, q(2, "kg"), q(3, "m/s^2"), "6 N"},
+		{"Derived energy", Mul, q(2, "N"), q(3, "m"), "6 J"},
+		{"Prefixed units are kept", Mul, q(1, "kW"), q(2, "hr"), "2 kW hr"},
+		{"Dimensionless quotient", Div, q(5, "km"), q(500, "m"), "10"},
+		{"Quotient", Div, q(10, "m"), q(2, "s"), "5 m/s"},
+		{"Number times quantity", Mul, q(2, "m/m"), q(3, "A"), "6 A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	var incompatible *IncompatibleError
+	if _, err := Add(q(1, "m"), q(1, "s")); !errors.As(err, &incompatible) {
+		t.Errorf("Expected an incompatible units error, got %v", err)
+	}
+	var offset *OffsetError
+	if _, err := Add(q(20, "degC"), q(5, "degC")); !errors.As(err, &offset) {
+		t.Errorf("Expected an offset unit error, got %v", err)
+	}
+	if _, err := Mul(q(2, "degF"), q(1, "s")); !errors.As(err, &offset) {
+		t.Errorf("Expected an offset unit error, got %v", err)
+	}
+
+	if c, err := Compare(q(3, "ft"), q(1, "yd")); err != nil || c != 0 {
+		t.Errorf("Expected 3 ft == 1 yd, got %d, %v", c, err)
+	}
+	if c, err := Compare(q(0, "degC"), q(31, "degF")); err != nil || c != 1 {
+		t.Errorf("Expected 0 degC > 31 degF, got %d, %v", c, err)
+	}
+}
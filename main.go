@@ -13,9 +13,9 @@ import (
 var log = logrus.New()
 
 func main() {
-	// Initialize logging
+	// Initialize logging. Logs go to stderr so stdout holds only results
 	log.SetFormatter(&logrus.JSONFormatter{})
-	log.SetOutput(os.Stdout)
+	log.SetOutput(os.Stderr)
 	log.SetLevel(logrus.InfoLevel)
 
 	// Execute the root command